@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joho/godotenv"
+	"github.com/lavish-gambhir/dashbeam/pkg/logger"
+	"github.com/lavish-gambhir/dashbeam/shared/config"
+	"github.com/lavish-gambhir/dashbeam/shared/streaming"
+)
+
+const usage = `Usage: dlq <command> [args]
+Commands:
+  list [-topic t] [-type event_type] [-error substr] [-status failed|replayed|quarantined] [-limit n]
+  show <topic> <event_id>
+  replay <topic> <event_id>...
+  purge <topic> <event_id>...`
+
+type queue interface {
+	streaming.MessageQueue
+	DeadLetters() streaming.DeadLetterQueue
+	Close() error
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		log.Fatal(usage)
+	}
+
+	// .env is optional here, unlike for the server
+	_ = godotenv.Load()
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load application configuration: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	q, err := openQueue(ctx, cfg)
+	if err != nil {
+		log.Fatalf("Failed to connect to the queue: %v", err)
+	}
+	defer q.Close()
+	dlq := q.DeadLetters()
+
+	command, args := os.Args[1], os.Args[2:]
+	switch command {
+	case "list":
+		fs := flag.NewFlagSet("list", flag.ExitOnError)
+		topic := fs.String("topic", "", "only events of this topic")
+		eventType := fs.String("type", "", "only events of this event type")
+		errContains := fs.String("error", "", "only events whose error contains this text")
+		status := fs.String("status", "", "failed, replayed or quarantined (default: failed and replayed)")
+		limit := fs.Int("limit", 100, "maximum number of events")
+		fs.Parse(args)
+
+		events, err := dlq.List(ctx, streaming.DLQFilter{
+			Topic:         *topic,
+			EventType:     streaming.EventType(*eventType),
+			ErrorContains: *errContains,
+			Status:        *status,
+			Limit:         *limit,
+		})
+		if err != nil {
+			log.Fatalf("Failed to list dead letters: %v", err)
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "TOPIC\tEVENT ID\tTYPE\tSTATUS\tREPLAYS\tFAILED AT\tERROR")
+		for _, fe := range events {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%s\t%s\n", fe.Topic, fe.Event.ID, fe.Event.Type, fe.Status, fe.RetryCount, fe.FailedAt.Format(time.RFC3339), fe.Error)
+		}
+		tw.Flush()
+
+	case "show":
+		if len(args) != 2 {
+			log.Fatal("Usage: dlq show <topic> <event_id>")
+		}
+		fe, err := dlq.Get(ctx, args[0], parseEventID(args[1]))
+		if err != nil {
+			log.Fatalf("Failed to get dead letter: %v", err)
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(fe); err != nil {
+			log.Fatalf("Failed to print dead letter: %v", err)
+		}
+
+	case "replay", "purge":
+		if len(args) < 2 {
+			log.Fatalf("Usage: dlq %s <topic> <event_id>...", command)
+		}
+		topic := args[0]
+		var failed int
+		for _, arg := range args[1:] {
+			id := parseEventID(arg)
+			if command == "replay" {
+				fe, err := dlq.Replay(ctx, topic, id)
+				if err != nil {
+					log.Printf("Failed to replay %s: %v", id, err)
+					failed++
+					continue
+				}
+				log.Printf("Replayed %s (replay %d)", id, fe.RetryCount)
+			} else {
+				if err := dlq.Purge(ctx, topic, id); err != nil {
+					log.Printf("Failed to purge %s: %v", id, err)
+					failed++
+					continue
+				}
+				log.Printf("Purged %s", id)
+			}
+		}
+		if failed > 0 {
+			os.Exit(1)
+		}
+
+	default:
+		log.Fatalf("Unknown command: %s\n%s", command, usage)
+	}
+}
+
+// openQueue connects to the queue the server is configured with. Replays go
+// through it, so they are delivered the same way as new events.
+func openQueue(ctx context.Context, cfg *config.AppConfig) (queue, error) {
+	slogger := logger.NewSlogger(string(cfg.Env))
+	switch cfg.Queue.Driver {
+	case "", "streams":
+		return streaming.NewStreamQueue(ctx, cfg, slogger)
+	case "pubsub":
+		return streaming.NewRedisQueue(ctx, cfg, slogger)
+	default:
+		return nil, fmt.Errorf("queue driver %q has no persistent dead-letter queue", cfg.Queue.Driver)
+	}
+}
+
+func parseEventID(s string) uuid.UUID {
+	id, err := uuid.Parse(s)
+	if err != nil {
+		log.Fatalf("Invalid event id %q: %v", s, err)
+	}
+	return id
+}
@@ -1,6 +1,7 @@
 package main // Note: changed from 'migrator' to 'main' for the executable
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
@@ -10,7 +11,9 @@ import (
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 	_ "github.com/golang-migrate/migrate/v4/source/github"
+	"github.com/lavish-gambhir/dashbeam/pkg/logger"
 	"github.com/lavish-gambhir/dashbeam/shared/config"
+	"github.com/lavish-gambhir/dashbeam/shared/database/clickhouse"
 )
 
 const (
@@ -22,6 +25,10 @@ func main() {
 	if err != nil {
 		log.Fatalf("Failed to load application configuration: %v", err)
 	}
+	if len(os.Args) >= 2 && os.Args[1] == "clickhouse" {
+		migrateClickHouse(cfg)
+		return
+	}
 	m, err := migrate.New(
 		_migrationsPath,
 		cfg.Database.Address(),
@@ -37,7 +44,7 @@ func main() {
 	}()
 
 	if len(os.Args) < 2 {
-		log.Fatal("Usage: migrator <command> [args]\nCommands: up, down, create")
+		log.Fatal("Usage: migrator <command> [args]\nCommands: up, down, create, clickhouse")
 	}
 
 	command := os.Args[1]
@@ -87,3 +94,19 @@ func main() {
 		log.Fatalf("Unknown command: %s", command)
 	}
 }
+
+// migrateClickHouse creates the ClickHouse tables and the metrics views,
+// backfilling the tables of new views from the events already stored.
+func migrateClickHouse(cfg *config.AppConfig) {
+	log.Println("Creating ClickHouse tables and metrics views...")
+	db, err := clickhouse.New(cfg.Analytics, logger.NewSlogger(string(cfg.Env)))
+	if err != nil {
+		log.Fatalf("Failed to connect to ClickHouse: %v", err)
+	}
+	defer db.Close()
+
+	if err := db.CreateMetricViews(context.Background()); err != nil {
+		log.Fatalf("Failed to create metrics views: %v", err)
+	}
+	log.Println("ClickHouse migrated successfully!")
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -14,16 +15,21 @@ import (
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/joho/godotenv"
+	"google.golang.org/grpc"
+
 	"github.com/lavish-gambhir/dashbeam/cmd/server/handlers"
 	"github.com/lavish-gambhir/dashbeam/pkg/logger"
+	"github.com/lavish-gambhir/dashbeam/services/admin"
 	"github.com/lavish-gambhir/dashbeam/services/analytics"
 	"github.com/lavish-gambhir/dashbeam/services/auth"
 	"github.com/lavish-gambhir/dashbeam/services/ingestion"
+	"github.com/lavish-gambhir/dashbeam/shared/authz"
 	"github.com/lavish-gambhir/dashbeam/shared/config"
 	"github.com/lavish-gambhir/dashbeam/shared/database/clickhouse"
 	"github.com/lavish-gambhir/dashbeam/shared/database/postgres"
 	"github.com/lavish-gambhir/dashbeam/shared/database/repositories"
 	"github.com/lavish-gambhir/dashbeam/shared/middleware"
+	"github.com/lavish-gambhir/dashbeam/shared/ratelimit"
 	"github.com/lavish-gambhir/dashbeam/shared/streaming"
 )
 
@@ -32,10 +38,17 @@ type App struct {
 	pool   *pgxpool.Pool
 	server *http.Server
 	mux    *http.ServeMux
+	grpc   *grpc.Server // nil when server.grpc_port is not set
 
 	authSvc      auth.Service
+	adminSvc     admin.Service
 	ingestionSvc ingestion.Service
 	analyticsSvc analytics.Service
+	analyticsAPI analytics.API
+	outboxRelay  *ingestion.OutboxRelay
+
+	dashboardUsers *repositories.DashboardUserRepository
+	auditLog       *repositories.AuditLogRepository
 }
 
 func index(w http.ResponseWriter, _ *http.Request) {
@@ -50,24 +63,54 @@ func setupApp(ctx context.Context, cfg *config.AppConfig, pool *pgxpool.Pool, lo
 	userRepo := repositories.NewUserRepository(pgdb)
 	dashboardUserRepo := repositories.NewDashboardUserRepository(pgdb)
 	quizRepo := repositories.NewQuizRepository(pgdb)
-	q, err := streaming.NewRedisQueue(ctx, cfg, logger)
+	processedEventRepo := repositories.NewProcessedEventRepository(pgdb)
+	classroomRepo := repositories.NewClassroomRepository(pgdb)
+	outboxRepo := repositories.NewOutboxRepository(pgdb)
+	schoolRepo := repositories.NewSchoolRepository(pgdb)
+	auditLogRepo := repositories.NewAuditLogRepository(pgdb)
+	refreshTokenRepo := repositories.NewRefreshTokenRepository(pgdb)
+	q, dlq, err := newMessageQueue(ctx, cfg, logger)
 	if err != nil {
-		return nil, fmt.Errorf("failed to init redis queue: %v", err)
+		return nil, fmt.Errorf("failed to init message queue: %v", err)
 	}
 
 	authService := auth.New(
 		dashboardUserRepo,
+		refreshTokenRepo,
 		cfg.Auth,
 		logger,
 	)
 
-	ingestionService := ingestion.New(
+	adminService := admin.New(dlq, logger)
+
+	dedup, err := newDeduplicator(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init deduplicator: %v", err)
+	}
+
+	limiter, err := newRateLimiter(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init rate limiter: %v", err)
+	}
+
+	ingestionService, err := ingestion.New(
 		userRepo,
 		quizRepo,
+		processedEventRepo,
+		classroomRepo,
+		outboxRepo,
+		schoolRepo,
+		pgdb,
+		dedup,
+		limiter,
 		q,
+		cfg.Ingestion,
 		logger,
-		0,
 	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init ingestion service: %v", err)
+	}
+	outboxRelay := ingestion.NewOutboxRelay(outboxRepo, pgdb, q, cfg.Ingestion, logger)
 
 	// Create ClickHouse connection
 	clickhouseDB, err := clickhouse.New(cfg.Analytics, logger)
@@ -77,7 +120,11 @@ func setupApp(ctx context.Context, cfg *config.AppConfig, pool *pgxpool.Pool, lo
 
 	// Create analytics dependencies
 	clickhouseRepo := repositories.NewClickHouseRepository(clickhouseDB)
-	eventProcessor := analytics.NewEventProcessor(clickhouseRepo, logger, cfg.Analytics.BatchSize)
+	piiPolicy, err := analytics.NewPIIPolicy(cfg.Analytics.PII, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load PII policy: %v", err)
+	}
+	eventProcessor := analytics.NewEventProcessor(clickhouseRepo, piiPolicy, logger, cfg.Analytics.BatchSize)
 
 	analyticsService := analytics.New(
 		q, // message queue
@@ -85,6 +132,8 @@ func setupApp(ctx context.Context, cfg *config.AppConfig, pool *pgxpool.Pool, lo
 		cfg.Analytics,
 		logger,
 	)
+	metricsReader := analytics.NewMetricsReader(clickhouseRepo, schoolRepo, cfg.Analytics, logger)
+	analyticsAPI := analytics.NewAPI(metricsReader, logger)
 	//=== deps [end] ====
 
 	server := &http.Server{
@@ -102,15 +151,89 @@ func setupApp(ctx context.Context, cfg *config.AppConfig, pool *pgxpool.Pool, lo
 		server:       server,
 		mux:          mux,
 		authSvc:      authService,
+		adminSvc:     adminService,
 		ingestionSvc: ingestionService,
 		analyticsSvc: analyticsService,
+		analyticsAPI: analyticsAPI,
+		outboxRelay:  outboxRelay,
+
+		dashboardUsers: dashboardUserRepo,
+		auditLog:       auditLogRepo,
 	}
 
 	app.registerRoutes(cfg, logger)
+	app.registerGRPC(cfg, logger)
 
 	return app, nil
 }
 
+// newMessageQueue builds the MessageQueue selected by queue.driver, along with
+// its dead-letter queue. Streams is the default since it keeps events published
+// while consumers are down; memory runs everything in-process with no external
+// broker.
+func newMessageQueue(ctx context.Context, cfg *config.AppConfig, logger *slog.Logger) (streaming.MessageQueue, streaming.DeadLetterQueue, error) {
+	switch cfg.Queue.Driver {
+	case "", "streams":
+		q, err := streaming.NewStreamQueue(ctx, cfg, logger)
+		if err != nil {
+			return nil, nil, err
+		}
+		return q, q.DeadLetters(), nil
+	case "pubsub":
+		q, err := streaming.NewRedisQueue(ctx, cfg, logger)
+		if err != nil {
+			return nil, nil, err
+		}
+		return q, q.DeadLetters(), nil
+	case "memory":
+		codec, err := streaming.NewCodec(cfg.Queue.Codec)
+		if err != nil {
+			return nil, nil, err
+		}
+		q := streaming.NewMemoryQueue(codec, logger)
+		return q, q.DeadLetters(), nil
+	default:
+		return nil, nil, fmt.Errorf("unknown queue driver: %s", cfg.Queue.Driver)
+	}
+}
+
+// newDeduplicator keeps ingested event IDs in Valkey, shared across instances,
+// unless the queue runs in memory.
+func newDeduplicator(ctx context.Context, cfg *config.AppConfig) (streaming.Deduplicator, error) {
+	if cfg.Queue.Driver == "memory" {
+		return streaming.NewMemoryDeduplicator(cfg.Ingestion.DedupeWindow), nil
+	}
+	client, err := streaming.NewRedisClient(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return streaming.NewRedisDeduplicator(client, cfg.Ingestion.DedupeWindow), nil
+}
+
+// newRateLimiter keeps rate limit buckets in Valkey, shared across instances,
+// unless the queue runs in memory.
+func newRateLimiter(ctx context.Context, cfg *config.AppConfig) (ratelimit.Limiter, error) {
+	if cfg.Queue.Driver == "memory" {
+		return ratelimit.NewMemoryLimiter(), nil
+	}
+	client, err := streaming.NewRedisClient(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return ratelimit.NewRedisLimiter(client), nil
+}
+
+// dashboardRoutes is the permission each dashboard route requires. Routes
+// under /analytics/schools/{schoolID} also require access to the school.
+var dashboardRoutes = authz.NewRoutes(map[string]authz.Permission{
+	"GET /analytics/schools/{schoolID}/": authz.AnalyticsRead,
+	"/admin/dlq/events":                  authz.DLQRead,
+	"/admin/dlq/events/{topic}/{id}":     authz.DLQRead,
+	"/admin/dlq/replay":                  authz.DLQManage,
+	"/admin/dlq/purge":                   authz.DLQManage,
+	"/admin/metrics":                     authz.MetricsRead,
+})
+
 func (a *App) registerRoutes(cfg *config.AppConfig, logger *slog.Logger) {
 	authMiddleware := middleware.NewAuthMiddleware(cfg.Auth, logger)
 
@@ -123,6 +246,32 @@ func (a *App) registerRoutes(cfg *config.AppConfig, logger *slog.Logger) {
 	protectedMux := http.NewServeMux()
 	a.ingestionSvc.RegisterRoutes(protectedMux, "/events")
 	a.mux.Handle("/events/", authMiddleware.RequireAuth(protectedMux))
+
+	// Dashboard routes (require a dashboard JWT and the permission of the route)
+	dashboardAuth := middleware.NewDashboardAuthMiddleware(cfg.Auth, a.dashboardUsers, a.auditLog, logger)
+	authorize := dashboardAuth.Authorize(dashboardRoutes)
+
+	adminMux := http.NewServeMux()
+	a.adminSvc.RegisterRoutes(adminMux, "/admin")
+	a.mux.Handle("/admin/", authorize(adminMux))
+
+	analyticsMux := http.NewServeMux()
+	a.analyticsAPI.RegisterRoutes(analyticsMux, "/analytics")
+	a.mux.Handle("/analytics/", authorize(analyticsMux))
+}
+
+// registerGRPC sets up the gRPC server, whose calls all require a mobile JWT
+// like the protected HTTP routes.
+func (a *App) registerGRPC(cfg *config.AppConfig, logger *slog.Logger) {
+	if cfg.Server.GRPCPort == "" {
+		return
+	}
+	authMiddleware := middleware.NewAuthMiddleware(cfg.Auth, logger)
+	a.grpc = grpc.NewServer(
+		grpc.ChainUnaryInterceptor(middleware.UnaryLogging(logger), authMiddleware.UnaryAuth()),
+		grpc.ChainStreamInterceptor(middleware.StreamLogging(logger), authMiddleware.StreamAuth()),
+	)
+	a.ingestionSvc.RegisterGRPC(a.grpc)
 }
 
 func (a *App) Start(ctx context.Context, logger *slog.Logger) <-chan error {
@@ -137,6 +286,9 @@ func (a *App) Start(ctx context.Context, logger *slog.Logger) <-chan error {
 		}
 	}()
 
+	// Publish events committed with their operational updates
+	go a.outboxRelay.Run(ctx)
+
 	go func() {
 		<-ctx.Done()
 
@@ -157,6 +309,18 @@ func (a *App) Start(ctx context.Context, logger *slog.Logger) <-chan error {
 		if err := a.server.Shutdown(ctxTimeout); err != nil {
 			errC <- err
 		}
+		if a.grpc != nil {
+			stopped := make(chan struct{})
+			go func() {
+				a.grpc.GracefulStop()
+				close(stopped)
+			}()
+			select {
+			case <-stopped:
+			case <-ctxTimeout.Done():
+				a.grpc.Stop()
+			}
+		}
 
 		logger.Info("=== dashbeam shut down complete ===")
 	}()
@@ -168,6 +332,21 @@ func (a *App) Start(ctx context.Context, logger *slog.Logger) <-chan error {
 		}
 	}()
 
+	if a.grpc != nil {
+		go func() {
+			addr := fmt.Sprintf(":%s", a.config.Server.GRPCPort)
+			lis, err := net.Listen("tcp", addr)
+			if err != nil {
+				errC <- err
+				return
+			}
+			logger.Info("Serving gRPC", "addr", addr)
+			if err := a.grpc.Serve(lis); err != nil {
+				errC <- err
+			}
+		}()
+	}
+
 	return errC
 }
 
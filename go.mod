@@ -9,10 +9,12 @@ require (
 	github.com/jackc/pgx/v5 v5.7.5
 	github.com/joho/godotenv v1.5.1
 	github.com/lavish-gambhir/dashbeam/pkg/logger v0.0.0-00010101000000-000000000000
+	github.com/lavish-gambhir/dashbeam/services/admin v0.0.0-00010101000000-000000000000
 	github.com/lavish-gambhir/dashbeam/services/analytics v0.0.0-00010101000000-000000000000
 	github.com/lavish-gambhir/dashbeam/services/auth v0.0.0-00010101000000-000000000000
 	github.com/lavish-gambhir/dashbeam/services/ingestion v0.0.0-00010101000000-000000000000
 	github.com/lavish-gambhir/dashbeam/shared v0.0.0
+	google.golang.org/grpc v1.73.0
 )
 
 require (
@@ -50,15 +52,21 @@ require (
 	github.com/spf13/pflag v1.0.6 // indirect
 	github.com/spf13/viper v1.20.1 // indirect
 	github.com/subosito/gotenv v1.6.0 // indirect
+	github.com/vmihailenco/msgpack/v5 v5.4.1 // indirect
+	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
 	go.opentelemetry.io/otel v1.36.0 // indirect
 	go.opentelemetry.io/otel/trace v1.36.0 // indirect
 	go.uber.org/atomic v1.9.0 // indirect
 	go.uber.org/multierr v1.11.0 // indirect
 	golang.org/x/crypto v0.38.0 // indirect
+	golang.org/x/net v0.38.0 // indirect
 	golang.org/x/oauth2 v0.25.0 // indirect
 	golang.org/x/sync v0.14.0 // indirect
 	golang.org/x/sys v0.33.0 // indirect
 	golang.org/x/text v0.25.0 // indirect
+	google.golang.org/genproto v0.0.0-20250603155806-513f23925822 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20250324211829-b45e905df463 // indirect
+	google.golang.org/protobuf v1.36.6 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
 
@@ -70,6 +78,8 @@ replace github.com/lavish-gambhir/dashbeam/pkg/utils => ./pkg/utils
 
 replace github.com/lavish-gambhir/dashbeam/shared => ./shared
 
+replace github.com/lavish-gambhir/dashbeam/services/admin => ./services/admin
+
 replace github.com/lavish-gambhir/dashbeam/services/auth => ./services/auth
 
 replace github.com/lavish-gambhir/dashbeam/services/quiz => ./services/quiz
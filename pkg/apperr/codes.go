@@ -13,11 +13,13 @@ const (
 	Forbidden          ErrCode = "FORBIDDEN"
 	Conflict           ErrCode = "CONFLICT"
 	ServiceUnavailable ErrCode = "SERVICE_UNAVAILABLE"
+	RateLimited        ErrCode = "RATE_LIMITED"
 
 	// Authentication Specific Error Codes
 	InvalidCredentials ErrCode = "AUTH_INVALID_CREDENTIALS"
 	TokenExpired       ErrCode = "AUTH_TOKEN_EXPIRED"
 	InvalidToken       ErrCode = "AUTH_INVALID_TOKEN"
+	TokenReused        ErrCode = "AUTH_TOKEN_REUSED"
 	UserNotFound       ErrCode = "AUTH_USER_NOT_FOUND"
 	UserAlreadyExists  ErrCode = "AUTH_USER_ALREADY_EXISTS"
 
@@ -0,0 +1,170 @@
+package admin
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/lavish-gambhir/dashbeam/pkg/apperr"
+	"github.com/lavish-gambhir/dashbeam/pkg/utils"
+	sharedcontext "github.com/lavish-gambhir/dashbeam/shared/context"
+	"github.com/lavish-gambhir/dashbeam/shared/streaming"
+)
+
+type handler struct {
+	dlq    streaming.DeadLetterQueue
+	logger *slog.Logger
+}
+
+// handleListDeadLetters lists dead-lettered events, newest first. Supports the
+// topic, event_type, error, status and limit query parameters.
+func (h *handler) handleListDeadLetters(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID, _ := sharedcontext.GetRequestID(ctx)
+	logger := h.logger.With("fn", "handleListDeadLetters").With("requestID", reqID)
+
+	if r.Method != http.MethodGet {
+		utils.WriteJSONError(w, apperr.New(apperr.BadRequest, "method not allowed"), http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := r.URL.Query()
+	filter := streaming.DLQFilter{
+		Topic:         q.Get("topic"),
+		EventType:     streaming.EventType(q.Get("event_type")),
+		ErrorContains: q.Get("error"),
+		Status:        q.Get("status"),
+		Limit:         defaultListLimit,
+	}
+	if limit := q.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 || n > maxListLimit {
+			utils.WriteJSONError(w, apperr.Newf(apperr.BadRequest, "limit must be between 1 and %d", maxListLimit), http.StatusBadRequest)
+			return
+		}
+		filter.Limit = n
+	}
+
+	events, err := h.dlq.List(ctx, filter)
+	if err != nil {
+		logger.Error("failed to list dead letters", slog.Any("error", err))
+		utils.WriteJSONError(w, err, statusFor(err))
+		return
+	}
+	if events == nil {
+		events = []streaming.FailedEvent{}
+	}
+	utils.WriteJSONSuccess(w, DeadLetterListResponse{Events: events, Count: len(events)})
+}
+
+func (h *handler) handleGetDeadLetter(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID, _ := sharedcontext.GetRequestID(ctx)
+	logger := h.logger.With("fn", "handleGetDeadLetter").With("requestID", reqID)
+
+	if r.Method != http.MethodGet {
+		utils.WriteJSONError(w, apperr.New(apperr.BadRequest, "method not allowed"), http.StatusMethodNotAllowed)
+		return
+	}
+
+	eventID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		utils.WriteJSONError(w, apperr.Wrap(err, apperr.InvalidFormat, "invalid event id"), http.StatusBadRequest)
+		return
+	}
+
+	fe, err := h.dlq.Get(ctx, r.PathValue("topic"), eventID)
+	if err != nil {
+		if !apperr.Is(err, apperr.NotFound) {
+			logger.Error("failed to get dead letter", slog.Any("error", err))
+		}
+		utils.WriteJSONError(w, err, statusFor(err))
+		return
+	}
+	utils.WriteJSONSuccess(w, fe)
+}
+
+func (h *handler) handleReplayDeadLetters(w http.ResponseWriter, r *http.Request) {
+	h.handleSelection(w, r, "replay", func(ctx context.Context, ref DeadLetterRef) (int, error) {
+		fe, err := h.dlq.Replay(ctx, ref.Topic, ref.EventID)
+		if err != nil {
+			return 0, err
+		}
+		return fe.RetryCount, nil
+	})
+}
+
+func (h *handler) handlePurgeDeadLetters(w http.ResponseWriter, r *http.Request) {
+	h.handleSelection(w, r, "purge", func(ctx context.Context, ref DeadLetterRef) (int, error) {
+		return 0, h.dlq.Purge(ctx, ref.Topic, ref.EventID)
+	})
+}
+
+// handleSelection applies op to every selected event and reports the outcome
+// per event; one failing event does not stop the others.
+func (h *handler) handleSelection(w http.ResponseWriter, r *http.Request, action string, op func(context.Context, DeadLetterRef) (int, error)) {
+	ctx := r.Context()
+	reqID, _ := sharedcontext.GetRequestID(ctx)
+	user, _ := sharedcontext.GetDashboardUser(ctx)
+	logger := h.logger.With("fn", "handleSelection", "action", action).With("requestID", reqID)
+	if user != nil {
+		logger = logger.With("username", user.Username)
+	}
+
+	if r.Method != http.MethodPost {
+		utils.WriteJSONError(w, apperr.New(apperr.BadRequest, "method not allowed"), http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req DeadLetterSelectionRequest
+	if err := utils.FromJson(r.Body, &req); err != nil {
+		utils.WriteJSONError(w, apperr.Wrap(err, apperr.BadRequest, "invalid request body"), http.StatusBadRequest)
+		return
+	}
+	if len(req.Events) == 0 {
+		utils.WriteJSONError(w, apperr.New(apperr.BadRequest, "no events selected"), http.StatusBadRequest)
+		return
+	}
+	if len(req.Events) > maxSelection {
+		utils.WriteJSONError(w, apperr.Newf(apperr.BadRequest, "at most %d events can be selected", maxSelection), http.StatusBadRequest)
+		return
+	}
+
+	resp := DeadLetterSelectionResponse{Results: make([]DeadLetterResult, 0, len(req.Events))}
+	for _, ref := range req.Events {
+		result := DeadLetterResult{Topic: ref.Topic, EventID: ref.EventID}
+		if ref.Topic == "" || ref.EventID == uuid.Nil {
+			result.Error = "topic and event_id are required"
+		} else if retryCount, err := op(ctx, ref); err != nil {
+			result.Error = apperr.GetMessage(err)
+		} else {
+			result.Success = true
+			result.RetryCount = retryCount
+		}
+
+		if result.Success {
+			resp.Succeeded++
+		} else {
+			resp.Failed++
+		}
+		resp.Results = append(resp.Results, result)
+	}
+
+	logger.Info("dead letters processed", slog.Int("succeeded", resp.Succeeded), slog.Int("failed", resp.Failed))
+	utils.WriteJSONSuccess(w, resp)
+}
+
+func statusFor(err error) int {
+	switch apperr.GetCode(err) {
+	case apperr.NotFound:
+		return http.StatusNotFound
+	case apperr.BadRequest:
+		return http.StatusBadRequest
+	case apperr.Conflict:
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
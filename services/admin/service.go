@@ -0,0 +1,43 @@
+package admin
+
+import (
+	"expvar"
+	"log/slog"
+	"net/http"
+
+	"github.com/lavish-gambhir/dashbeam/shared/streaming"
+)
+
+// Service exposes operational endpoints for dashboard admins. Callers are
+// expected to mount it behind admin-only authentication.
+type Service interface {
+	RegisterRoutes(mux *http.ServeMux, prefix string)
+}
+
+type service struct {
+	dlq    streaming.DeadLetterQueue
+	logger *slog.Logger
+}
+
+func New(dlq streaming.DeadLetterQueue, logger *slog.Logger) Service {
+	return &service{
+		dlq:    dlq,
+		logger: logger,
+	}
+}
+
+// RegisterRoutes registers all admin service routes
+func (s *service) RegisterRoutes(parentmux *http.ServeMux, prefix string) {
+	h := &handler{
+		dlq:    s.dlq,
+		logger: s.logger.With("handler", "admin.handler"),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/dlq/events", h.handleListDeadLetters)
+	mux.HandleFunc("/dlq/events/{topic}/{id}", h.handleGetDeadLetter)
+	mux.HandleFunc("/dlq/replay", h.handleReplayDeadLetters)
+	mux.HandleFunc("/dlq/purge", h.handlePurgeDeadLetters)
+	mux.Handle("/metrics", expvar.Handler())
+	parentmux.Handle(prefix+"/", http.StripPrefix(prefix, mux))
+}
@@ -0,0 +1,41 @@
+package admin
+
+import (
+	"github.com/google/uuid"
+	"github.com/lavish-gambhir/dashbeam/shared/streaming"
+)
+
+const (
+	defaultListLimit = 100
+	maxListLimit     = 1000
+	maxSelection     = 500
+)
+
+type DeadLetterListResponse struct {
+	Events []streaming.FailedEvent `json:"events"`
+	Count  int                     `json:"count"`
+}
+
+// DeadLetterRef selects one dead-lettered event.
+type DeadLetterRef struct {
+	Topic   string    `json:"topic"`
+	EventID uuid.UUID `json:"event_id"`
+}
+
+type DeadLetterSelectionRequest struct {
+	Events []DeadLetterRef `json:"events"`
+}
+
+type DeadLetterResult struct {
+	Topic      string    `json:"topic"`
+	EventID    uuid.UUID `json:"event_id"`
+	Success    bool      `json:"success"`
+	RetryCount int       `json:"retry_count,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+type DeadLetterSelectionResponse struct {
+	Results   []DeadLetterResult `json:"results"`
+	Succeeded int                `json:"succeeded"`
+	Failed    int                `json:"failed"`
+}
@@ -0,0 +1,41 @@
+package analytics
+
+import (
+	"log/slog"
+	"net/http"
+)
+
+// API serves the aggregated metrics to dashboards. Callers are expected to
+// mount it behind the dashboard authorization middleware; a user only sees
+// the schools in its school access.
+type API interface {
+	RegisterRoutes(mux *http.ServeMux, prefix string)
+}
+
+type api struct {
+	reader *MetricsReader
+	logger *slog.Logger
+}
+
+func NewAPI(reader *MetricsReader, logger *slog.Logger) API {
+	return &api{
+		reader: reader,
+		logger: logger,
+	}
+}
+
+// RegisterRoutes registers the time series routes. All of them take the
+// start_date, end_date, granularity, limit and offset query parameters.
+func (a *api) RegisterRoutes(parentmux *http.ServeMux, prefix string) {
+	h := &handler{
+		reader: a.reader,
+		logger: a.logger.With("handler", "analytics.handler"),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /schools/{schoolID}/metrics", h.handleSchoolMetrics)
+	mux.HandleFunc("GET /schools/{schoolID}/classrooms/{classroomID}/metrics", h.handleClassroomMetrics)
+	mux.HandleFunc("GET /schools/{schoolID}/quizzes/{quizID}/metrics", h.handleQuizMetrics)
+	mux.HandleFunc("GET /schools/{schoolID}/users/{userID}/activity", h.handleUserActivity)
+	parentmux.Handle(prefix+"/", http.StripPrefix(prefix, mux))
+}
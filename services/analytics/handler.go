@@ -0,0 +1,156 @@
+package analytics
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"github.com/lavish-gambhir/dashbeam/pkg/apperr"
+	"github.com/lavish-gambhir/dashbeam/pkg/utils"
+	sharedcontext "github.com/lavish-gambhir/dashbeam/shared/context"
+	"github.com/lavish-gambhir/dashbeam/shared/models"
+)
+
+type handler struct {
+	reader *MetricsReader
+	logger *slog.Logger
+}
+
+func (h *handler) handleSchoolMetrics(w http.ResponseWriter, r *http.Request) {
+	serveSeries(h, w, r, "handleSchoolMetrics", h.reader.SchoolMetrics)
+}
+
+func (h *handler) handleClassroomMetrics(w http.ResponseWriter, r *http.Request) {
+	classroomID, err := uuid.Parse(r.PathValue("classroomID"))
+	if err != nil {
+		utils.WriteJSONError(w, apperr.Wrap(err, apperr.InvalidFormat, "invalid classroom id"), http.StatusBadRequest)
+		return
+	}
+	serveSeries(h, w, r, "handleClassroomMetrics", func(ctx context.Context, schoolID uuid.UUID, dates models.DateRange, page models.Page) ([]models.ClassroomMetric, error) {
+		return h.reader.ClassroomMetrics(ctx, schoolID, classroomID, dates, page)
+	})
+}
+
+func (h *handler) handleQuizMetrics(w http.ResponseWriter, r *http.Request) {
+	quizID, err := uuid.Parse(r.PathValue("quizID"))
+	if err != nil {
+		utils.WriteJSONError(w, apperr.Wrap(err, apperr.InvalidFormat, "invalid quiz id"), http.StatusBadRequest)
+		return
+	}
+	serveSeries(h, w, r, "handleQuizMetrics", func(ctx context.Context, schoolID uuid.UUID, dates models.DateRange, page models.Page) ([]models.QuizMetric, error) {
+		return h.reader.QuizMetrics(ctx, schoolID, quizID, dates, page)
+	})
+}
+
+func (h *handler) handleUserActivity(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		utils.WriteJSONError(w, apperr.Wrap(err, apperr.InvalidFormat, "invalid user id"), http.StatusBadRequest)
+		return
+	}
+	serveSeries(h, w, r, "handleUserActivity", func(ctx context.Context, schoolID uuid.UUID, dates models.DateRange, page models.Page) ([]models.UserActivityMetric, error) {
+		return h.reader.UserActivity(ctx, schoolID, userID, dates, page)
+	})
+}
+
+// serveSeries writes the time series of the school in the path loaded by
+// load, after checking the dashboard user may see the school.
+func serveSeries[T any](h *handler, w http.ResponseWriter, r *http.Request, fn string,
+	load func(ctx context.Context, schoolID uuid.UUID, dates models.DateRange, page models.Page) ([]T, error)) {
+	ctx := r.Context()
+	reqID, _ := sharedcontext.GetRequestID(ctx)
+	logger := h.logger.With("fn", fn).With("requestID", reqID)
+
+	principal, ok := sharedcontext.GetPrincipal(ctx)
+	if !ok {
+		logger.Error("principal not found - middleware not applied correctly")
+		utils.WriteJSONError(w, apperr.New(apperr.Unauthorized, "authentication context missing"), http.StatusUnauthorized)
+		return
+	}
+	logger = logger.With("username", principal.Username)
+
+	schoolID, err := uuid.Parse(r.PathValue("schoolID"))
+	if err != nil {
+		utils.WriteJSONError(w, apperr.Wrap(err, apperr.InvalidFormat, "invalid school id"), http.StatusBadRequest)
+		return
+	}
+	if !principal.CanAccessSchool(schoolID) {
+		logger.Warn("dashboard user has no access to school", slog.String("schoolID", schoolID.String()))
+		utils.WriteJSONError(w, apperr.New(apperr.Forbidden, "no access to school"), http.StatusForbidden)
+		return
+	}
+
+	q := r.URL.Query()
+	page, err := parsePage(q.Get("limit"), q.Get("offset"))
+	if err != nil {
+		utils.WriteJSONError(w, err, http.StatusBadRequest)
+		return
+	}
+	dates, err := h.reader.DateRange(ctx, schoolID, q.Get("start_date"), q.Get("end_date"), models.Granularity(q.Get("granularity")))
+	if err != nil {
+		if !apperr.Is(err, apperr.DBRecordNotFound) {
+			logger.Error("failed to look up school timezone", slog.Any("error", err))
+		}
+		utils.WriteJSONError(w, err, statusFor(err))
+		return
+	}
+
+	// One more than asked for tells whether there is a next page.
+	series, err := load(ctx, schoolID, dates, models.Page{Limit: page.Limit + 1, Offset: page.Offset})
+	if err != nil {
+		if !apperr.Is(err, apperr.BadRequest) {
+			logger.Error("failed to load metrics", slog.Any("error", err))
+		}
+		utils.WriteJSONError(w, err, statusFor(err))
+		return
+	}
+	hasMore := len(series) > page.Limit
+	if hasMore {
+		series = series[:page.Limit]
+	}
+	if series == nil {
+		series = []T{}
+	}
+
+	utils.WriteJSONSuccess(w, SeriesResponse[T]{
+		StartDate:   dates.StartDate,
+		EndDate:     dates.EndDate,
+		Timezone:    dates.Timezone,
+		Granularity: dates.Granularity,
+		Series:      series,
+		Pagination:  Pagination{Limit: page.Limit, Offset: page.Offset, HasMore: hasMore},
+	})
+}
+
+func parsePage(limit, offset string) (models.Page, error) {
+	page := models.Page{Limit: defaultSeriesLimit}
+	if limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 || n > maxSeriesLimit {
+			return page, apperr.Newf(apperr.BadRequest, "limit must be between 1 and %d", maxSeriesLimit)
+		}
+		page.Limit = n
+	}
+	if offset != "" {
+		n, err := strconv.Atoi(offset)
+		if err != nil || n < 0 {
+			return page, apperr.New(apperr.BadRequest, "offset must be a non-negative integer")
+		}
+		page.Offset = n
+	}
+	return page, nil
+}
+
+func statusFor(err error) int {
+	switch apperr.GetCode(err) {
+	case apperr.NotFound, apperr.DBRecordNotFound:
+		return http.StatusNotFound
+	case apperr.BadRequest:
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
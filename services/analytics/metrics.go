@@ -0,0 +1,18 @@
+package analytics
+
+import "expvar"
+
+// Analytics counters, published with expvar (see GET /admin/metrics).
+var (
+	// eventsReordered counts quiz session events that arrived after an event
+	// of their session that happened later, and were put back in order.
+	eventsReordered = expvar.NewInt("analytics_events_reordered")
+
+	// eventsLate counts quiz session events that arrived after a later event
+	// of their session was already processed.
+	eventsLate = expvar.NewInt("analytics_events_late")
+
+	// piiFieldsRedacted counts metadata fields dropped, hashed or truncated
+	// by the PII policy.
+	piiFieldsRedacted = expvar.NewInt("analytics_pii_fields_redacted")
+)
@@ -0,0 +1,157 @@
+package analytics
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/lavish-gambhir/dashbeam/shared/config"
+	"github.com/lavish-gambhir/dashbeam/shared/models"
+	"github.com/lavish-gambhir/dashbeam/shared/streaming"
+)
+
+const (
+	PIIDrop     = "drop"
+	PIIHash     = "hash"
+	PIITruncate = "truncate"
+	PIIKeep     = "keep"
+)
+
+const (
+	defaultPIIPolicyVersion = "builtin-1"
+	defaultSaltRotation     = 30 * 24 * time.Hour
+	defaultTruncateLength   = 256
+)
+
+// defaultPIIRules is the policy used when analytics.pii has no rules.
+var defaultPIIRules = []config.PIIRule{
+	{Field: "email", Action: PIIHash},
+	{Field: "user_agent", Action: PIIDrop},
+	{EventType: streaming.ErrorOccurred.String(), Field: "stack_trace", Action: PIITruncate, MaxLength: 1024},
+	{EventType: streaming.ErrorOccurred.String(), Field: "error_message", Action: PIITruncate},
+	{EventType: streaming.ErrorOccurred.String(), Field: "context", Action: PIITruncate},
+}
+
+// PIIPolicy redacts the metadata of analytics records before they are
+// stored. Each rule applies an action to one metadata field, of one event
+// type or of all of them; a rule for the event type wins over one for all
+// types, and fields without a rule are kept.
+//
+// Hashed values are keyed with a salt derived from the hash key and the
+// period the event happened in, so a value hashes the same within a period,
+// also when an event is redelivered, and can't be linked across periods.
+//
+// Every record is stamped with the policy version it was redacted under.
+type PIIPolicy struct {
+	version      string
+	hashKey      []byte
+	saltRotation time.Duration
+	byType       map[string]config.PIIRule // event type + "\x00" + field
+	byField      map[string]config.PIIRule
+}
+
+func NewPIIPolicy(cfg config.PIIConfig, logger *slog.Logger) (*PIIPolicy, error) {
+	rules, version := cfg.Rules, cfg.PolicyVersion
+	if len(rules) == 0 {
+		rules = defaultPIIRules
+		if version == "" {
+			version = defaultPIIPolicyVersion
+		}
+	}
+	if version == "" {
+		return nil, fmt.Errorf("analytics.pii.policy_version is required with custom rules")
+	}
+
+	p := &PIIPolicy{
+		version:      version,
+		hashKey:      []byte(cfg.HashKey),
+		saltRotation: cfg.SaltRotation,
+		byType:       make(map[string]config.PIIRule),
+		byField:      make(map[string]config.PIIRule),
+	}
+	if p.saltRotation <= 0 {
+		p.saltRotation = defaultSaltRotation
+	}
+
+	for _, rule := range rules {
+		switch rule.Action {
+		case PIIDrop, PIIHash, PIIKeep:
+		case PIITruncate:
+			if rule.MaxLength <= 0 {
+				rule.MaxLength = defaultTruncateLength
+			}
+		default:
+			return nil, fmt.Errorf("unknown PII action %q for field %s", rule.Action, rule.Field)
+		}
+		if rule.Field == "" {
+			return nil, fmt.Errorf("PII rule without a field")
+		}
+		if rule.Action == PIIHash && len(p.hashKey) == 0 {
+			logger.Warn("no PII hash key configured, dropping field instead of hashing it",
+				slog.String("field", rule.Field))
+			rule.Action = PIIDrop
+		}
+		if rule.EventType == "" {
+			p.byField[rule.Field] = rule
+		} else {
+			p.byType[rule.EventType+"\x00"+rule.Field] = rule
+		}
+	}
+
+	logger.Info("PII policy loaded", slog.String("version", p.version), slog.Int("rules", len(rules)))
+	return p, nil
+}
+
+// Apply redacts record.Metadata in place and records the policy version.
+func (p *PIIPolicy) Apply(record *models.AnalyticsRecord) {
+	record.PIIPolicyVersion = p.version
+	for field, value := range record.Metadata {
+		rule, ok := p.byType[record.EventType+"\x00"+field]
+		if !ok {
+			rule, ok = p.byField[field]
+		}
+		if !ok {
+			continue
+		}
+
+		switch rule.Action {
+		case PIIDrop:
+			delete(record.Metadata, field)
+		case PIIHash:
+			record.Metadata[field] = p.hash(fmt.Sprint(value), record.Timestamp)
+		case PIITruncate:
+			if s, ok := value.(string); ok {
+				record.Metadata[field] = truncateRunes(s, rule.MaxLength)
+			}
+		}
+		if rule.Action != PIIKeep {
+			piiFieldsRedacted.Add(1)
+		}
+	}
+}
+
+// hash returns the keyed hash of value with the salt of the period at falls
+// in.
+func (p *PIIPolicy) hash(value string, at time.Time) string {
+	period := at.UTC().Truncate(p.saltRotation)
+	salt := hmac.New(sha256.New, p.hashKey)
+	salt.Write([]byte(period.Format(time.RFC3339)))
+
+	mac := hmac.New(sha256.New, salt.Sum(nil))
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+func truncateRunes(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
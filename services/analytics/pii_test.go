@@ -0,0 +1,185 @@
+package analytics
+
+import (
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lavish-gambhir/dashbeam/shared/config"
+	"github.com/lavish-gambhir/dashbeam/shared/models"
+	"github.com/lavish-gambhir/dashbeam/shared/streaming"
+)
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// hashed stands in a wanted metadata map for the policy's hash of a value.
+type hashed string
+
+func TestPIIPolicyApply(t *testing.T) {
+	errorType := streaming.ErrorOccurred.String()
+	foregroundType := streaming.AppForeground.String()
+	long := strings.Repeat("x", 2000)
+
+	tests := []struct {
+		name        string
+		cfg         config.PIIConfig
+		eventType   string
+		metadata    map[string]any
+		want        map[string]any
+		wantVersion string
+	}{
+		{
+			name:        "default rules",
+			cfg:         config.PIIConfig{HashKey: "key"},
+			eventType:   foregroundType,
+			metadata:    map[string]any{"email": "ada@example.com", "user_agent": "Mozilla/5.0", "screen": "home"},
+			want:        map[string]any{"email": hashed("ada@example.com"), "screen": "home"},
+			wantVersion: defaultPIIPolicyVersion,
+		},
+		{
+			name:        "default rules for errors",
+			cfg:         config.PIIConfig{HashKey: "key"},
+			eventType:   errorType,
+			metadata:    map[string]any{"stack_trace": long, "error_message": long, "context": 42},
+			want:        map[string]any{"stack_trace": long[:1024], "error_message": long[:defaultTruncateLength], "context": 42},
+			wantVersion: defaultPIIPolicyVersion,
+		},
+		{
+			name:        "type rules only for their type",
+			cfg:         config.PIIConfig{HashKey: "key"},
+			eventType:   foregroundType,
+			metadata:    map[string]any{"stack_trace": long},
+			want:        map[string]any{"stack_trace": long},
+			wantVersion: defaultPIIPolicyVersion,
+		},
+		{
+			name: "type rule wins",
+			cfg: config.PIIConfig{PolicyVersion: "v2", HashKey: "key", Rules: []config.PIIRule{
+				{Field: "note", Action: PIIDrop},
+				{EventType: errorType, Field: "note", Action: PIIKeep},
+			}},
+			eventType:   errorType,
+			metadata:    map[string]any{"note": "kept", "email": "ada@example.com"},
+			want:        map[string]any{"note": "kept", "email": "ada@example.com"},
+			wantVersion: "v2",
+		},
+		{
+			name: "truncate counts runes",
+			cfg: config.PIIConfig{PolicyVersion: "v2", Rules: []config.PIIRule{
+				{Field: "name", Action: PIITruncate, MaxLength: 3},
+			}},
+			eventType:   foregroundType,
+			metadata:    map[string]any{"name": "Zoë Ångström"},
+			want:        map[string]any{"name": "Zoë"},
+			wantVersion: "v2",
+		},
+		{
+			name:        "hash without a key drops",
+			cfg:         config.PIIConfig{},
+			eventType:   foregroundType,
+			metadata:    map[string]any{"email": "ada@example.com"},
+			want:        map[string]any{},
+			wantVersion: defaultPIIPolicyVersion,
+		},
+		{
+			name: "non-string values hashed",
+			cfg: config.PIIConfig{PolicyVersion: "v2", HashKey: "key", Rules: []config.PIIRule{
+				{Field: "student_number", Action: PIIHash},
+			}},
+			eventType:   foregroundType,
+			metadata:    map[string]any{"student_number": 1234},
+			want:        map[string]any{"student_number": hashed("1234")},
+			wantVersion: "v2",
+		},
+	}
+
+	at := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewPIIPolicy(tt.cfg, discardLogger())
+			if err != nil {
+				t.Fatal(err)
+			}
+			record := &models.AnalyticsRecord{EventType: tt.eventType, Timestamp: at, Metadata: tt.metadata}
+			p.Apply(record)
+
+			if record.PIIPolicyVersion != tt.wantVersion {
+				t.Errorf("policy version = %q, want %q", record.PIIPolicyVersion, tt.wantVersion)
+			}
+			if len(record.Metadata) != len(tt.want) {
+				t.Errorf("metadata = %v, want %v", record.Metadata, tt.want)
+			}
+			for field, want := range tt.want {
+				if h, ok := want.(hashed); ok {
+					want = p.hash(string(h), at)
+				}
+				if got, ok := record.Metadata[field]; !ok || got != want {
+					t.Errorf("metadata[%s] = %v, want %v", field, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestNewPIIPolicyRejectsInvalidRules(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.PIIConfig
+	}{
+		{name: "custom rules without a version", cfg: config.PIIConfig{Rules: []config.PIIRule{{Field: "email", Action: PIIDrop}}}},
+		{name: "unknown action", cfg: config.PIIConfig{PolicyVersion: "v2", Rules: []config.PIIRule{{Field: "email", Action: "encrypt"}}}},
+		{name: "no field", cfg: config.PIIConfig{PolicyVersion: "v2", Rules: []config.PIIRule{{Action: PIIDrop}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewPIIPolicy(tt.cfg, discardLogger()); err == nil {
+				t.Error("NewPIIPolicy() accepted invalid rules")
+			}
+		})
+	}
+}
+
+func TestPIIPolicySaltRotation(t *testing.T) {
+	cfg := config.PIIConfig{HashKey: "key", SaltRotation: 24 * time.Hour}
+	p, err := NewPIIPolicy(cfg, discardLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.HashKey = "other-key"
+	other, err := NewPIIPolicy(cfg, discardLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	morning := time.Date(2025, 6, 1, 8, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		p      *PIIPolicy
+		value  string
+		at     time.Time
+		linked bool
+	}{
+		{name: "redelivered", p: p, value: "ada@example.com", at: morning, linked: true},
+		{name: "same period", p: p, value: "ada@example.com", at: morning.Add(15 * time.Hour), linked: true},
+		{name: "same period in another zone", p: p, value: "ada@example.com", at: morning.In(time.FixedZone("UTC+9", 9*3600)), linked: true},
+		{name: "next period", p: p, value: "ada@example.com", at: morning.Add(16 * time.Hour)},
+		{name: "other value", p: p, value: "grace@example.com", at: morning},
+		{name: "other key", p: other, value: "ada@example.com", at: morning},
+	}
+	want := p.hash("ada@example.com", morning)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.p.hash(tt.value, tt.at)
+			if len(got) != 32 {
+				t.Errorf("hash %q is %d characters, want 32", got, len(got))
+			}
+			if (got == want) != tt.linked {
+				t.Errorf("hash %s, first %s: linked = %v, want %v", got, want, got == want, tt.linked)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package analytics
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/lavish-gambhir/dashbeam/services/analytics/repository"
+	"github.com/lavish-gambhir/dashbeam/shared/config"
+	"github.com/lavish-gambhir/dashbeam/shared/models"
+)
+
+// defaultRangeDays is the length of the date range when no start date is
+// given.
+const defaultRangeDays = 30
+
+// MetricsReader reads the aggregated metrics of a school. Days, weeks and
+// months are those of the school's timezone, as set in the DateRange built by
+// DateRange.
+type MetricsReader struct {
+	clickhouseRepo repository.ClickHouse
+	timezones      *schoolTimezones
+}
+
+func NewMetricsReader(
+	clickhouseRepo repository.ClickHouse,
+	schoolRepo repository.School,
+	config config.AnalyticsConfig,
+	logger *slog.Logger,
+) *MetricsReader {
+	logger = logger.With("component", "metrics_reader")
+	return &MetricsReader{
+		clickhouseRepo: clickhouseRepo,
+		timezones:      newSchoolTimezones(schoolRepo, config.TimezoneCacheTTL, logger),
+	}
+}
+
+// DateRange returns the range from startDate to endDate in the school's
+// timezone. endDate defaults to today there, and startDate to defaultRangeDays
+// before endDate.
+func (r *MetricsReader) DateRange(ctx context.Context, schoolID uuid.UUID, startDate, endDate string, granularity models.Granularity) (models.DateRange, error) {
+	timezone, err := r.timezones.timezone(ctx, schoolID)
+	if err != nil {
+		return models.DateRange{}, err
+	}
+
+	if endDate == "" {
+		loc, err := time.LoadLocation(timezone)
+		if err != nil {
+			loc = time.UTC
+		}
+		endDate = time.Now().In(loc).Format(time.DateOnly)
+	}
+	if startDate == "" {
+		if end, err := time.Parse(time.DateOnly, endDate); err == nil {
+			startDate = end.AddDate(0, 0, 1-defaultRangeDays).Format(time.DateOnly)
+		}
+	}
+	if granularity == "" {
+		granularity = models.GranularityDay
+	}
+
+	return models.DateRange{
+		StartDate:   startDate,
+		EndDate:     endDate,
+		Timezone:    timezone,
+		Granularity: granularity,
+	}, nil
+}
+
+func (r *MetricsReader) SchoolMetrics(ctx context.Context, schoolID uuid.UUID, dates models.DateRange, page models.Page) ([]models.SchoolMetric, error) {
+	return r.clickhouseRepo.GetSchoolMetricsByDateRange(ctx, schoolID.String(), dates, page)
+}
+
+// ClassroomMetrics returns the metrics of a classroom of the school.
+func (r *MetricsReader) ClassroomMetrics(ctx context.Context, schoolID, classroomID uuid.UUID, dates models.DateRange, page models.Page) ([]models.ClassroomMetric, error) {
+	return r.clickhouseRepo.GetClassroomMetricsByDateRange(ctx, schoolID.String(), classroomID.String(), dates, page)
+}
+
+// QuizMetrics returns the metrics of a quiz of the school.
+func (r *MetricsReader) QuizMetrics(ctx context.Context, schoolID, quizID uuid.UUID, dates models.DateRange, page models.Page) ([]models.QuizMetric, error) {
+	return r.clickhouseRepo.GetQuizMetricsByDateRange(ctx, schoolID.String(), quizID.String(), dates, page)
+}
+
+// UserActivity returns the activity of a user of the school.
+func (r *MetricsReader) UserActivity(ctx context.Context, schoolID, userID uuid.UUID, dates models.DateRange, page models.Page) ([]models.UserActivityMetric, error) {
+	return r.clickhouseRepo.GetUserActivityByDateRange(ctx, schoolID.String(), userID.String(), dates, page)
+}
@@ -0,0 +1,115 @@
+package analytics
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/lavish-gambhir/dashbeam/shared/streaming"
+)
+
+// releasedTTL is how long the timestamp of the last released event of a quiz
+// session is remembered to detect late events.
+const releasedTTL = time.Hour
+
+// reorderBuffer holds the events of each quiz session for a short window and
+// releases them in Event.Timestamp order, so that events of one session that
+// arrived out of order (across batches or devices) reach the EventProcessor
+// in the order they happened. Events that don't belong to a session pass
+// straight through.
+//
+// An event is held until window has passed since it arrived, and released
+// once every event of its session with an earlier timestamp is released. An
+// event older than one already released can no longer be put in order; it is
+// counted as late and passed on right away.
+//
+// The buffer orders events within one consumer. It is used by the batcher
+// goroutine only and is not safe for concurrent use.
+type reorderBuffer struct {
+	window   time.Duration
+	sessions map[uuid.UUID]*sessionEvents
+}
+
+type sessionEvents struct {
+	held         []heldEvent // sorted by event timestamp
+	lastReleased time.Time   // timestamp of the last released event
+	releasedAt   time.Time   // when an event was last released
+}
+
+type heldEvent struct {
+	event    streaming.Event
+	deadline time.Time
+}
+
+func newReorderBuffer(window time.Duration) *reorderBuffer {
+	return &reorderBuffer{
+		window:   window,
+		sessions: make(map[uuid.UUID]*sessionEvents),
+	}
+}
+
+// add takes an event that arrived at now and returns the events ready to be
+// processed: the event itself unless it is held.
+func (b *reorderBuffer) add(event streaming.Event, now time.Time) []streaming.Event {
+	sessionID, ok := event.QuizSessionID()
+	if !ok || b.window <= 0 {
+		return []streaming.Event{event}
+	}
+
+	s, ok := b.sessions[sessionID]
+	if !ok {
+		s = &sessionEvents{}
+		b.sessions[sessionID] = s
+	}
+	if event.Timestamp.Before(s.lastReleased) {
+		eventsLate.Add(1)
+		return []streaming.Event{event}
+	}
+
+	i := sort.Search(len(s.held), func(i int) bool {
+		return s.held[i].event.Timestamp.After(event.Timestamp)
+	})
+	if i < len(s.held) {
+		// An event that happened later has already arrived.
+		eventsReordered.Add(1)
+	}
+	s.held = append(s.held, heldEvent{})
+	copy(s.held[i+1:], s.held[i:])
+	s.held[i] = heldEvent{event: event, deadline: now.Add(b.window)}
+	return nil
+}
+
+// release returns the held events that are due at now, in timestamp order
+// per session.
+func (b *reorderBuffer) release(now time.Time) []streaming.Event {
+	var ready []streaming.Event
+	for sessionID, s := range b.sessions {
+		n := 0
+		for n < len(s.held) && !s.held[n].deadline.After(now) {
+			ready = append(ready, s.held[n].event)
+			n++
+		}
+		if n > 0 {
+			s.lastReleased = s.held[n-1].event.Timestamp
+			s.releasedAt = now
+			s.held = s.held[n:]
+		}
+		if len(s.held) == 0 && now.Sub(s.releasedAt) > releasedTTL {
+			delete(b.sessions, sessionID)
+		}
+	}
+	return ready
+}
+
+// releaseAll returns every held event, in timestamp order per session.
+func (b *reorderBuffer) releaseAll() []streaming.Event {
+	var ready []streaming.Event
+	for sessionID, s := range b.sessions {
+		for _, held := range s.held {
+			ready = append(ready, held.event)
+		}
+		delete(b.sessions, sessionID)
+	}
+	return ready
+}
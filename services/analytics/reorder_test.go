@@ -0,0 +1,192 @@
+package analytics
+
+import (
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/lavish-gambhir/dashbeam/shared/streaming"
+)
+
+// testEvent is an event of a quiz session, or of none when session is empty,
+// that happened at the given offset.
+type testEvent struct {
+	session string
+	at      time.Duration
+}
+
+// reorderStep adds an event, or releases the due events when event is empty,
+// at the given offset.
+type reorderStep struct {
+	at    time.Duration
+	event string
+	all   bool // release every held event
+	want  []string
+}
+
+func TestReorderBuffer(t *testing.T) {
+	const window = 10 * time.Second
+	tests := []struct {
+		name   string
+		window time.Duration
+		events map[string]testEvent
+		steps  []reorderStep
+	}{
+		{
+			name:   "in order",
+			events: map[string]testEvent{"a": {"s1", 1 * time.Second}, "b": {"s1", 2 * time.Second}},
+			steps: []reorderStep{
+				{at: 0, event: "a"},
+				{at: time.Second, event: "b"},
+				{at: 5 * time.Second},
+				{at: window, want: []string{"a"}},
+				{at: window + time.Second, want: []string{"b"}},
+			},
+		},
+		{
+			name:   "out of order",
+			events: map[string]testEvent{"a": {"s1", 1 * time.Second}, "b": {"s1", 2 * time.Second}},
+			steps: []reorderStep{
+				{at: 0, event: "b"},
+				{at: time.Second, event: "a"},
+				// b is due, but waits for a, which happened before it.
+				{at: window},
+				{at: window + time.Second, want: []string{"a", "b"}},
+			},
+		},
+		{
+			name:   "late",
+			events: map[string]testEvent{"a": {"s1", 5 * time.Second}, "b": {"s1", 1 * time.Second}, "c": {"s1", 5 * time.Second}},
+			steps: []reorderStep{
+				{at: 0, event: "a"},
+				{at: window, want: []string{"a"}},
+				{at: window + time.Second, event: "b", want: []string{"b"}},
+				// Not older than the last released event, so still held.
+				{at: window + time.Second, event: "c"},
+				{at: 2*window + time.Second, want: []string{"c"}},
+			},
+		},
+		{
+			name:   "released session forgotten",
+			events: map[string]testEvent{"a": {"s1", 5 * time.Second}, "b": {"s1", 1 * time.Second}},
+			steps: []reorderStep{
+				{at: 0, event: "a"},
+				{at: window, want: []string{"a"}},
+				{at: window + releasedTTL + time.Second},
+				{at: window + releasedTTL + time.Second, event: "b"},
+			},
+		},
+		{
+			name:   "without a session",
+			events: map[string]testEvent{"a": {"", 1 * time.Second}},
+			steps:  []reorderStep{{at: 0, event: "a", want: []string{"a"}}},
+		},
+		{
+			name:   "no window",
+			window: -1,
+			events: map[string]testEvent{"a": {"s1", 1 * time.Second}},
+			steps:  []reorderStep{{at: 0, event: "a", want: []string{"a"}}},
+		},
+		{
+			name: "sessions independent",
+			events: map[string]testEvent{
+				"a": {"s1", 2 * time.Second}, "b": {"s2", 1 * time.Second}, "c": {"s2", 3 * time.Second},
+			},
+			steps: []reorderStep{
+				{at: 0, event: "a"},
+				{at: time.Second, event: "c"},
+				{at: 5 * time.Second, event: "b"},
+				{at: window, want: []string{"a"}},
+				{at: window + 5*time.Second, want: []string{"b", "c"}},
+			},
+		},
+		{
+			name: "release all",
+			events: map[string]testEvent{
+				"a": {"s1", 2 * time.Second}, "b": {"s1", 1 * time.Second}, "c": {"s2", 1 * time.Second},
+			},
+			steps: []reorderStep{
+				{at: 0, event: "a"},
+				{at: 0, event: "b"},
+				{at: 0, event: "c"},
+				{at: time.Second, all: true, want: []string{"b", "a", "c"}},
+				{at: 2 * window},
+			},
+		},
+	}
+
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := tt.window
+			if w == 0 {
+				w = window
+			}
+			b := newReorderBuffer(w)
+
+			sessions := make(map[string]uuid.UUID)
+			events := make(map[string]streaming.Event)
+			labels := make(map[uuid.UUID]string)
+			for _, label := range slices.Sorted(maps.Keys(tt.events)) {
+				te := tt.events[label]
+				event := streaming.Event{ID: uuid.New(), Timestamp: start.Add(te.at)}
+				if te.session == "" {
+					event.Type, event.Payload = streaming.AppForeground, streaming.AppForegroundPayload{ScreenName: "home"}
+				} else {
+					if _, ok := sessions[te.session]; !ok {
+						sessions[te.session] = uuid.New()
+					}
+					event.Type = streaming.QuizQuestionShown
+					event.Payload = streaming.QuizQuestionShownPayload{
+						QuizID: uuid.New(), SessionID: sessions[te.session], QuestionID: uuid.New(), QuestionSequence: 1,
+					}
+				}
+				events[label], labels[event.ID] = event, label
+			}
+
+			for i, step := range tt.steps {
+				now := start.Add(step.at)
+				var got []streaming.Event
+				switch {
+				case step.all:
+					got = b.releaseAll()
+				case step.event != "":
+					got = b.add(events[step.event], now)
+				default:
+					got = b.release(now)
+				}
+				if !sameOrderPerSession(got, step.want, labels, tt.events) {
+					gotLabels := make([]string, len(got))
+					for j, event := range got {
+						gotLabels[j] = labels[event.ID]
+					}
+					t.Fatalf("step %d: got %v, want %v", i, gotLabels, step.want)
+				}
+			}
+		})
+	}
+}
+
+// sameOrderPerSession reports whether got are the events labelled want, in
+// the same order within each session. Sessions come out in any order.
+func sameOrderPerSession(got []streaming.Event, want []string, labels map[uuid.UUID]string, events map[string]testEvent) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	bySession := func(labelled []string) map[string][]string {
+		m := make(map[string][]string)
+		for _, label := range labelled {
+			session := events[label].session
+			m[session] = append(m[session], label)
+		}
+		return m
+	}
+	gotLabels := make([]string, len(got))
+	for i, event := range got {
+		gotLabels[i] = labels[event.ID]
+	}
+	return maps.EqualFunc(bySession(gotLabels), bySession(want), slices.Equal[[]string])
+}
@@ -2,6 +2,9 @@ package repository
 
 import (
 	"context"
+	"time"
+
+	"github.com/google/uuid"
 
 	"github.com/lavish-gambhir/dashbeam/shared/models"
 )
@@ -10,14 +13,12 @@ import (
 type ClickHouse interface {
 	// Event storage
 	InsertEvents(ctx context.Context, records []models.AnalyticsRecord) error
+	ExistingEventIDs(ctx context.Context, ids []string, schoolIDs []uuid.UUID, from, to time.Time) (map[string]struct{}, error)
 
-	// Metrics operations
-	UpsertUserActivityMetrics(ctx context.Context, metrics []models.UserActivityMetric) error
-	UpsertQuizMetrics(ctx context.Context, metrics []models.QuizMetric) error
-	UpsertSchoolMetrics(ctx context.Context, metrics []models.SchoolMetric) error
-
-	// Query operations for aggregations
-	GetUserActivityByDateRange(ctx context.Context, userID string, startDate, endDate string) ([]models.UserActivityMetric, error)
-	GetQuizMetricsByDateRange(ctx context.Context, quizID string, startDate, endDate string) ([]models.QuizMetric, error)
-	GetSchoolMetricsByDateRange(ctx context.Context, schoolID string, startDate, endDate string) ([]models.SchoolMetric, error)
+	// Metrics of a school, aggregated from stored events by ClickHouse, most
+	// recent period first
+	GetUserActivityByDateRange(ctx context.Context, schoolID, userID string, dates models.DateRange, page models.Page) ([]models.UserActivityMetric, error)
+	GetQuizMetricsByDateRange(ctx context.Context, schoolID, quizID string, dates models.DateRange, page models.Page) ([]models.QuizMetric, error)
+	GetClassroomMetricsByDateRange(ctx context.Context, schoolID, classroomID string, dates models.DateRange, page models.Page) ([]models.ClassroomMetric, error)
+	GetSchoolMetricsByDateRange(ctx context.Context, schoolID string, dates models.DateRange, page models.Page) ([]models.SchoolMetric, error)
 }
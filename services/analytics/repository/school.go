@@ -0,0 +1,12 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type School interface {
+	// Timezone returns the IANA timezone of the school
+	Timezone(ctx context.Context, schoolID uuid.UUID) (string, error)
+}
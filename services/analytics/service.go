@@ -2,24 +2,67 @@ package analytics
 
 import (
 	"context"
+	"errors"
 	"log/slog"
+	"sync"
 	"time"
 
+	"github.com/google/uuid"
+
 	"github.com/lavish-gambhir/dashbeam/shared/config"
 	"github.com/lavish-gambhir/dashbeam/shared/streaming"
 )
 
+// consumerGroup is the queue consumer group shared by all analytics workers,
+// so each event is processed by one of them and resumed after a restart.
+const consumerGroup = "analytics"
+
+const (
+	defaultBatchSize          = 1000
+	defaultProcessingInterval = 10 * time.Second
+	defaultReorderWindow      = 5 * time.Second
+	minReorderTick            = 100 * time.Millisecond
+	flushTimeout              = 30 * time.Second
+	shutdownFlushTimeout      = 30 * time.Second
+)
+
+var errStopping = errors.New("analytics service is stopping")
+
 type Service interface {
 	Start(ctx context.Context) error
 	Stop() error
 }
 
+// service consumes events through one long-lived subscription and hands them
+// to the EventProcessor in batches. A batch is flushed when it reaches
+// BatchSize or when ProcessingInterval elapses, whichever comes first.
+//
+// The subscription handler only returns once the batch holding its event has
+// been stored, so the queue acknowledges nothing that could still be lost; an
+// event of a batch that kept failing goes back to the queue as a handler
+// error, to be dead-lettered there. The queue hands over up to twice
+// BatchSize events at once so that one batch fills while the previous one is
+// flushed. While a flush is slow or retrying, the queue stops delivering.
+//
+// Events of a quiz session pass through a reorder buffer on their way into a
+// batch, so that they are processed in the order they happened.
 type service struct {
 	messageQueue streaming.MessageQueue
 	processor    *EventProcessor
 	logger       *slog.Logger
 	config       config.AnalyticsConfig
-	stopCh       chan struct{}
+
+	batchSize int
+	interval  time.Duration
+	buffer    chan pendingEvent
+	reorder   *reorderBuffer
+
+	mu       sync.RWMutex
+	stopped  bool
+	cancel   context.CancelFunc
+	stopCh   chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
 }
 
 func New(
@@ -28,55 +71,233 @@ func New(
 	config config.AnalyticsConfig,
 	logger *slog.Logger,
 ) Service {
+	batchSize := int(config.BatchSize)
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+	interval := config.ProcessingInterval
+	if interval <= 0 {
+		interval = defaultProcessingInterval
+	}
+	reorderWindow := config.ReorderWindow
+	if reorderWindow <= 0 {
+		reorderWindow = defaultReorderWindow
+	}
 	return &service{
 		messageQueue: messageQueue,
 		processor:    processor,
 		logger:       logger.With("service", "analytics"),
 		config:       config,
+		batchSize:    batchSize,
+		interval:     interval,
+		buffer:       make(chan pendingEvent, batchSize),
+		reorder:      newReorderBuffer(reorderWindow),
 		stopCh:       make(chan struct{}),
+		done:         make(chan struct{}),
 	}
 }
 
 func (s *service) Start(ctx context.Context) error {
-	s.logger.Info("starting analytics service")
+	s.logger.Info("starting analytics service", slog.Int("batch_size", s.batchSize), slog.Duration("interval", s.interval))
 
-	// Start consuming events from Redis streams
-	go s.consumeEvents(ctx)
+	subCtx, cancel := context.WithCancel(ctx)
+	s.mu.Lock()
+	s.cancel = cancel
+	s.mu.Unlock()
+
+	go s.runBatcher(ctx)
+
+	// Subscribe to all event topics using pattern matching
+	opts := streaming.DefaultSubscriberOptions()
+	opts.ConsumerGroup = consumerGroup
+	// Every handler holds its event until its batch is stored, so this is
+	// also how many events are worked on at once: one full batch being
+	// flushed and the next one filling. Any less and a batch could never
+	// fill while another is flushed; any more would only queue up behind
+	// the flush, since the buffer holds one batch. Throughput is thus at most
+	// 2*BatchSize events per flush.
+	opts.Concurrency = 2 * s.batchSize
+	// flush already retried the batch; its events go to the dead-letter
+	// queue rather than through another round of batching.
+	opts.MaxRetries = 0
+	// Entries stay pending while held. One read while all Concurrency slots
+	// are taken first waits for a handler to return, up to a hold time too.
+	opts.ClaimMinIdle = 2 * s.maxHoldTime()
+	s.messageQueue.Subscribe(subCtx, streaming.TopicPatternAll, func(event streaming.Event) error {
+		return s.enqueue(subCtx, event)
+	}, opts)
 	return nil
 }
 
+// maxHoldTime is the longest a subscription handler holds its event: in the
+// reorder buffer (at most about two windows, when earlier events of its
+// session keep arriving), until its batch is due, behind the flush of the
+// previous batch, and through its own batch's flush with every retry.
+func (s *service) maxHoldTime() time.Duration {
+	flush := time.Duration(s.config.MaxRetries+1) * flushTimeout
+	for attempt := 1; attempt <= int(s.config.MaxRetries); attempt++ {
+		flush += flushBackoff(attempt)
+	}
+	reorderTick := max(s.reorder.window/4, minReorderTick)
+	return 2*s.reorder.window + reorderTick + s.interval + 2*flush
+}
+
+// flushBackoff is the delay before the given retry of a flush.
+func flushBackoff(attempt int) time.Duration {
+	return time.Duration(attempt) * time.Second
+}
+
+// Stop stops intake, flushes whatever is buffered and waits for the flush to
+// finish, so a deploy doesn't drop events that were already handed over.
 func (s *service) Stop() error {
-	s.logger.Info("stopping analytics service")
-	close(s.stopCh)
+	s.stopOnce.Do(func() {
+		s.logger.Info("stopping analytics service")
+
+		// Waits for in-progress enqueues; later ones are refused and stay
+		// pending in the queue.
+		s.mu.Lock()
+		s.stopped = true
+		if s.cancel != nil {
+			s.cancel()
+		}
+		s.mu.Unlock()
+
+		close(s.stopCh)
+		<-s.done
+	})
 	return nil
 }
 
-func (s *service) processAvailableEvents(ctx context.Context) error {
-	// Subscribe to all event topics using pattern matching
-	s.messageQueue.Subscribe(ctx, "events.*", func(event streaming.Event) error {
-		events := []streaming.Event{event}
-		return s.processor.ProcessEvents(ctx, events)
-	}, nil)
+// pendingEvent is an event handed to the batcher, with the channel the
+// subscription handler waits on for the result of storing it.
+type pendingEvent struct {
+	event  streaming.Event
+	stored chan error
+}
 
-	return nil
+// enqueue hands event to the batcher and waits until it is stored.
+func (s *service) enqueue(ctx context.Context, event streaming.Event) error {
+	pending := pendingEvent{event: event, stored: make(chan error, 1)}
+	if err := s.handOver(ctx, pending); err != nil {
+		return err
+	}
+	// Every event handed over is flushed, on shutdown at the latest, so
+	// the result comes even after ctx is done.
+	return <-pending.stored
 }
 
-func (s *service) consumeEvents(ctx context.Context) {
-	ticker := time.NewTicker(s.config.ProcessingInterval)
+func (s *service) handOver(ctx context.Context, pending pendingEvent) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.stopped {
+		return errStopping
+	}
+	select {
+	case s.buffer <- pending:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *service) runBatcher(ctx context.Context) {
+	defer close(s.done)
+
+	// Flushes outlive ctx so the final one can run during shutdown.
+	flushCtx := context.WithoutCancel(ctx)
+	ticker := time.NewTicker(s.interval)
 	defer ticker.Stop()
 
+	reorderTick := max(s.reorder.window/4, minReorderTick)
+	reorderTicker := time.NewTicker(reorderTick)
+	defer reorderTicker.Stop()
+
+	// The handlers waiting for each event held in the reorder buffer or the
+	// batch; an event delivered twice has two.
+	waiting := make(map[uuid.UUID][]chan error)
+	receive := func(pending pendingEvent) []streaming.Event {
+		waiting[pending.event.ID] = append(waiting[pending.event.ID], pending.stored)
+		return s.reorder.add(pending.event, time.Now())
+	}
+	flush := func(ctx context.Context, events []streaming.Event) {
+		err := s.flush(ctx, events)
+		for _, event := range events {
+			for _, stored := range waiting[event.ID] {
+				stored <- err
+			}
+			delete(waiting, event.ID)
+		}
+	}
+
+	batch := make([]streaming.Event, 0, s.batchSize)
+	appendReady := func(events []streaming.Event) {
+		for _, event := range events {
+			batch = append(batch, event)
+			if len(batch) >= s.batchSize {
+				flush(flushCtx, batch)
+				batch = make([]streaming.Event, 0, s.batchSize)
+				ticker.Reset(s.interval)
+			}
+		}
+	}
 	for {
 		select {
-		case <-ctx.Done():
-			s.logger.Info("context cancelled, stopping event consumption")
-			return
+		case pending := <-s.buffer:
+			appendReady(receive(pending))
+		case <-reorderTicker.C:
+			appendReady(s.reorder.release(time.Now()))
+		case <-ticker.C:
+			if len(batch) > 0 {
+				flush(flushCtx, batch)
+				batch = make([]streaming.Event, 0, s.batchSize)
+			}
 		case <-s.stopCh:
-			s.logger.Info("stop signal received, stopping event consumption")
+		drain:
+			for {
+				select {
+				case pending := <-s.buffer:
+					batch = append(batch, receive(pending)...)
+				default:
+					break drain
+				}
+			}
+			batch = append(batch, s.reorder.releaseAll()...)
+			s.logger.Info("flushing buffered events before shutdown", slog.Int("count", len(batch)))
+			ctx, cancel := context.WithTimeout(flushCtx, shutdownFlushTimeout)
+			flush(ctx, batch)
+			cancel()
 			return
-		case <-ticker.C:
-			if err := s.processAvailableEvents(ctx); err != nil {
-				s.logger.Error("failed to process events", slog.Any("error", err))
+		}
+	}
+}
+
+// flush processes a batch, retrying up to MaxRetries times. The error of the
+// last attempt is returned to the handlers of its events, so that the queue
+// redelivers them rather than acknowledging them.
+func (s *service) flush(ctx context.Context, events []streaming.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	var err error
+retry:
+	for attempt := 0; attempt <= int(s.config.MaxRetries); attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				err = ctx.Err()
+				break retry
+			case <-time.After(flushBackoff(attempt)):
 			}
 		}
+		fctx, cancel := context.WithTimeout(ctx, flushTimeout)
+		err = s.processor.ProcessEvents(fctx, events)
+		cancel()
+		if err == nil {
+			return nil
+		}
+		s.logger.Warn("failed to flush events batch", slog.Int("attempt", attempt+1), slog.Int("count", len(events)), slog.Any("error", err))
 	}
+	s.logger.Error("failed to flush events batch after retries, returning it to the queue", slog.Int("count", len(events)), slog.Any("error", err))
+	return err
 }
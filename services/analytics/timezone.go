@@ -0,0 +1,73 @@
+package analytics
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/lavish-gambhir/dashbeam/services/analytics/repository"
+)
+
+const defaultTimezoneCacheTTL = 5 * time.Minute
+
+// schoolTimezones caches the timezone of each school. Metrics are stored per
+// UTC quarter hour and only grouped into days when read, so a school whose
+// timezone changed has all its metrics, past ones included, dated in the new
+// timezone once its entry expires.
+type schoolTimezones struct {
+	schools repository.School
+	ttl     time.Duration
+	logger  *slog.Logger
+
+	mu        sync.Mutex
+	timezones map[uuid.UUID]cachedTimezone
+}
+
+type cachedTimezone struct {
+	timezone string
+	expires  time.Time
+}
+
+func newSchoolTimezones(schools repository.School, ttl time.Duration, logger *slog.Logger) *schoolTimezones {
+	if ttl <= 0 {
+		ttl = defaultTimezoneCacheTTL
+	}
+	return &schoolTimezones{
+		schools:   schools,
+		ttl:       ttl,
+		logger:    logger,
+		timezones: make(map[uuid.UUID]cachedTimezone),
+	}
+}
+
+// timezone returns the timezone of the school. If it can't be looked up, an
+// expired entry is used if there is one.
+func (t *schoolTimezones) timezone(ctx context.Context, schoolID uuid.UUID) (string, error) {
+	now := time.Now()
+	t.mu.Lock()
+	cached, ok := t.timezones[schoolID]
+	t.mu.Unlock()
+	if ok && now.Before(cached.expires) {
+		return cached.timezone, nil
+	}
+
+	timezone, err := t.schools.Timezone(ctx, schoolID)
+	if err != nil {
+		if !ok {
+			return "", err
+		}
+		t.logger.Warn("failed to look up school timezone, using cached one", "school_id", schoolID.String(), "error", err)
+		return cached.timezone, nil
+	}
+	if ok && timezone != cached.timezone {
+		t.logger.Info("school timezone changed", "school_id", schoolID.String(), "from", cached.timezone, "to", timezone)
+	}
+
+	t.mu.Lock()
+	t.timezones[schoolID] = cachedTimezone{timezone: timezone, expires: now.Add(t.ttl)}
+	t.mu.Unlock()
+	return timezone, nil
+}
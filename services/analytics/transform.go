@@ -0,0 +1,432 @@
+package analytics
+
+import (
+	"github.com/lavish-gambhir/dashbeam/shared/models"
+	"github.com/lavish-gambhir/dashbeam/shared/streaming"
+)
+
+func init() {
+	streaming.RegisterAnalyticsTransformer(streaming.QuizSessionStarted, transformQuizStartEvent)
+	streaming.RegisterAnalyticsTransformer(streaming.QuizQuestionShown, transformQuizQuestionEvent)
+	streaming.RegisterAnalyticsTransformer(streaming.QuizAnswerSubmitted, transformQuizAnswerEvent)
+	streaming.RegisterAnalyticsTransformer(streaming.QuizSessionCompleted, transformQuizSubmitEvent)
+	streaming.RegisterAnalyticsTransformer(streaming.QuizSessionAbandoned, transformQuizAbandonEvent)
+	streaming.RegisterAnalyticsTransformer(streaming.QuizSessionPaused, transformQuizPauseEvent)
+	streaming.RegisterAnalyticsTransformer(streaming.QuizSessionResumed, transformQuizResumeEvent)
+
+	streaming.RegisterAnalyticsTransformer(streaming.UserLogin, transformUserLoginEvent)
+	streaming.RegisterAnalyticsTransformer(streaming.UserLogout, transformUserLogoutEvent)
+	streaming.RegisterAnalyticsTransformer(streaming.AppInteraction, transformAppInteractionEvent)
+	streaming.RegisterAnalyticsTransformer(streaming.AppNavigation, transformAppNavigationEvent)
+	streaming.RegisterAnalyticsTransformer(streaming.AppFocusChange, transformAppFocusChangeEvent)
+	streaming.RegisterAnalyticsTransformer(streaming.AppBackground, transformAppLifecycleEvent)
+	streaming.RegisterAnalyticsTransformer(streaming.AppForeground, transformAppLifecycleEvent)
+
+	streaming.RegisterAnalyticsTransformer(streaming.APIRequest, transformAPIEvent)
+	streaming.RegisterAnalyticsTransformer(streaming.APIResponse, transformAPIEvent)
+	streaming.RegisterAnalyticsTransformer(streaming.ErrorOccurred, transformErrorEvent)
+	streaming.RegisterAnalyticsTransformer(streaming.SystemStartup, transformSystemEvent)
+	streaming.RegisterAnalyticsTransformer(streaming.SystemShutdown, transformSystemEvent)
+}
+
+func transformUserLoginEvent(base models.AnalyticsRecord, event streaming.Event) (models.AnalyticsRecord, error) {
+	base.Category = "user_activity"
+	base.Action = "login"
+
+	if payload, ok := event.Payload.(streaming.UserLoginPayload); ok {
+		base.Metadata = map[string]any{
+			"login_method":  payload.LoginMethod,
+			"session_start": payload.SessionStart,
+		}
+		if payload.UserAgent != nil {
+			base.Metadata["user_agent"] = *payload.UserAgent
+		}
+		if payload.Email != nil {
+			base.Metadata["email"] = *payload.Email
+		}
+		if payload.Role != nil {
+			base.Metadata["role"] = *payload.Role
+		}
+	}
+
+	return base, nil
+}
+
+func transformUserLogoutEvent(base models.AnalyticsRecord, event streaming.Event) (models.AnalyticsRecord, error) {
+	base.Category = "user_activity"
+	base.Action = "logout"
+
+	if payload, ok := event.Payload.(streaming.UserLogoutPayload); ok {
+		base.Value = float64Ptr(float64(payload.SessionDuration))
+		base.Metadata = map[string]any{
+			"session_duration_ms": payload.SessionDuration,
+			"logout_reason":       payload.LogoutReason,
+		}
+	}
+
+	return base, nil
+}
+
+func transformQuizStartEvent(base models.AnalyticsRecord, event streaming.Event) (models.AnalyticsRecord, error) {
+	base.Category = "quiz_activity"
+	base.Action = "quiz_started"
+
+	if payload, ok := event.Payload.(streaming.QuizSessionStartedPayload); ok {
+		base.QuizID = &payload.QuizID
+		sessionID := payload.SessionID
+		base.SessionID = &sessionID
+		base.Value = float64Ptr(float64(payload.TotalQuestions))
+
+		base.Metadata = map[string]any{
+			"session_code":    payload.SessionCode,
+			"total_questions": payload.TotalQuestions,
+			"max_score":       payload.MaxScore,
+		}
+		if payload.TimeLimit != nil {
+			base.Metadata["time_limit_seconds"] = *payload.TimeLimit
+		}
+	}
+
+	return base, nil
+}
+
+func transformQuizSubmitEvent(base models.AnalyticsRecord, event streaming.Event) (models.AnalyticsRecord, error) {
+	base.Category = "quiz_activity"
+	base.Action = "quiz_completed"
+
+	if payload, ok := event.Payload.(streaming.QuizSessionCompletedPayload); ok {
+		base.QuizID = &payload.QuizID
+		sessionID := payload.SessionID
+		base.SessionID = &sessionID
+		base.Value = &payload.TotalScore
+
+		base.Metadata = map[string]any{
+			"total_score":           payload.TotalScore,
+			"max_score":             payload.MaxScore,
+			"completion_time_ms":    payload.CompletionTimeMS,
+			"questions_correct":     payload.QuestionsCorrect,
+			"questions_answered":    payload.QuestionsAnswered,
+			"questions_skipped":     payload.QuestionsSkipped,
+			"average_response_time": payload.AverageResponseTime,
+		}
+	}
+
+	return base, nil
+}
+
+func transformQuizAnswerEvent(base models.AnalyticsRecord, event streaming.Event) (models.AnalyticsRecord, error) {
+	base.Category = "quiz_activity"
+	base.Action = "answer_submitted"
+
+	if payload, ok := event.Payload.(streaming.QuizAnswerSubmittedPayload); ok {
+		base.QuizID = &payload.QuizID
+		base.QuestionID = &payload.QuestionID
+		sessionID := payload.SessionID
+		base.SessionID = &sessionID
+		base.Value = float64Ptr(float64(payload.ResponseTimeMS))
+
+		base.Metadata = map[string]any{
+			"question_sequence": payload.QuestionSequence,
+			"response_time_ms":  payload.ResponseTimeMS,
+		}
+
+		if payload.IsCorrect != nil {
+			base.Metadata["is_correct"] = *payload.IsCorrect
+		}
+		if payload.AnswerChanges != nil {
+			base.Metadata["answer_changes"] = *payload.AnswerChanges
+		}
+		if payload.Points != nil {
+			base.Metadata["points"] = *payload.Points
+		}
+	}
+
+	return base, nil
+}
+
+func transformQuizQuestionEvent(base models.AnalyticsRecord, event streaming.Event) (models.AnalyticsRecord, error) {
+	base.Category = "quiz_activity"
+	base.Action = "question_shown"
+
+	if payload, ok := event.Payload.(streaming.QuizQuestionShownPayload); ok {
+		base.QuizID = &payload.QuizID
+		base.QuestionID = &payload.QuestionID
+		sessionID := payload.SessionID
+		base.SessionID = &sessionID
+		base.Value = float64Ptr(float64(payload.QuestionSequence))
+
+		base.Metadata = map[string]any{
+			"question_sequence": payload.QuestionSequence,
+			"question_type":     payload.QuestionType,
+		}
+		if payload.TimeLimit != nil {
+			base.Metadata["time_limit_seconds"] = *payload.TimeLimit
+		}
+	}
+
+	return base, nil
+}
+
+func transformAppInteractionEvent(base models.AnalyticsRecord, event streaming.Event) (models.AnalyticsRecord, error) {
+	base.Category = "app_activity"
+	base.Action = "interaction"
+
+	if payload, ok := event.Payload.(streaming.AppInteractionPayload); ok {
+		base.Metadata = map[string]any{
+			"interaction_type": payload.InteractionType,
+			"screen_name":      payload.ScreenName,
+		}
+		if payload.ElementClicked != nil {
+			base.Metadata["element_clicked"] = *payload.ElementClicked
+		}
+		if payload.TimeSpentMS != nil {
+			base.Value = float64Ptr(float64(*payload.TimeSpentMS))
+			base.Metadata["time_spent_ms"] = *payload.TimeSpentMS
+		}
+		if payload.Action != nil {
+			base.Metadata["action"] = *payload.Action
+		}
+	}
+
+	return base, nil
+}
+
+func transformAppNavigationEvent(base models.AnalyticsRecord, event streaming.Event) (models.AnalyticsRecord, error) {
+	base.Category = "app_activity"
+	base.Action = "navigation"
+
+	if payload, ok := event.Payload.(streaming.AppNavigationPayload); ok {
+		base.Metadata = map[string]any{
+			"from_screen":     payload.FromScreen,
+			"to_screen":       payload.ToScreen,
+			"navigation_type": payload.NavigationType,
+		}
+		if payload.TimeSpentMS != nil {
+			base.Value = float64Ptr(float64(*payload.TimeSpentMS))
+			base.Metadata["time_spent_ms"] = *payload.TimeSpentMS
+		}
+	}
+
+	return base, nil
+}
+
+func transformQuizAbandonEvent(base models.AnalyticsRecord, event streaming.Event) (models.AnalyticsRecord, error) {
+	base.Category = "quiz_activity"
+	base.Action = "quiz_abandoned"
+
+	if payload, ok := event.Payload.(streaming.QuizSessionAbandonedPayload); ok {
+		base.QuizID = &payload.QuizID
+		sessionID := payload.SessionID
+		base.SessionID = &sessionID
+		base.Value = float64Ptr(float64(payload.QuestionsAnswered))
+
+		base.Metadata = map[string]any{
+			"questions_answered": payload.QuestionsAnswered,
+			"time_spent_ms":      payload.TimeSpentMS,
+			"reason":             payload.Reason,
+		}
+	}
+
+	return base, nil
+}
+
+func transformQuizPauseEvent(base models.AnalyticsRecord, event streaming.Event) (models.AnalyticsRecord, error) {
+	base.Category = "quiz_activity"
+	base.Action = "quiz_paused"
+
+	if payload, ok := event.Payload.(streaming.QuizSessionPausedPayload); ok {
+		base.QuizID = &payload.QuizID
+		sessionID := payload.SessionID
+		base.SessionID = &sessionID
+
+		base.Metadata = map[string]any{
+			"reason": payload.Reason,
+		}
+		if payload.QuestionSequence != nil {
+			base.Metadata["question_sequence"] = *payload.QuestionSequence
+		}
+	}
+
+	return base, nil
+}
+
+func transformQuizResumeEvent(base models.AnalyticsRecord, event streaming.Event) (models.AnalyticsRecord, error) {
+	base.Category = "quiz_activity"
+	base.Action = "quiz_resumed"
+
+	if payload, ok := event.Payload.(streaming.QuizSessionResumedPayload); ok {
+		base.QuizID = &payload.QuizID
+		sessionID := payload.SessionID
+		base.SessionID = &sessionID
+		base.Value = float64Ptr(float64(payload.PausedDurationMS))
+
+		base.Metadata = map[string]any{
+			"paused_duration_ms": payload.PausedDurationMS,
+		}
+		if payload.QuestionSequence != nil {
+			base.Metadata["question_sequence"] = *payload.QuestionSequence
+		}
+	}
+
+	return base, nil
+}
+
+func transformAppFocusChangeEvent(base models.AnalyticsRecord, event streaming.Event) (models.AnalyticsRecord, error) {
+	base.Category = "app_activity"
+	base.Action = "focus_lost"
+
+	if payload, ok := event.Payload.(streaming.AppFocusChangePayload); ok {
+		if payload.HasFocus {
+			base.Action = "focus_gained"
+		}
+		base.Metadata = map[string]any{
+			"has_focus":   payload.HasFocus,
+			"screen_name": payload.ScreenName,
+		}
+		if payload.QuizSessionID != nil {
+			sessionID := *payload.QuizSessionID
+			base.SessionID = &sessionID
+		}
+		if payload.DurationMS != nil {
+			base.Value = float64Ptr(float64(*payload.DurationMS))
+			base.Metadata["duration_ms"] = *payload.DurationMS
+		}
+	}
+
+	return base, nil
+}
+
+func transformAppLifecycleEvent(base models.AnalyticsRecord, event streaming.Event) (models.AnalyticsRecord, error) {
+	base.Category = "app_activity"
+
+	switch payload := event.Payload.(type) {
+	case streaming.AppBackgroundPayload:
+		base.Action = "background"
+		base.Metadata = map[string]any{
+			"screen_name": payload.ScreenName,
+		}
+		if payload.ForegroundDurationMS != nil {
+			base.Value = float64Ptr(float64(*payload.ForegroundDurationMS))
+			base.Metadata["foreground_duration_ms"] = *payload.ForegroundDurationMS
+		}
+	case streaming.AppForegroundPayload:
+		base.Action = "foreground"
+		base.Metadata = map[string]any{
+			"screen_name": payload.ScreenName,
+		}
+		if payload.BackgroundDurationMS != nil {
+			base.Value = float64Ptr(float64(*payload.BackgroundDurationMS))
+			base.Metadata["background_duration_ms"] = *payload.BackgroundDurationMS
+		}
+	}
+
+	return base, nil
+}
+
+func transformAPIEvent(base models.AnalyticsRecord, event streaming.Event) (models.AnalyticsRecord, error) {
+	base.Category = "system"
+
+	switch payload := event.Payload.(type) {
+	case streaming.APIRequestPayload:
+		base.Action = "api_request"
+		base.Metadata = map[string]any{
+			"method":   payload.Method,
+			"endpoint": payload.Endpoint,
+		}
+		if payload.StatusCode != nil {
+			base.Metadata["status_code"] = *payload.StatusCode
+		}
+		if payload.ResponseTime != nil {
+			base.Value = float64Ptr(float64(*payload.ResponseTime))
+			base.Metadata["response_time_ms"] = *payload.ResponseTime
+		}
+		if payload.ErrorCode != nil {
+			base.Metadata["error_code"] = *payload.ErrorCode
+		}
+		if payload.RequestSize != nil {
+			base.Metadata["request_size_bytes"] = *payload.RequestSize
+		}
+		if payload.ResponseSize != nil {
+			base.Metadata["response_size_bytes"] = *payload.ResponseSize
+		}
+	case streaming.APIResponsePayload:
+		base.Action = "api_response"
+		base.Metadata = map[string]any{
+			"method":      payload.Method,
+			"endpoint":    payload.Endpoint,
+			"status_code": payload.StatusCode,
+		}
+		if payload.ResponseTime != nil {
+			base.Value = float64Ptr(float64(*payload.ResponseTime))
+			base.Metadata["response_time_ms"] = *payload.ResponseTime
+		}
+		if payload.ErrorCode != nil {
+			base.Metadata["error_code"] = *payload.ErrorCode
+		}
+		if payload.ResponseSize != nil {
+			base.Metadata["response_size_bytes"] = *payload.ResponseSize
+		}
+	}
+
+	return base, nil
+}
+
+func transformErrorEvent(base models.AnalyticsRecord, event streaming.Event) (models.AnalyticsRecord, error) {
+	base.Category = "system"
+	base.Action = "error"
+
+	if payload, ok := event.Payload.(streaming.ErrorOccurredPayload); ok {
+		base.Metadata = map[string]any{
+			"error_type":    payload.ErrorType,
+			"error_message": payload.ErrorMessage,
+			"severity":      payload.Severity,
+		}
+		if payload.ErrorCode != nil {
+			base.Metadata["error_code"] = *payload.ErrorCode
+		}
+		if payload.Context != nil {
+			base.Metadata["context"] = *payload.Context
+		}
+		if payload.StackTrace != nil {
+			base.Metadata["stack_trace"] = *payload.StackTrace
+		}
+	}
+
+	return base, nil
+}
+
+func transformSystemEvent(base models.AnalyticsRecord, event streaming.Event) (models.AnalyticsRecord, error) {
+	base.Category = "system"
+
+	// Add basic metadata
+	base.Metadata = map[string]any{
+		"event_type": event.Type.String(),
+	}
+
+	switch payload := event.Payload.(type) {
+	case streaming.SystemStartupPayload:
+		base.Action = "startup"
+		base.Metadata["cold_start"] = payload.ColdStart
+		if payload.StartupTimeMS != nil {
+			base.Value = float64Ptr(float64(*payload.StartupTimeMS))
+			base.Metadata["startup_time_ms"] = *payload.StartupTimeMS
+		}
+		if payload.OSVersion != nil {
+			base.Metadata["os_version"] = *payload.OSVersion
+		}
+	case streaming.SystemShutdownPayload:
+		base.Action = "shutdown"
+		base.Value = float64Ptr(float64(payload.UptimeMS))
+		base.Metadata["reason"] = payload.Reason
+		base.Metadata["uptime_ms"] = payload.UptimeMS
+	default:
+		base.Action = "system_event"
+	}
+
+	return base, nil
+}
+
+// Helper function to create float64 pointer
+func float64Ptr(f float64) *float64 {
+	return &f
+}
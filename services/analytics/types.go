@@ -0,0 +1,25 @@
+package analytics
+
+import "github.com/lavish-gambhir/dashbeam/shared/models"
+
+const (
+	defaultSeriesLimit = 100
+	maxSeriesLimit     = 1000
+)
+
+// SeriesResponse is the data of every time series route: the metrics of each
+// period of the date range that has any, most recent first.
+type SeriesResponse[T any] struct {
+	StartDate   string             `json:"start_date"`
+	EndDate     string             `json:"end_date"`
+	Timezone    string             `json:"timezone"`
+	Granularity models.Granularity `json:"granularity"`
+	Series      []T                `json:"series"`
+	Pagination  Pagination         `json:"pagination"`
+}
+
+type Pagination struct {
+	Limit   int  `json:"limit"`
+	Offset  int  `json:"offset"`
+	HasMore bool `json:"has_more"`
+}
@@ -1,7 +1,13 @@
 package auth
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"strings"
@@ -20,17 +26,19 @@ import (
 )
 
 type handler struct {
-	dashboardRepo repository.DashboardRepository
-	authConfig    config.AuthConfig
-	logger        *slog.Logger
+	dashboardRepo    repository.DashboardRepository
+	refreshTokenRepo repository.RefreshTokenRepository
+	authConfig       config.AuthConfig
+	logger           *slog.Logger
 }
 
-func NewHandler(dashboardRepo repository.DashboardRepository, authConfig config.AuthConfig, logger *slog.Logger) *handler {
+func NewHandler(dashboardRepo repository.DashboardRepository, refreshTokenRepo repository.RefreshTokenRepository, authConfig config.AuthConfig, logger *slog.Logger) *handler {
 	log := logger.With("handler", "auth.handler")
 	return &handler{
-		dashboardRepo: dashboardRepo,
-		authConfig:    authConfig,
-		logger:        log,
+		dashboardRepo:    dashboardRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		authConfig:       authConfig,
+		logger:           log,
 	}
 }
 
@@ -137,6 +145,21 @@ func (h *handler) handleDashboardLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	refreshToken, refresh, err := h.newRefreshToken()
+	if err != nil {
+		logger.Error("failed to generate refresh token", slog.Any("error", err))
+		utils.WriteJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+	refresh.SessionID = sessionID
+	refresh.UserID = user.ID
+	refresh.ExpiresAt = time.Now().UTC().Add(h.authConfig.RefreshTokenExpiry)
+	if err := h.refreshTokenRepo.Create(ctx, refresh); err != nil {
+		logger.Error("failed to store refresh token", slog.Any("error", err))
+		utils.WriteJSONError(w, apperr.Wrap(err, apperr.Internal, "failed to generate refresh token"), http.StatusInternalServerError)
+		return
+	}
+
 	if err := h.dashboardRepo.UpdateLastLogin(ctx, user.ID.String()); err != nil {
 		logger.Warn("failed to update last login", slog.Any("error", err))
 		// Don't fail the login for this
@@ -145,20 +168,147 @@ func (h *handler) handleDashboardLogin(w http.ResponseWriter, r *http.Request) {
 	logger.Info("successful dashboard login", slog.String("username", user.Username))
 	w.Header().Set("Authorization", fmt.Sprintf("Bearer %s", accessToken))
 	utils.WriteJSONSuccess(w, DashboardLoginResponse{
-		Success:   true,
-		User:      user,
-		Session:   session,
+		Success:          true,
+		User:             user,
+		Session:          session,
+		ExpiresAt:        expiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: refresh.ExpiresAt,
+	})
+}
+
+// handleRefresh exchanges a refresh token for a new access token of the same
+// session, along with the refresh token replacing it. A refresh token can only
+// be used once; using one again revokes the session, since either it or its
+// replacement has leaked. Refreshing does not extend the session: it expires
+// refresh_token_expiry after login.
+func (h *handler) handleRefresh(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID, _ := sharedcontext.GetRequestID(ctx)
+	logger := h.logger.With("fn", "handleRefresh").With("requestID", reqID)
+
+	if r.Method != http.MethodPost {
+		utils.WriteJSONError(w, apperr.New(apperr.BadRequest, "method not allowed"), http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req RefreshRequest
+	if err := utils.FromJson(r.Body, &req); err != nil {
+		utils.WriteJSONError(w, apperr.Wrap(err, apperr.BadRequest, "invalid request body"), http.StatusBadRequest)
+		return
+	}
+	if req.RefreshToken == "" {
+		utils.WriteJSONError(w, apperr.New(apperr.BadRequest, "refresh token is required"), http.StatusBadRequest)
+		return
+	}
+
+	refreshToken, next, err := h.newRefreshToken()
+	if err != nil {
+		logger.Error("failed to generate refresh token", slog.Any("error", err))
+		utils.WriteJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+	used, err := h.refreshTokenRepo.Rotate(ctx, hashRefreshToken(req.RefreshToken), next)
+	if err != nil {
+		switch apperr.GetCode(err) {
+		case apperr.TokenReused:
+			logger.Warn("refresh token reused, session revoked", slog.Any("error", err))
+			utils.WriteJSONError(w, apperr.New(apperr.TokenReused, "refresh token was already used; session revoked"), http.StatusUnauthorized)
+		case apperr.DBRecordNotFound, apperr.InvalidToken, apperr.TokenExpired:
+			logger.Warn("refresh token rejected", slog.Any("error", err))
+			utils.WriteJSONError(w, apperr.New(apperr.InvalidToken, "invalid or expired refresh token"), http.StatusUnauthorized)
+		default:
+			logger.Error("failed to rotate refresh token", slog.Any("error", err))
+			utils.WriteJSONError(w, apperr.Wrap(err, apperr.Internal, "failed to refresh token"), http.StatusInternalServerError)
+		}
+		return
+	}
+	logger = logger.With("sessionID", used.SessionID.String())
+
+	user, err := h.dashboardRepo.GetUserByID(ctx, used.UserID.String())
+	if err != nil {
+		logger.Error("failed to get user by ID", slog.Any("error", err), slog.String("userID", used.UserID.String()))
+		utils.WriteJSONError(w, apperr.New(apperr.InvalidToken, "invalid or expired refresh token"), http.StatusUnauthorized)
+		return
+	}
+	if !user.IsActive {
+		logger.Warn("inactive user attempted refresh", slog.String("username", user.Username))
+		if err := h.refreshTokenRepo.RevokeSession(ctx, used.SessionID); err != nil {
+			logger.Error("failed to revoke session of inactive user", slog.Any("error", err))
+		}
+		utils.WriteJSONError(w, apperr.New(apperr.InvalidCredentials, "account is inactive"), http.StatusUnauthorized)
+		return
+	}
+
+	now := time.Now().UTC()
+	expiresAt := now.Add(h.authConfig.AccessTokenExpiry)
+	accessToken, err := h.generateDashboardJWT(user, used.SessionID, h.authConfig.AccessTokenExpiry)
+	if err != nil {
+		logger.Error("failed to generate access token", slog.Any("error", err))
+		utils.WriteJSONError(w, apperr.Wrap(err, apperr.Internal, "failed to generate access token"), http.StatusInternalServerError)
+		return
+	}
+
+	session := &models.DashboardSession{
+		ID:        used.SessionID,
+		UserID:    user.ID,
+		Username:  user.Username,
+		FullName:  user.FullName,
+		Email:     user.Email,
+		CreatedAt: now,
 		ExpiresAt: expiresAt,
+	}
+
+	logger.Info("refreshed dashboard session", slog.String("username", user.Username))
+	w.Header().Set("Authorization", fmt.Sprintf("Bearer %s", accessToken))
+	utils.WriteJSONSuccess(w, DashboardLoginResponse{
+		Success:          true,
+		User:             user,
+		Session:          session,
+		ExpiresAt:        expiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: next.ExpiresAt,
 	})
 }
 
+// handleDashboardLogout revokes the session of the refresh token in the body,
+// if any, and that of the access token in the Authorization header. The
+// access token may have expired; its session can still be refreshed.
 func (h *handler) handleDashboardLogout(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID, _ := sharedcontext.GetRequestID(ctx)
+	logger := h.logger.With("fn", "handleDashboardLogout").With("requestID", reqID)
+
 	if r.Method != http.MethodPost {
 		utils.WriteJSONError(w, apperr.New(apperr.BadRequest, "method not allowed"), http.StatusMethodNotAllowed)
 		return
 	}
 
+	var req LogoutRequest
+	if err := utils.FromJson(r.Body, &req); err != nil && !errors.Is(err, io.EOF) {
+		utils.WriteJSONError(w, apperr.Wrap(err, apperr.BadRequest, "invalid request body"), http.StatusBadRequest)
+		return
+	}
+
+	// The session can no longer be refreshed, but its access token stays
+	// valid until it expires.
 	// TODO: maintain a blacklist of invalidated tokens
+	if req.RefreshToken != "" {
+		if err := h.refreshTokenRepo.RevokeTokenSession(ctx, hashRefreshToken(req.RefreshToken)); err != nil {
+			logger.Error("failed to revoke refresh tokens", slog.Any("error", err))
+			utils.WriteJSONError(w, apperr.Wrap(err, apperr.Internal, "failed to log out"), http.StatusInternalServerError)
+			return
+		}
+	}
+	tokenString := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if claims, err := h.parseDashboardJWT(tokenString, jwt.WithoutClaimsValidation()); err == nil && claims.SessionID != uuid.Nil {
+		if err := h.refreshTokenRepo.RevokeSession(ctx, claims.SessionID); err != nil {
+			logger.Error("failed to revoke refresh tokens", slog.Any("error", err), slog.String("sessionID", claims.SessionID.String()))
+			utils.WriteJSONError(w, apperr.Wrap(err, apperr.Internal, "failed to log out"), http.StatusInternalServerError)
+			return
+		}
+	}
+
 	utils.WriteJSONSuccess(w, LogoutResponse{
 		Success: true,
 		Message: "logged out successfully",
@@ -256,9 +406,15 @@ func (h *handler) validateMobileJWT(tokenString string) (*models.UserContext, er
 }
 
 func (h *handler) validateDashboardJWT(tokenString string) (*dashboardClaims, error) {
+	return h.parseDashboardJWT(tokenString)
+}
+
+// parseDashboardJWT verifies the signature of a dashboard token and reads its
+// claims. Options such as jwt.WithoutClaimsValidation relax the other checks.
+func (h *handler) parseDashboardJWT(tokenString string, opts ...jwt.ParserOption) (*dashboardClaims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, jwt.MapClaims{}, func(token *jwt.Token) (interface{}, error) {
 		return []byte(h.authConfig.JWTSecretKey), nil
-	})
+	}, opts...)
 
 	if err != nil {
 		return nil, apperr.Wrap(err, apperr.InvalidToken, "failed to parse token")
@@ -293,6 +449,10 @@ func (h *handler) validateDashboardJWT(tokenString string) (*dashboardClaims, er
 		claims.Email = email
 	}
 
+	if role, ok := mapClaims["role"].(string); ok {
+		claims.Role = role
+	}
+
 	if sessionID, ok := mapClaims["session_id"].(string); ok {
 		if parsed, err := uuid.Parse(sessionID); err == nil {
 			claims.SessionID = parsed
@@ -330,6 +490,7 @@ func (h *handler) generateDashboardJWT(user *models.DashboardUser, sessionID uui
 		"username":   user.Username,
 		"full_name":  user.FullName,
 		"email":      user.Email,
+		"role":       user.Role,
 		"session_id": sessionID.String(),
 		"iat":        now.Unix(),
 		"exp":        now.Add(expiry).Unix(),
@@ -341,3 +502,22 @@ func (h *handler) generateDashboardJWT(user *models.DashboardUser, sessionID uui
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString([]byte(h.authConfig.JWTSecretKey))
 }
+
+// newRefreshToken returns a random opaque refresh token and its record,
+// without a session, user or expiry. Only the hash of the token is stored.
+func (h *handler) newRefreshToken() (string, *models.RefreshToken, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, apperr.Wrap(err, apperr.Internal, "failed to generate refresh token")
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+	return token, &models.RefreshToken{
+		ID:        uuid.New(),
+		TokenHash: hashRefreshToken(token),
+	}, nil
+}
+
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,254 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/lavish-gambhir/dashbeam/pkg/apperr"
+	"github.com/lavish-gambhir/dashbeam/shared/config"
+	"github.com/lavish-gambhir/dashbeam/shared/models"
+)
+
+// fakeDashboardUsers holds dashboard users in memory.
+type fakeDashboardUsers struct {
+	users map[uuid.UUID]*models.DashboardUser
+}
+
+func (r *fakeDashboardUsers) GetUserByUsername(context.Context, string) (*models.DashboardUser, error) {
+	return nil, apperr.New(apperr.DBRecordNotFound, "user not found")
+}
+
+func (r *fakeDashboardUsers) GetUserByID(_ context.Context, userID string) (*models.DashboardUser, error) {
+	for id, user := range r.users {
+		if id.String() == userID {
+			return user, nil
+		}
+	}
+	return nil, apperr.New(apperr.DBRecordNotFound, "user not found")
+}
+
+func (r *fakeDashboardUsers) UpdateLastLogin(context.Context, string) error { return nil }
+
+func (r *fakeDashboardUsers) CreateUser(context.Context, *models.DashboardUser) error { return nil }
+
+// fakeRefreshTokens rotates the token it holds, or fails with err, and
+// records revocations.
+type fakeRefreshTokens struct {
+	token           models.RefreshToken
+	err             error
+	next            *models.RefreshToken
+	revokedSessions []uuid.UUID
+	revokedHashes   []string
+}
+
+func (r *fakeRefreshTokens) Create(context.Context, *models.RefreshToken) error { return nil }
+
+func (r *fakeRefreshTokens) Rotate(_ context.Context, tokenHash string, next *models.RefreshToken) (*models.RefreshToken, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	if tokenHash != r.token.TokenHash {
+		return nil, apperr.New(apperr.DBRecordNotFound, "refresh token not found")
+	}
+	next.SessionID, next.UserID, next.ExpiresAt = r.token.SessionID, r.token.UserID, r.token.ExpiresAt
+	r.next = next
+	used := r.token
+	return &used, nil
+}
+
+func (r *fakeRefreshTokens) RevokeSession(_ context.Context, sessionID uuid.UUID) error {
+	r.revokedSessions = append(r.revokedSessions, sessionID)
+	return nil
+}
+
+func (r *fakeRefreshTokens) RevokeTokenSession(_ context.Context, tokenHash string) error {
+	r.revokedHashes = append(r.revokedHashes, tokenHash)
+	return nil
+}
+
+func newTestHandler(users *fakeDashboardUsers, tokens *fakeRefreshTokens) *handler {
+	cfg := config.AuthConfig{JWTSecretKey: "test-secret", AccessTokenExpiry: 15 * time.Minute, RefreshTokenExpiry: 30 * 24 * time.Hour}
+	return NewHandler(users, tokens, cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+// decodeResponse decodes the data of a success response into data, or
+// returns the code of an error response.
+func decodeResponse(t *testing.T, rec *httptest.ResponseRecorder, data any) string {
+	t.Helper()
+	var body struct {
+		Data json.RawMessage `json:"data"`
+		Code string          `json:"code"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if body.Code == "" && data != nil {
+		if err := json.Unmarshal(body.Data, data); err != nil {
+			t.Fatalf("decoding response data: %v", err)
+		}
+	}
+	return body.Code
+}
+
+func TestHandleRefresh(t *testing.T) {
+	const presented = "presented-token"
+	sessionExpiry := time.Now().UTC().Add(20 * 24 * time.Hour).Truncate(time.Second)
+	active := &models.DashboardUser{ID: uuid.New(), Username: "ada", IsActive: true}
+	inactive := &models.DashboardUser{ID: uuid.New(), Username: "grace"}
+
+	tests := []struct {
+		name         string
+		body         string
+		user         *models.DashboardUser
+		err          error
+		wantStatus   int
+		wantCode     apperr.ErrCode
+		wantRevoked  bool
+		wantRotation bool
+	}{
+		{name: "rotated", user: active, wantStatus: http.StatusOK, wantRotation: true},
+		{name: "unknown token", body: `{"refresh_token":"other-token"}`, user: active, wantStatus: http.StatusUnauthorized, wantCode: apperr.InvalidToken},
+		{
+			name: "reused", user: active, err: apperr.New(apperr.TokenReused, "refresh token was already used"),
+			wantStatus: http.StatusUnauthorized, wantCode: apperr.TokenReused,
+		},
+		{
+			name: "revoked", user: active, err: apperr.New(apperr.InvalidToken, "refresh token is revoked"),
+			wantStatus: http.StatusUnauthorized, wantCode: apperr.InvalidToken,
+		},
+		{
+			name: "expired", user: active, err: apperr.New(apperr.TokenExpired, "refresh token has expired"),
+			wantStatus: http.StatusUnauthorized, wantCode: apperr.InvalidToken,
+		},
+		{
+			name: "database down", user: active, err: apperr.New(apperr.DBQueryFailed, "connection refused"),
+			wantStatus: http.StatusInternalServerError, wantCode: apperr.Internal,
+		},
+		{name: "inactive user", user: inactive, wantStatus: http.StatusUnauthorized, wantCode: apperr.InvalidCredentials, wantRevoked: true},
+		{name: "no token", body: `{}`, user: active, wantStatus: http.StatusBadRequest, wantCode: apperr.BadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sessionID := uuid.New()
+			tokens := &fakeRefreshTokens{
+				token: models.RefreshToken{
+					ID: uuid.New(), TokenHash: hashRefreshToken(presented),
+					SessionID: sessionID, UserID: tt.user.ID, ExpiresAt: sessionExpiry,
+				},
+				err: tt.err,
+			}
+			users := &fakeDashboardUsers{users: map[uuid.UUID]*models.DashboardUser{tt.user.ID: tt.user}}
+			h := newTestHandler(users, tokens)
+
+			body := tt.body
+			if body == "" {
+				body = `{"refresh_token":"` + presented + `"}`
+			}
+			rec := httptest.NewRecorder()
+			h.handleRefresh(rec, httptest.NewRequest(http.MethodPost, "/auth/refresh", strings.NewReader(body)))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			var resp DashboardLoginResponse
+			if code := decodeResponse(t, rec, &resp); code != string(tt.wantCode) {
+				t.Errorf("code = %q, want %q", code, tt.wantCode)
+			}
+			if revoked := slices.Contains(tokens.revokedSessions, sessionID); revoked != tt.wantRevoked {
+				t.Errorf("session revoked = %v, want %v", revoked, tt.wantRevoked)
+			}
+			if !tt.wantRotation {
+				return
+			}
+
+			// The replacement is returned, and the session keeps its expiry.
+			if tokens.next == nil || hashRefreshToken(resp.RefreshToken) != tokens.next.TokenHash {
+				t.Errorf("returned refresh token is not the stored replacement")
+			}
+			if resp.RefreshToken == presented {
+				t.Errorf("refresh token not rotated")
+			}
+			if !resp.RefreshExpiresAt.Equal(sessionExpiry) {
+				t.Errorf("refresh expires at %v, want the session's %v", resp.RefreshExpiresAt, sessionExpiry)
+			}
+			accessToken := strings.TrimPrefix(rec.Header().Get("Authorization"), "Bearer ")
+			claims, err := h.parseDashboardJWT(accessToken)
+			if err != nil {
+				t.Fatalf("access token: %v", err)
+			}
+			if claims.SessionID != sessionID || resp.Session == nil || resp.Session.ID != sessionID {
+				t.Errorf("refreshed session %s, want %s", claims.SessionID, sessionID)
+			}
+		})
+	}
+}
+
+func TestHandleDashboardLogout(t *testing.T) {
+	user := &models.DashboardUser{ID: uuid.New(), Username: "ada", IsActive: true}
+	tests := []struct {
+		name               string
+		refreshToken       string
+		accessExpiry       time.Duration // no access token when zero
+		badSignature       bool
+		wantHashes         []string
+		wantSessionRevoked bool
+	}{
+		{name: "nothing to revoke"},
+		{name: "refresh token", refreshToken: "refresh", wantHashes: []string{hashRefreshToken("refresh")}},
+		{name: "access token", accessExpiry: time.Minute, wantSessionRevoked: true},
+		{name: "expired access token", accessExpiry: -time.Minute, wantSessionRevoked: true},
+		{name: "forged access token", accessExpiry: time.Minute, badSignature: true},
+		{
+			name: "both", refreshToken: "refresh", accessExpiry: -time.Minute,
+			wantHashes: []string{hashRefreshToken("refresh")}, wantSessionRevoked: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens := &fakeRefreshTokens{}
+			h := newTestHandler(&fakeDashboardUsers{}, tokens)
+			sessionID := uuid.New()
+
+			body := ""
+			if tt.refreshToken != "" {
+				body = `{"refresh_token":"` + tt.refreshToken + `"}`
+			}
+			req := httptest.NewRequest(http.MethodPost, "/auth/logout", strings.NewReader(body))
+			if tt.accessExpiry != 0 {
+				signer := h
+				if tt.badSignature {
+					signer = newTestHandler(&fakeDashboardUsers{}, tokens)
+					signer.authConfig.JWTSecretKey = "other-secret"
+				}
+				accessToken, err := signer.generateDashboardJWT(user, sessionID, tt.accessExpiry)
+				if err != nil {
+					t.Fatal(err)
+				}
+				req.Header.Set("Authorization", "Bearer "+accessToken)
+			}
+
+			rec := httptest.NewRecorder()
+			h.handleDashboardLogout(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+			}
+			if !slices.Equal(tokens.revokedHashes, tt.wantHashes) {
+				t.Errorf("revoked sessions of tokens %v, want %v", tokens.revokedHashes, tt.wantHashes)
+			}
+			if revoked := slices.Contains(tokens.revokedSessions, sessionID); revoked != tt.wantSessionRevoked {
+				t.Errorf("session revoked = %v, want %v", revoked, tt.wantSessionRevoked)
+			}
+		})
+	}
+}
@@ -3,6 +3,8 @@ package repository
 import (
 	"context"
 
+	"github.com/google/uuid"
+
 	"github.com/lavish-gambhir/dashbeam/shared/models"
 )
 
@@ -11,9 +13,29 @@ type DashboardRepository interface {
 	// GetUserByUsername retrieves a dashboard user by username
 	GetUserByUsername(ctx context.Context, username string) (*models.DashboardUser, error)
 
+	// GetUserByID retrieves a dashboard user by ID
+	GetUserByID(ctx context.Context, userID string) (*models.DashboardUser, error)
+
 	// UpdateLastLogin updates the user's last login timestamp
 	UpdateLastLogin(ctx context.Context, userID string) error
 
 	// CreateUser creates a new dashboard user (for initial setup)
 	CreateUser(ctx context.Context, user *models.DashboardUser) error
 }
+
+// RefreshTokenRepository stores the refresh tokens of dashboard sessions
+type RefreshTokenRepository interface {
+	// Create stores a new refresh token
+	Create(ctx context.Context, token *models.RefreshToken) error
+
+	// Rotate uses up the token with tokenHash and stores next in its place,
+	// revoking the whole session if the token was already used
+	Rotate(ctx context.Context, tokenHash string, next *models.RefreshToken) (*models.RefreshToken, error)
+
+	// RevokeSession revokes every refresh token of the session
+	RevokeSession(ctx context.Context, sessionID uuid.UUID) error
+
+	// RevokeTokenSession revokes every refresh token of the session the
+	// token with tokenHash belongs to
+	RevokeTokenSession(ctx context.Context, tokenHash string) error
+}
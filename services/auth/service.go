@@ -13,29 +13,33 @@ type Service interface {
 }
 
 type service struct {
-	dashboardRepo repository.DashboardRepository
-	authConfig    config.AuthConfig
-	logger        *slog.Logger
+	dashboardRepo    repository.DashboardRepository
+	refreshTokenRepo repository.RefreshTokenRepository
+	authConfig       config.AuthConfig
+	logger           *slog.Logger
 }
 
-func New(dashboardRepo repository.DashboardRepository, authConfig config.AuthConfig, logger *slog.Logger) Service {
+func New(dashboardRepo repository.DashboardRepository, refreshTokenRepo repository.RefreshTokenRepository, authConfig config.AuthConfig, logger *slog.Logger) Service {
 	return &service{
-		dashboardRepo: dashboardRepo,
-		authConfig:    authConfig,
-		logger:        logger,
+		dashboardRepo:    dashboardRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		authConfig:       authConfig,
+		logger:           logger,
 	}
 }
 
 func (s *service) RegisterRoutes(parentmux *http.ServeMux, prefix string) {
 	h := &handler{
-		dashboardRepo: s.dashboardRepo,
-		authConfig:    s.authConfig,
-		logger:        s.logger,
+		dashboardRepo:    s.dashboardRepo,
+		refreshTokenRepo: s.refreshTokenRepo,
+		authConfig:       s.authConfig,
+		logger:           s.logger,
 	}
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/validate", h.handleValidateJWT)
 	mux.HandleFunc("/login", h.handleDashboardLogin)
+	mux.HandleFunc("/refresh", h.handleRefresh)
 	mux.HandleFunc("/logout", h.handleDashboardLogout)
 	mux.HandleFunc("/me", h.handleGetCurrentUser)
 	parentmux.Handle(prefix+"/", http.StripPrefix(prefix, mux))
@@ -23,11 +23,17 @@ type DashboardLoginRequest struct {
 }
 
 type DashboardLoginResponse struct {
-	Success   bool                     `json:"success"`
-	User      *models.DashboardUser    `json:"user,omitempty"`
-	Session   *models.DashboardSession `json:"session,omitempty"`
-	ExpiresAt time.Time                `json:"expires_at,omitempty"`
-	Error     string                   `json:"error,omitempty"`
+	Success          bool                     `json:"success"`
+	User             *models.DashboardUser    `json:"user,omitempty"`
+	Session          *models.DashboardSession `json:"session,omitempty"`
+	ExpiresAt        time.Time                `json:"expires_at,omitempty"`
+	RefreshToken     string                   `json:"refresh_token,omitempty"`
+	RefreshExpiresAt time.Time                `json:"refresh_expires_at,omitempty"`
+	Error            string                   `json:"error,omitempty"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
 }
 
 type CurrentUserResponse struct {
@@ -36,6 +42,10 @@ type CurrentUserResponse struct {
 	Error   string                   `json:"error,omitempty"`
 }
 
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
 type LogoutResponse struct {
 	Success bool   `json:"success"`
 	Message string `json:"message,omitempty"`
@@ -47,6 +57,7 @@ type dashboardClaims struct {
 	Username  string    `json:"username"`
 	FullName  string    `json:"full_name"`
 	Email     string    `json:"email"`
+	Role      string    `json:"role"`
 	SessionID uuid.UUID `json:"session_id"`
 	IssuedAt  time.Time `json:"iat"`
 	ExpiresAt time.Time `json:"exp"`
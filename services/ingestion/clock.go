@@ -0,0 +1,117 @@
+package ingestion
+
+import (
+	"sync"
+	"time"
+
+	"github.com/lavish-gambhir/dashbeam/shared/streaming"
+)
+
+const (
+	// skewTolerance is the skew below which timestamps are left alone; it
+	// covers network and queueing delay between the device and the server.
+	skewTolerance = 2 * time.Second
+
+	// deviceSkewTTL is how long a device's last measured skew is used for
+	// its events that carry no client_time.
+	deviceSkewTTL = time.Hour
+
+	maxTrackedDevices = 100000
+)
+
+// clockCorrector corrects event timestamps for device clock skew. The skew
+// of a device is estimated from Metadata.ClientTime, the device clock when it
+// sent the event, and the time the server received it. As events can be sent
+// long after they happened, the skew is independent of how old an event is.
+//
+// The last skew measured per device is kept in memory, per instance, for
+// events sent without a client_time.
+type clockCorrector struct {
+	maxSkew time.Duration
+
+	mu      sync.Mutex
+	devices map[string]deviceSkew
+}
+
+type deviceSkew struct {
+	skew       time.Duration
+	measuredAt time.Time
+}
+
+func newClockCorrector(maxSkew time.Duration) *clockCorrector {
+	return &clockCorrector{
+		maxSkew: maxSkew,
+		devices: make(map[string]deviceSkew),
+	}
+}
+
+// correct shifts event.Timestamp by the estimated skew of the device that
+// sent it, received at receivedAt, keeping the original in ClientTimestamp.
+// Events with a skew above the configured maximum are flagged as suspect.
+// Skew fields sent by the client are discarded.
+func (c *clockCorrector) correct(event *streaming.Event, receivedAt time.Time) {
+	event.ClientTimestamp = nil
+	event.ClockSkewMS = 0
+	event.ClockSkewSuspect = false
+	if event.Timestamp.IsZero() {
+		// Defaulted to the server time later on.
+		return
+	}
+
+	skew, ok := c.estimate(event, receivedAt)
+	if !ok || skew.Abs() < skewTolerance {
+		return
+	}
+
+	clientTimestamp := event.Timestamp
+	event.ClientTimestamp = &clientTimestamp
+	event.Timestamp = clientTimestamp.Add(skew)
+	event.ClockSkewMS = skew.Milliseconds()
+	if skew.Abs() > c.maxSkew {
+		event.ClockSkewSuspect = true
+		eventsSkewSuspect.Add(1)
+	}
+	eventsSkewCorrected.Add(1)
+}
+
+// estimate returns the skew of the event's device, measured from the event
+// when it has a client_time, or else the device's last measurement.
+func (c *clockCorrector) estimate(event *streaming.Event, receivedAt time.Time) (time.Duration, bool) {
+	deviceID := event.Metadata.DeviceID
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if event.Metadata.ClientTime != nil {
+		clientTime, err := time.Parse(time.RFC3339Nano, *event.Metadata.ClientTime)
+		if err == nil {
+			skew := receivedAt.Sub(clientTime)
+			c.remember(deviceID, deviceSkew{skew: skew, measuredAt: receivedAt})
+			return skew, true
+		}
+	}
+
+	last, ok := c.devices[deviceID]
+	if !ok || receivedAt.Sub(last.measuredAt) > deviceSkewTTL {
+		return 0, false
+	}
+	return last.skew, true
+}
+
+func (c *clockCorrector) remember(deviceID string, skew deviceSkew) {
+	if deviceID == "" {
+		return
+	}
+	if _, ok := c.devices[deviceID]; !ok && len(c.devices) >= maxTrackedDevices {
+		for id, d := range c.devices {
+			if skew.measuredAt.Sub(d.measuredAt) > deviceSkewTTL {
+				delete(c.devices, id)
+			}
+		}
+		if len(c.devices) >= maxTrackedDevices {
+			// All recent; start over rather than grow without bound.
+			clear(c.devices)
+		}
+	}
+	c.devices[deviceID] = skew
+}
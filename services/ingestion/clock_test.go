@@ -0,0 +1,84 @@
+package ingestion
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lavish-gambhir/dashbeam/shared/streaming"
+)
+
+func TestClockCorrector(t *testing.T) {
+	received := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	happened := received.Add(-10 * time.Minute)
+	clientTime := func(skew time.Duration) *string {
+		s := received.Add(-skew).Format(time.RFC3339Nano)
+		return &s
+	}
+
+	tests := []struct {
+		name string
+		// previous is the skew measured from an earlier event of the device,
+		// measuredAgo before this one was received.
+		previous    *time.Duration
+		measuredAgo time.Duration
+		clientTime  *string
+		timestamp   time.Time
+		wantSkew    time.Duration
+		wantSuspect bool
+	}{
+		{name: "in sync", clientTime: clientTime(0), timestamp: happened},
+		{name: "within tolerance", clientTime: clientTime(time.Second), timestamp: happened},
+		{name: "device behind", clientTime: clientTime(time.Hour), timestamp: happened, wantSkew: time.Hour},
+		{name: "device ahead", clientTime: clientTime(-30 * time.Minute), timestamp: happened, wantSkew: -30 * time.Minute},
+		{name: "beyond the maximum", clientTime: clientTime(48 * time.Hour), timestamp: happened, wantSkew: 48 * time.Hour, wantSuspect: true},
+		{name: "no client time or measurement", timestamp: happened},
+		{name: "device's last measurement", previous: ptr(time.Hour), measuredAgo: time.Minute, timestamp: happened, wantSkew: time.Hour},
+		{name: "stale measurement", previous: ptr(time.Hour), measuredAgo: 2 * deviceSkewTTL, timestamp: happened},
+		{
+			name: "client time over the last measurement", previous: ptr(time.Hour), measuredAgo: time.Minute,
+			clientTime: clientTime(5 * time.Minute), timestamp: happened, wantSkew: 5 * time.Minute,
+		},
+		{
+			name: "invalid client time", previous: ptr(time.Hour), measuredAgo: time.Minute,
+			clientTime: ptr("yesterday"), timestamp: happened, wantSkew: time.Hour,
+		},
+		{name: "no timestamp", clientTime: clientTime(time.Hour)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newClockCorrector(24 * time.Hour)
+			if tt.previous != nil {
+				earlier := newTestEvent(streaming.AppForeground, streaming.AppForegroundPayload{ScreenName: "home"})
+				measuredAt := received.Add(-tt.measuredAgo)
+				earlier.Metadata.ClientTime = ptr(measuredAt.Add(-*tt.previous).Format(time.RFC3339Nano))
+				c.correct(&earlier, measuredAt)
+			}
+
+			event := newTestEvent(streaming.AppForeground, streaming.AppForegroundPayload{ScreenName: "home"})
+			event.Timestamp = tt.timestamp
+			event.Metadata.ClientTime = tt.clientTime
+			// Skew fields sent by the client are discarded.
+			forged := time.Unix(0, 0)
+			event.ClientTimestamp, event.ClockSkewMS, event.ClockSkewSuspect = &forged, 1234, true
+
+			c.correct(&event, received)
+
+			if event.ClockSkewMS != tt.wantSkew.Milliseconds() || event.ClockSkewSuspect != tt.wantSuspect {
+				t.Errorf("skew = %dms, suspect = %v, want %dms, %v",
+					event.ClockSkewMS, event.ClockSkewSuspect, tt.wantSkew.Milliseconds(), tt.wantSuspect)
+			}
+			if tt.wantSkew == 0 {
+				if event.ClientTimestamp != nil || !event.Timestamp.Equal(tt.timestamp) {
+					t.Errorf("timestamp = %v (client %v), want %v left alone", event.Timestamp, event.ClientTimestamp, tt.timestamp)
+				}
+				return
+			}
+			if event.ClientTimestamp == nil || !event.ClientTimestamp.Equal(tt.timestamp) {
+				t.Errorf("client timestamp = %v, want %v", event.ClientTimestamp, tt.timestamp)
+			}
+			if want := tt.timestamp.Add(tt.wantSkew); !event.Timestamp.Equal(want) {
+				t.Errorf("timestamp = %v, want %v", event.Timestamp, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package ingestion
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+
+	"github.com/lavish-gambhir/dashbeam/shared/config"
+	sharedcontext "github.com/lavish-gambhir/dashbeam/shared/context"
+	"github.com/lavish-gambhir/dashbeam/shared/models"
+	"github.com/lavish-gambhir/dashbeam/shared/streaming"
+)
+
+// Enricher fills in server-side fields of an event before it is accepted,
+// overriding whatever the client sent for them. Enrichers run after the
+// event was validated and its identity checked against the token.
+type Enricher interface {
+	Enrich(ctx context.Context, req RequestInfo, event *streaming.Event) error
+}
+
+// EnricherFunc adapts a function to Enricher.
+type EnricherFunc func(ctx context.Context, req RequestInfo, event *streaming.Event) error
+
+func (f EnricherFunc) Enrich(ctx context.Context, req RequestInfo, event *streaming.Event) error {
+	return f(ctx, req, event)
+}
+
+// EnricherFactory builds an enricher from the ingestion config.
+type EnricherFactory func(cfg config.IngestionConfig) (Enricher, error)
+
+// RequestInfo is what enrichers know about the request that carried an event.
+type RequestInfo struct {
+	RemoteAddr string
+	Header     http.Header
+	RequestID  string
+	TraceID    string
+	User       *models.UserContext // nil if the request is not authenticated
+}
+
+var enrichers = struct {
+	sync.RWMutex
+	factories map[string]EnricherFactory
+}{factories: make(map[string]EnricherFactory)}
+
+// RegisterEnricher makes an enricher available under name, to be listed in
+// ingestion.enrichers. Register enrichers from init functions; registering a
+// name twice panics.
+func RegisterEnricher(name string, factory EnricherFactory) {
+	enrichers.Lock()
+	defer enrichers.Unlock()
+	if _, dup := enrichers.factories[name]; dup {
+		panic(fmt.Sprintf("ingestion: enricher %s registered twice", name))
+	}
+	enrichers.factories[name] = factory
+}
+
+type namedEnricher struct {
+	name string
+	Enricher
+}
+
+// enricherChain runs the configured enrichers in order.
+type enricherChain struct {
+	enrichers []namedEnricher
+	logger    *slog.Logger
+}
+
+func newEnricherChain(cfg config.IngestionConfig, logger *slog.Logger) (*enricherChain, error) {
+	enrichers.RLock()
+	defer enrichers.RUnlock()
+
+	chain := &enricherChain{logger: logger}
+	for _, name := range cfg.Enrichers {
+		factory, ok := enrichers.factories[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown enricher: %s", name)
+		}
+		enricher, err := factory(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build enricher %s: %w", name, err)
+		}
+		chain.enrichers = append(chain.enrichers, namedEnricher{name: name, Enricher: enricher})
+	}
+	return chain, nil
+}
+
+// enrich runs every enricher on event. The server-side fields are cleared
+// first, so what the client sent for them is never kept, whichever enrichers
+// are configured. Enrichment is best effort: a failing enricher is logged and
+// the event goes on without its fields.
+func (c *enricherChain) enrich(ctx context.Context, event *streaming.Event) {
+	clearServerFields(event)
+	req, _ := requestInfoFrom(ctx)
+	for _, e := range c.enrichers {
+		if err := e.Enrich(ctx, req, event); err != nil {
+			c.logger.Warn("enricher failed", "enricher", e.name, "event_id", event.ID.String(), "error", err)
+		}
+	}
+}
+
+// clearServerFields clears the fields of event that only enrichers set.
+func clearServerFields(event *streaming.Event) {
+	m := &event.Metadata
+	m.IPAddress, m.UserAgent, m.OS, m.AppBuild, m.RequestID, m.TraceID = nil, nil, nil, nil, nil, nil
+	if payload, ok := event.Payload.(streaming.UserLoginPayload); ok {
+		payload.UserAgent, payload.IPAddress = nil, nil
+		event.Payload = payload
+	}
+}
+
+type requestInfoKey struct{}
+
+// withRequestInfo makes the request available to enrichers of the events it
+// carries.
+func withRequestInfo(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		req := RequestInfo{
+			RemoteAddr: r.RemoteAddr,
+			Header:     r.Header,
+		}
+		req.RequestID, _ = sharedcontext.GetRequestID(ctx)
+		req.TraceID, _ = sharedcontext.GetTraceID(ctx)
+		req.User, _ = sharedcontext.GetUserContext(ctx)
+		next(w, r.WithContext(context.WithValue(ctx, requestInfoKey{}, req)))
+	}
+}
+
+func requestInfoFrom(ctx context.Context) (RequestInfo, bool) {
+	req, ok := ctx.Value(requestInfoKey{}).(RequestInfo)
+	return req, ok
+}
@@ -0,0 +1,163 @@
+package ingestion
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/lavish-gambhir/dashbeam/shared/config"
+	"github.com/lavish-gambhir/dashbeam/shared/models"
+	"github.com/lavish-gambhir/dashbeam/shared/streaming"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+// forgedLogin is a login event with every server-side field filled in by the
+// client.
+func forgedLogin() streaming.Event {
+	event := newTestEvent(streaming.UserLogin, streaming.UserLoginPayload{
+		LoginMethod: "password",
+		UserAgent:   ptr("forged-agent"),
+		IPAddress:   ptr("10.9.9.9"),
+	})
+	event.Metadata.IPAddress = ptr("10.9.9.9")
+	event.Metadata.UserAgent = ptr("forged-agent")
+	event.Metadata.OS = ptr("forged-os")
+	event.Metadata.AppBuild = ptr("forged-build")
+	event.Metadata.RequestID = ptr("forged-request")
+	event.Metadata.TraceID = ptr("forged-trace")
+	return event
+}
+
+func str(s *string) string {
+	if s == nil {
+		return "<nil>"
+	}
+	return *s
+}
+
+func TestEnricherChain(t *testing.T) {
+	classroom := uuid.New()
+	req := RequestInfo{
+		RemoteAddr: "203.0.113.7:5123",
+		Header: http.Header{
+			"User-Agent":      {"Dashbeam-Notebook/2.3.1 (Linux; Android 13; Pixel 7)"},
+			"X-Forwarded-For": {"198.51.100.1"},
+		},
+		RequestID: "req-1",
+		TraceID:   "4bf92f3577b34da6a3ce929d0e0e4736",
+		User:      &models.UserContext{ClassroomID: &classroom},
+	}
+	noHeaders := req
+	noHeaders.Header = http.Header{}
+	noHeaders.RequestID, noHeaders.TraceID = "", ""
+
+	type want struct {
+		ip, userAgent, os, appBuild, requestID, traceID string
+		loginUserAgent, loginIP                         string
+		classroom                                       bool
+	}
+	unset := want{
+		ip: "<nil>", userAgent: "<nil>", os: "<nil>", appBuild: "<nil>", requestID: "<nil>", traceID: "<nil>",
+		loginUserAgent: "<nil>", loginIP: "<nil>",
+	}
+	tests := []struct {
+		name      string
+		enrichers []string
+		req       RequestInfo
+		want      want
+	}{
+		{name: "no enrichers", req: req, want: unset},
+		{
+			name:      "all enrichers",
+			enrichers: defaultEnrichers,
+			req:       req,
+			want: want{
+				ip:             "203.0.113.7",
+				userAgent:      "Dashbeam-Notebook/2.3.1 (Linux; Android 13; Pixel 7)",
+				os:             "Android 13",
+				appBuild:       "2.3.1",
+				requestID:      "req-1",
+				traceID:        "4bf92f3577b34da6a3ce929d0e0e4736",
+				loginUserAgent: "Dashbeam-Notebook/2.3.1 (Linux; Android 13; Pixel 7)",
+				loginIP:        "203.0.113.7",
+				classroom:      true,
+			},
+		},
+		{
+			name:      "request without headers",
+			enrichers: defaultEnrichers,
+			req:       noHeaders,
+			want: want{
+				ip: "203.0.113.7", userAgent: "<nil>", os: "<nil>", appBuild: "<nil>", requestID: "<nil>", traceID: "<nil>",
+				loginUserAgent: "<nil>", loginIP: "203.0.113.7", classroom: true,
+			},
+		},
+		{name: "user agent only, without header", enrichers: []string{EnricherUserAgent}, req: noHeaders, want: unset},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain, err := newEnricherChain(config.IngestionConfig{Enrichers: tt.enrichers}, discardLogger())
+			if err != nil {
+				t.Fatal(err)
+			}
+			event := forgedLogin()
+			ctx := context.WithValue(context.Background(), requestInfoKey{}, tt.req)
+			chain.enrich(ctx, &event)
+
+			m := event.Metadata
+			login := event.Payload.(streaming.UserLoginPayload)
+			got := want{
+				ip: str(m.IPAddress), userAgent: str(m.UserAgent), os: str(m.OS), appBuild: str(m.AppBuild),
+				requestID: str(m.RequestID), traceID: str(m.TraceID),
+				loginUserAgent: str(login.UserAgent), loginIP: str(login.IPAddress),
+				classroom: event.ClassroomID != nil && *event.ClassroomID == classroom,
+			}
+			if got != tt.want {
+				t.Errorf("enriched %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientIPEnricher(t *testing.T) {
+	tests := []struct {
+		name    string
+		trusted []string
+		remote  string
+		xff     []string
+		want    string
+	}{
+		{name: "direct", remote: "203.0.113.7:443", want: "203.0.113.7"},
+		{name: "untrusted proxy ignored", remote: "203.0.113.7:443", xff: []string{"198.51.100.1"}, want: "203.0.113.7"},
+		{name: "trusted proxy", trusted: []string{"10.0.0.0/8"}, remote: "10.1.2.3:443", xff: []string{"198.51.100.1"}, want: "198.51.100.1"},
+		{
+			name: "client-supplied hops skipped", trusted: []string{"10.0.0.0/8"}, remote: "10.1.2.3:443",
+			xff: []string{"1.1.1.1, 198.51.100.1, 10.4.4.4"}, want: "198.51.100.1",
+		},
+		{name: "trusted single address", trusted: []string{"10.1.2.3"}, remote: "10.1.2.3:443", xff: []string{"198.51.100.1"}, want: "198.51.100.1"},
+		{name: "IPv4-mapped", remote: "[::ffff:203.0.113.7]:443", want: "203.0.113.7"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := newClientIPEnricher(config.IngestionConfig{TrustedProxies: tt.trusted})
+			if err != nil {
+				t.Fatal(err)
+			}
+			event := newTestEvent(streaming.AppForeground, streaming.AppForegroundPayload{ScreenName: "home"})
+			req := RequestInfo{RemoteAddr: tt.remote, Header: http.Header{"X-Forwarded-For": tt.xff}}
+			if err := e.Enrich(context.Background(), req, &event); err != nil {
+				t.Fatal(err)
+			}
+			if got := str(event.Metadata.IPAddress); got != tt.want {
+				t.Errorf("IP = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := newClientIPEnricher(config.IngestionConfig{TrustedProxies: []string{"not-an-ip"}}); err == nil {
+		t.Error("invalid trusted proxy accepted")
+	}
+}
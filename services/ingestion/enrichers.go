@@ -0,0 +1,197 @@
+package ingestion
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"regexp"
+	"strings"
+
+	"github.com/lavish-gambhir/dashbeam/shared/config"
+	"github.com/lavish-gambhir/dashbeam/shared/streaming"
+)
+
+// Built-in enrichers, run in this order unless ingestion.enrichers says
+// otherwise.
+const (
+	EnricherClientIP       = "client_ip"
+	EnricherUserAgent      = "user_agent"
+	EnricherRequestContext = "request_context"
+	EnricherClassroom      = "classroom"
+)
+
+var defaultEnrichers = []string{EnricherClientIP, EnricherUserAgent, EnricherRequestContext, EnricherClassroom}
+
+func init() {
+	RegisterEnricher(EnricherClientIP, newClientIPEnricher)
+	RegisterEnricher(EnricherUserAgent, func(config.IngestionConfig) (Enricher, error) {
+		return EnricherFunc(enrichUserAgent), nil
+	})
+	RegisterEnricher(EnricherRequestContext, func(config.IngestionConfig) (Enricher, error) {
+		return EnricherFunc(enrichRequestContext), nil
+	})
+	RegisterEnricher(EnricherClassroom, func(config.IngestionConfig) (Enricher, error) {
+		return EnricherFunc(enrichClassroom), nil
+	})
+}
+
+// clientIPEnricher sets the IP address of the device that sent the event.
+// X-Forwarded-For is only honored when the request came from a trusted
+// proxy, and then walked from the right, past the trusted proxies, so a
+// client cannot pick its own address.
+type clientIPEnricher struct {
+	trusted []netip.Prefix
+}
+
+func newClientIPEnricher(cfg config.IngestionConfig) (Enricher, error) {
+	e := &clientIPEnricher{}
+	for _, cidr := range cfg.TrustedProxies {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(cidr)
+			if addrErr != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", cidr, err)
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		e.trusted = append(e.trusted, prefix.Masked())
+	}
+	return e, nil
+}
+
+func (e *clientIPEnricher) Enrich(_ context.Context, req RequestInfo, event *streaming.Event) error {
+	ip, ok := e.clientIP(req)
+	if !ok {
+		return fmt.Errorf("no client IP in remote address %q", req.RemoteAddr)
+	}
+	s := ip.String()
+	event.Metadata.IPAddress = &s
+	if payload, ok := event.Payload.(streaming.UserLoginPayload); ok {
+		payload.IPAddress = &s
+		event.Payload = payload
+	}
+	return nil
+}
+
+func (e *clientIPEnricher) clientIP(req RequestInfo) (netip.Addr, bool) {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	ip = ip.Unmap()
+
+	var hops []string
+	for _, header := range req.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+	for i := len(hops) - 1; i >= 0 && e.isTrusted(ip); i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+		ip = hop.Unmap()
+	}
+	return ip, true
+}
+
+func (e *clientIPEnricher) isTrusted(ip netip.Addr) bool {
+	for _, prefix := range e.trusted {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// enrichUserAgent records the User-Agent of the request and the OS and app
+// build parsed from it.
+func enrichUserAgent(_ context.Context, req RequestInfo, event *streaming.Event) error {
+	ua := req.Header.Get("User-Agent")
+	if ua == "" {
+		return nil
+	}
+	event.Metadata.UserAgent = &ua
+	if os := parseOS(ua); os != "" {
+		event.Metadata.OS = &os
+	}
+	if build := parseAppBuild(ua); build != "" {
+		event.Metadata.AppBuild = &build
+	}
+	if payload, ok := event.Payload.(streaming.UserLoginPayload); ok {
+		payload.UserAgent = &ua
+		event.Payload = payload
+	}
+	return nil
+}
+
+var (
+	osPatterns = []struct {
+		re   *regexp.Regexp
+		name string
+	}{
+		{regexp.MustCompile(`Android[ /]([\d.]+)`), "Android"},
+		{regexp.MustCompile(`iPad.*? OS ([\d_]+)`), "iPadOS"},
+		{regexp.MustCompile(`(?:iPhone )?OS ([\d_]+) like Mac OS X`), "iOS"},
+		{regexp.MustCompile(`Windows NT ([\d.]+)`), "Windows NT"},
+		{regexp.MustCompile(`Mac OS X ([\d_.]+)`), "macOS"},
+		{regexp.MustCompile(`CrOS \S+ ([\d.]+)`), "ChromeOS"},
+		{regexp.MustCompile(`Linux()`), "Linux"},
+	}
+	buildPattern   = regexp.MustCompile(`(?i)\bbuild[ /:]?([\w.-]+)`)
+	productPattern = regexp.MustCompile(`^([\w.-]+)/([\w.-]+)`)
+)
+
+// parseOS returns the OS name and version from a User-Agent, e.g.
+// "Android 13".
+func parseOS(ua string) string {
+	for _, p := range osPatterns {
+		if m := p.re.FindStringSubmatch(ua); m != nil {
+			if m[1] == "" {
+				return p.name
+			}
+			return p.name + " " + strings.ReplaceAll(m[1], "_", ".")
+		}
+	}
+	return ""
+}
+
+// parseAppBuild returns the app build from a User-Agent: the build number if
+// it carries one ("build 451"), or else the version of the leading product
+// of an app User-Agent ("Dashbeam-Notebook/2.3.1").
+func parseAppBuild(ua string) string {
+	if m := buildPattern.FindStringSubmatch(ua); m != nil {
+		return m[1]
+	}
+	if m := productPattern.FindStringSubmatch(ua); m != nil && m[1] != "Mozilla" {
+		return m[2]
+	}
+	return ""
+}
+
+// enrichRequestContext records the request and trace IDs the event arrived
+// with.
+func enrichRequestContext(_ context.Context, req RequestInfo, event *streaming.Event) error {
+	if req.RequestID != "" {
+		requestID := req.RequestID
+		event.Metadata.RequestID = &requestID
+	}
+	if req.TraceID != "" && strings.Trim(req.TraceID, "0") != "" {
+		traceID := req.TraceID
+		event.Metadata.TraceID = &traceID
+	}
+	return nil
+}
+
+// enrichClassroom fills in a missing classroom_id from the token.
+func enrichClassroom(_ context.Context, req RequestInfo, event *streaming.Event) error {
+	if event.ClassroomID == nil && req.User != nil && req.User.ClassroomID != nil {
+		classroomID := *req.User.ClassroomID
+		event.ClassroomID = &classroomID
+	}
+	return nil
+}
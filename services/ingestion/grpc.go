@@ -0,0 +1,326 @@
+package ingestion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/lavish-gambhir/dashbeam/pkg/apperr"
+	"github.com/lavish-gambhir/dashbeam/services/ingestion/ingestionpb"
+	sharedutil "github.com/lavish-gambhir/dashbeam/shared"
+	sharedcontext "github.com/lavish-gambhir/dashbeam/shared/context"
+	"github.com/lavish-gambhir/dashbeam/shared/streaming"
+)
+
+// grpcServer serves the ingestion API over gRPC, mirroring the HTTP
+// handlers: events go through the same validation, rate limiting, identity
+// checks, enrichment and publishing. Calls are expected to be authenticated
+// by the server's interceptors, like the HTTP routes by the auth middleware.
+type grpcServer struct {
+	ingestionpb.UnimplementedIngestionServiceServer
+	h *handler
+}
+
+func (s *grpcServer) SendBatch(ctx context.Context, req *ingestionpb.SendBatchRequest) (*ingestionpb.SendBatchResponse, error) {
+	ctx = withGRPCRequestInfo(ctx)
+	reqID, _ := sharedcontext.GetRequestID(ctx)
+	logger := s.h.logger.With("fn", "SendBatch").With("requestID", reqID)
+
+	userContext, ok := sharedcontext.GetUserContext(ctx)
+	if !ok {
+		logger.Error("user context not found - interceptor not applied correctly")
+		return nil, status.Error(codes.Unauthenticated, "authentication context missing")
+	}
+	logger = logger.With("userID", userContext.UserID.String()).With("schoolID", userContext.SchoolID.String())
+
+	if len(req.GetEvents()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "no events provided")
+	}
+	if len(req.GetEvents()) > s.h.config.MaxBatchSize {
+		return nil, status.Errorf(codes.InvalidArgument, "batch size %d exceeds maximum %d", len(req.GetEvents()), s.h.config.MaxBatchSize)
+	}
+
+	logger.Info("processing batch events", slog.Int("count", len(req.GetEvents())))
+	results := make([]EventResult, 0, len(req.GetEvents()))
+	for i, pbEvent := range req.GetEvents() {
+		results = append(results, s.processEvent(ctx, EventResult{Index: i}, pbEvent))
+	}
+	return batchResponseToProto(logger, results), nil
+}
+
+func (s *grpcServer) SendQuizEvent(ctx context.Context, req *ingestionpb.SendEventRequest) (*ingestionpb.SendEventResponse, error) {
+	return s.sendEvent(ctx, req, streaming.CategoryQuiz)
+}
+
+func (s *grpcServer) SendUserEvent(ctx context.Context, req *ingestionpb.SendEventRequest) (*ingestionpb.SendEventResponse, error) {
+	return s.sendEvent(ctx, req, streaming.CategoryUser)
+}
+
+func (s *grpcServer) SendSystemEvent(ctx context.Context, req *ingestionpb.SendEventRequest) (*ingestionpb.SendEventResponse, error) {
+	return s.sendEvent(ctx, req, streaming.CategorySystem)
+}
+
+func (s *grpcServer) sendEvent(ctx context.Context, req *ingestionpb.SendEventRequest, category streaming.EventCategory) (*ingestionpb.SendEventResponse, error) {
+	ctx = withGRPCRequestInfo(ctx)
+
+	event, err := eventFromProto(req.GetEvent())
+	if err != nil {
+		return nil, grpcError(ctx, apperr.Wrap(err, apperr.ValidationFailed, "invalid event"))
+	}
+	if event.Category() != category {
+		return nil, status.Errorf(codes.InvalidArgument, "not a %s event", category)
+	}
+
+	eventID, duplicate, err := s.h.processSingleEvent(ctx, *event)
+	if err != nil {
+		s.h.logger.Error("failed to process event", slog.Any("error", err), slog.String("event_type", event.Type.String()))
+		return nil, grpcError(ctx, err)
+	}
+
+	resp := &ingestionpb.SendEventResponse{EventId: eventID, Status: ingestionpb.EventStatus_EVENT_STATUS_ACCEPTED}
+	if duplicate {
+		resp.Status = ingestionpb.EventStatus_EVENT_STATUS_DUPLICATE
+	}
+	return resp, nil
+}
+
+// UploadEvents is the gRPC counterpart of handleStreamEvents: events are
+// processed as they are received. Once stream_max_events is reached the
+// rest is not read, and a final rejected result tells the client to resend
+// from there.
+func (s *grpcServer) UploadEvents(stream grpc.ClientStreamingServer[ingestionpb.UploadEventsRequest, ingestionpb.SendBatchResponse]) error {
+	ctx := withGRPCRequestInfo(stream.Context())
+	reqID, _ := sharedcontext.GetRequestID(ctx)
+	logger := s.h.logger.With("fn", "UploadEvents").With("requestID", reqID)
+
+	userContext, ok := sharedcontext.GetUserContext(ctx)
+	if !ok {
+		logger.Error("user context not found - interceptor not applied correctly")
+		return status.Error(codes.Unauthenticated, "authentication context missing")
+	}
+	logger = logger.With("userID", userContext.UserID.String()).With("schoolID", userContext.SchoolID.String())
+
+	var results []EventResult
+receive:
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			logger.Warn("failed to receive events", slog.Any("error", err), slog.Int("received", len(results)))
+			return err
+		}
+		for _, pbEvent := range req.GetEvents() {
+			if len(results) == s.h.config.StreamMaxEvents {
+				results = append(results, rejectedResult(EventResult{Index: len(results)},
+					apperr.Newf(apperr.BadRequest, "upload exceeds %d events; resend from this event", s.h.config.StreamMaxEvents)))
+				break receive
+			}
+			results = append(results, s.processEvent(ctx, EventResult{Index: len(results)}, pbEvent))
+		}
+	}
+
+	if len(results) == 0 {
+		return status.Error(codes.InvalidArgument, "no events provided")
+	}
+
+	logger.Info("processed event upload", slog.Int("count", len(results)))
+	return stream.SendAndClose(batchResponseToProto(logger, results))
+}
+
+// processEvent is processRawEvent for an event received over gRPC.
+func (s *grpcServer) processEvent(ctx context.Context, result EventResult, pbEvent *ingestionpb.Event) EventResult {
+	event, err := eventFromProto(pbEvent)
+	if err != nil {
+		result.EventID = pbEvent.GetEventId()
+		return rejectedResult(result, apperr.Wrapf(err, apperr.ValidationFailed, "invalid event at item %d", result.Index))
+	}
+	return s.h.processEvent(ctx, result, event)
+}
+
+// withGRPCRequestInfo is withRequestInfo for a gRPC call: the metadata take
+// the place of the request headers.
+func withGRPCRequestInfo(ctx context.Context) context.Context {
+	req := RequestInfo{Header: make(http.Header)}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		req.RemoteAddr = p.Addr.String()
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for key, values := range md {
+		if strings.HasPrefix(key, ":") || key == "authorization" {
+			continue
+		}
+		req.Header[http.CanonicalHeaderKey(key)] = values
+	}
+	req.RequestID, _ = sharedcontext.GetRequestID(ctx)
+	req.TraceID, _ = sharedcontext.GetTraceID(ctx)
+	req.User, _ = sharedcontext.GetUserContext(ctx)
+	return context.WithValue(ctx, requestInfoKey{}, req)
+}
+
+// grpcError is writeEventError for a gRPC call.
+func grpcError(ctx context.Context, err error) error {
+	if seconds, ok := retryAfter(err); ok {
+		_ = grpc.SetHeader(ctx, metadata.Pairs("retry-after", strconv.Itoa(seconds)))
+	}
+	result := rejectedResult(EventResult{}, err)
+	return status.Errorf(grpcCodeFor(err), "%s: %s", result.ErrorCode, result.Error)
+}
+
+func grpcCodeFor(err error) codes.Code {
+	switch apperr.GetCode(err) {
+	case apperr.ValidationFailed, apperr.JSONDecodingFailed, apperr.BadRequest:
+		return codes.InvalidArgument
+	case apperr.Unauthorized:
+		return codes.Unauthenticated
+	case apperr.Forbidden:
+		return codes.PermissionDenied
+	case apperr.RateLimited:
+		return codes.ResourceExhausted
+	default:
+		return codes.Internal
+	}
+}
+
+// batchResponseToProto summarizes the results of a multi-event call. Unlike
+// over HTTP, a batch whose events were all rejected is not an error: the
+// results tell why.
+func batchResponseToProto(logger *slog.Logger, results []EventResult) *ingestionpb.SendBatchResponse {
+	resp := summarizeResults(results)
+	switch resp.Status {
+	case StatusPartialSuccess:
+		logger.Warn("batch partially rejected", slog.Int("rejected", resp.Rejected), slog.Int("count", len(results)))
+	case "error":
+		logger.Warn("batch rejected", slog.Int("count", len(results)))
+	}
+
+	pbResp := &ingestionpb.SendBatchResponse{
+		Status:            resp.Status,
+		EventIds:          resp.EventIDs,
+		DuplicateEventIds: resp.DuplicateIDs,
+		Processed:         int32(resp.Processed),
+		Rejected:          int32(resp.Rejected),
+		Results:           make([]*ingestionpb.EventResult, 0, len(results)),
+		Timestamp:         timestamppb.New(resp.Timestamp),
+	}
+	for _, result := range results {
+		pbResp.Results = append(pbResp.Results, &ingestionpb.EventResult{
+			Index:             int32(result.Index),
+			EventId:           result.EventID,
+			Status:            eventStatusToProto(result.Status),
+			RewrittenFields:   result.Rewritten,
+			ErrorCode:         result.ErrorCode,
+			Error:             result.Error,
+			RetryAfterSeconds: int32(result.RetryAfter),
+		})
+	}
+	return pbResp
+}
+
+func eventStatusToProto(status string) ingestionpb.EventStatus {
+	switch status {
+	case EventAccepted:
+		return ingestionpb.EventStatus_EVENT_STATUS_ACCEPTED
+	case EventDuplicate:
+		return ingestionpb.EventStatus_EVENT_STATUS_DUPLICATE
+	case EventRejected:
+		return ingestionpb.EventStatus_EVENT_STATUS_REJECTED
+	default:
+		return ingestionpb.EventStatus_EVENT_STATUS_UNSPECIFIED
+	}
+}
+
+// payloadJSON marshals payload messages with the field names of the JSON
+// payloads.
+var payloadJSON = protojson.MarshalOptions{UseProtoNames: true}
+
+// eventFromProto converts an event received over gRPC. Its type is that of
+// the payload field set, and the payload is decoded as if it had been sent as
+// JSON, so that it is validated the same way.
+func eventFromProto(pb *ingestionpb.Event) (*streaming.Event, error) {
+	if pb == nil {
+		return nil, errors.New("event is missing")
+	}
+
+	msg := pb.ProtoReflect()
+	payloadField := msg.WhichOneof(msg.Descriptor().Oneofs().ByName("payload"))
+	if payloadField == nil {
+		return nil, streaming.ErrInvalidPayload
+	}
+	eventType := streaming.EventType(strings.ReplaceAll(string(payloadField.Name()), "_", "."))
+	data, err := payloadJSON.Marshal(msg.Get(payloadField).Message().Interface())
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode payload: %w", err)
+	}
+	payload, err := streaming.DecodePayload(eventType, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse payload for event type %s: %w", eventType, err)
+	}
+
+	event := &streaming.Event{
+		Type:          eventType,
+		SchemaVersion: streaming.CurrentSchemaVersion(eventType),
+		Payload:       payload,
+		Metadata:      metadataFromProto(pb.GetMetadata()),
+	}
+	if pb.GetEventId() != "" {
+		if event.ID, err = sharedutil.ParseUUID(pb.GetEventId()); err != nil {
+			return nil, fmt.Errorf("invalid event_id: %w", err)
+		}
+	}
+	if event.UserID, err = sharedutil.ParseUUID(pb.GetUserId()); err != nil {
+		return nil, fmt.Errorf("invalid user_id: %w", err)
+	}
+	if event.SchoolID, err = sharedutil.ParseUUID(pb.GetSchoolId()); err != nil {
+		return nil, fmt.Errorf("invalid school_id: %w", err)
+	}
+	if pb.ClassroomId != nil {
+		classroomID, err := sharedutil.ParseUUID(pb.GetClassroomId())
+		if err != nil {
+			return nil, fmt.Errorf("invalid classroom_id: %w", err)
+		}
+		event.ClassroomID = &classroomID
+	}
+	if pb.Timestamp != nil {
+		if err := pb.Timestamp.CheckValid(); err != nil {
+			return nil, fmt.Errorf("invalid timestamp: %w", err)
+		}
+		event.Timestamp = pb.Timestamp.AsTime()
+	}
+	switch pb.GetAppType() {
+	case ingestionpb.AppType_APP_TYPE_WHITEBOARD:
+		event.AppType = streaming.AppTypeWhite
+	case ingestionpb.AppType_APP_TYPE_NOTEBOOK:
+		event.AppType = streaming.AppTypeNote
+	}
+	return event, nil
+}
+
+func metadataFromProto(pb *ingestionpb.Metadata) streaming.Metadata {
+	if pb == nil {
+		return streaming.Metadata{}
+	}
+	return streaming.Metadata{
+		AppVersion:  pb.GetAppVersion(),
+		DeviceType:  pb.GetDeviceType(),
+		DeviceID:    pb.GetDeviceId(),
+		NetworkType: pb.NetworkType,
+		ClientTime:  pb.ClientTime,
+		SessionID:   pb.SessionId,
+		IPAddress:   pb.IpAddress,
+	}
+}
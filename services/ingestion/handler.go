@@ -8,26 +8,57 @@ import (
 	"github.com/lavish-gambhir/dashbeam/pkg/apperr"
 	"github.com/lavish-gambhir/dashbeam/pkg/utils"
 	"github.com/lavish-gambhir/dashbeam/services/ingestion/repository"
+	"github.com/lavish-gambhir/dashbeam/shared/config"
 	sharedcontext "github.com/lavish-gambhir/dashbeam/shared/context"
 	"github.com/lavish-gambhir/dashbeam/shared/streaming"
 )
 
 type handler struct {
-	userRepo     repository.User
-	quizRepo     repository.Quiz
-	messageQueue streaming.MessageQueue
-	logger       *slog.Logger
-	maxBatchSize int
+	userRepo      repository.User
+	quizRepo      repository.Quiz
+	processedRepo repository.ProcessedEvent
+	outboxRepo    repository.Outbox
+	identity      *identityPolicy
+	clock         *clockCorrector
+	transactor    repository.Transactor
+	dedup         streaming.Deduplicator
+	messageQueue  streaming.MessageQueue
+	enrichers     *enricherChain
+	limits        *rateLimiter
+	logger        *slog.Logger
+	config        config.IngestionConfig
 }
 
-func NewHandler(userRepo repository.User, quizRepo repository.Quiz, messageQueue streaming.MessageQueue, logger *slog.Logger, maxBatchSize int) *handler {
+func NewHandler(
+	userRepo repository.User,
+	quizRepo repository.Quiz,
+	processedRepo repository.ProcessedEvent,
+	classroomRepo repository.Classroom,
+	outboxRepo repository.Outbox,
+	transactor repository.Transactor,
+	dedup streaming.Deduplicator,
+	messageQueue streaming.MessageQueue,
+	enrichers *enricherChain,
+	limits *rateLimiter,
+	config config.IngestionConfig,
+	logger *slog.Logger,
+) *handler {
 	log := logger.With("handler", "ingestion.handler")
+	config = withDefaults(config)
 	return &handler{
-		userRepo:     userRepo,
-		quizRepo:     quizRepo,
-		messageQueue: messageQueue,
-		logger:       log,
-		maxBatchSize: maxBatchSize,
+		userRepo:      userRepo,
+		quizRepo:      quizRepo,
+		processedRepo: processedRepo,
+		outboxRepo:    outboxRepo,
+		identity:      newIdentityPolicy(classroomRepo, config.IdentityMode),
+		clock:         newClockCorrector(config.MaxClockSkew),
+		transactor:    transactor,
+		dedup:         dedup,
+		messageQueue:  messageQueue,
+		enrichers:     enrichers,
+		limits:        limits,
+		logger:        log,
+		config:        config,
 	}
 }
 
@@ -60,25 +91,14 @@ func (h *handler) handleBatchEvents(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if len(req.Events) > h.maxBatchSize {
-		utils.WriteJSONError(w, apperr.Newf(apperr.BadRequest, "batch size %d exceeds maximum %d", len(req.Events), h.maxBatchSize), http.StatusRequestEntityTooLarge)
+	if len(req.Events) > h.config.MaxBatchSize {
+		utils.WriteJSONError(w, apperr.Newf(apperr.BadRequest, "batch size %d exceeds maximum %d", len(req.Events), h.config.MaxBatchSize), http.StatusRequestEntityTooLarge)
 		return
 	}
 
 	logger.Info("processing batch events", slog.Int("count", len(req.Events)))
-	eventIDs, err := h.processBatchEvents(ctx, req.Events)
-	if err != nil {
-		h.logger.Error("failed to process batch events", slog.Any("error", err), slog.Int("count", len(req.Events)))
-		utils.WriteJSONError(w, err, http.StatusInternalServerError)
-		return
-	}
-
-	utils.WriteJSONSuccess(w, EventResponse{
-		Status:    "success",
-		EventIDs:  eventIDs,
-		Processed: len(req.Events),
-		Timestamp: time.Now().UTC(),
-	})
+	results := h.processBatchEvents(ctx, req.Events)
+	writeEventResults(w, logger, results)
 }
 
 func (h *handler) handleQuizEvent(w http.ResponseWriter, r *http.Request) {
@@ -100,19 +120,14 @@ func (h *handler) handleQuizEvent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	eventID, err := h.processSingleEvent(ctx, req.Event)
+	eventID, duplicate, err := h.processSingleEvent(ctx, req.Event)
 	if err != nil {
 		h.logger.Error("failed to process quiz event", slog.Any("error", err), slog.String("event_type", req.Event.Type.String()))
-		utils.WriteJSONError(w, err, http.StatusInternalServerError)
+		writeEventError(w, err)
 		return
 	}
 
-	utils.WriteJSONSuccess(w, EventResponse{
-		Status:    "success",
-		EventIDs:  []string{eventID},
-		Processed: 1,
-		Timestamp: time.Now().UTC(),
-	})
+	utils.WriteJSONSuccess(w, singleEventResponse(eventID, duplicate))
 }
 
 func (h *handler) handleUserEvent(w http.ResponseWriter, r *http.Request) {
@@ -134,19 +149,14 @@ func (h *handler) handleUserEvent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	eventID, err := h.processSingleEvent(ctx, req.Event)
+	eventID, duplicate, err := h.processSingleEvent(ctx, req.Event)
 	if err != nil {
 		h.logger.Error("failed to process user event", slog.Any("error", err), slog.String("event_type", req.Event.Type.String()))
-		utils.WriteJSONError(w, err, http.StatusInternalServerError)
+		writeEventError(w, err)
 		return
 	}
 
-	utils.WriteJSONSuccess(w, EventResponse{
-		Status:    "success",
-		EventIDs:  []string{eventID},
-		Processed: 1,
-		Timestamp: time.Now().UTC(),
-	})
+	utils.WriteJSONSuccess(w, singleEventResponse(eventID, duplicate))
 }
 
 func (h *handler) handleSystemEvent(w http.ResponseWriter, r *http.Request) {
@@ -168,17 +178,129 @@ func (h *handler) handleSystemEvent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	eventID, err := h.processSingleEvent(ctx, req.Event)
+	eventID, duplicate, err := h.processSingleEvent(ctx, req.Event)
 	if err != nil {
 		h.logger.Error("failed to process system event", slog.Any("error", err), slog.String("event_type", req.Event.Type.String()))
-		utils.WriteJSONError(w, err, http.StatusInternalServerError)
+		writeEventError(w, err)
 		return
 	}
 
-	utils.WriteJSONSuccess(w, EventResponse{
+	utils.WriteJSONSuccess(w, singleEventResponse(eventID, duplicate))
+}
+
+func singleEventResponse(eventID string, duplicate bool) EventResponse {
+	if duplicate {
+		return EventResponse{
+			Status:       StatusAlreadyAccepted,
+			EventIDs:     []string{eventID},
+			DuplicateIDs: []string{eventID},
+			Timestamp:    time.Now().UTC(),
+		}
+	}
+	return EventResponse{
 		Status:    "success",
 		EventIDs:  []string{eventID},
 		Processed: 1,
 		Timestamp: time.Now().UTC(),
-	})
+	}
+}
+
+// summarizeResults builds the response of a multi-event request from its
+// per-event results: success when every event went through, partial_success
+// when some were rejected and error when all were.
+func summarizeResults(results []EventResult) EventResponse {
+	resp := EventResponse{
+		Results:   results,
+		Timestamp: time.Now().UTC(),
+	}
+	for _, result := range results {
+		switch result.Status {
+		case EventAccepted:
+			resp.EventIDs = append(resp.EventIDs, result.EventID)
+			resp.Processed++
+		case EventDuplicate:
+			resp.EventIDs = append(resp.EventIDs, result.EventID)
+			resp.DuplicateIDs = append(resp.DuplicateIDs, result.EventID)
+		case EventRejected:
+			resp.Rejected++
+		}
+	}
+	switch {
+	case resp.Rejected == 0:
+		resp.Status = "success"
+	case resp.Rejected < len(results):
+		resp.Status = StatusPartialSuccess
+	default:
+		resp.Status = "error"
+	}
+	return resp
+}
+
+// writeEventResults writes the per-event results of a multi-event request:
+// 200 when every event went through, 207 when some were rejected, and an
+// error when all were: 429 when all were rate limited, 400 when all were
+// rejected for the client's doing, and 500 otherwise.
+func writeEventResults(w http.ResponseWriter, logger *slog.Logger, results []EventResult) {
+	resp := summarizeResults(results)
+	clientErrorsOnly, rateLimitedOnly := true, true
+	maxRetryAfter := 0
+	for _, result := range results {
+		if result.Status != EventRejected {
+			continue
+		}
+		switch apperr.ErrCode(result.ErrorCode) {
+		case apperr.ValidationFailed, apperr.JSONDecodingFailed, apperr.BadRequest, apperr.Forbidden, apperr.RateLimited:
+		default:
+			clientErrorsOnly = false
+		}
+		if apperr.ErrCode(result.ErrorCode) == apperr.RateLimited {
+			maxRetryAfter = max(maxRetryAfter, result.RetryAfter)
+		} else {
+			rateLimitedOnly = false
+		}
+	}
+	if maxRetryAfter > 0 {
+		setRetryAfter(w, maxRetryAfter)
+	}
+
+	switch resp.Status {
+	case "success":
+		utils.WriteJSONSuccess(w, resp)
+	case StatusPartialSuccess:
+		logger.Warn("batch partially rejected", slog.Int("rejected", resp.Rejected), slog.Int("count", len(results)))
+		utils.WriteJSONSuccessWithStatus(w, resp, http.StatusMultiStatus)
+	default:
+		logger.Warn("batch rejected", slog.Int("count", len(results)))
+		switch {
+		case rateLimitedOnly:
+			utils.WriteJSONErrorWithDetails(w, apperr.New(apperr.RateLimited, "rate limit exceeded"), resp, http.StatusTooManyRequests)
+		case clientErrorsOnly:
+			utils.WriteJSONErrorWithDetails(w, apperr.New(apperr.ValidationFailed, "all events were rejected"), resp, http.StatusBadRequest)
+		default:
+			utils.WriteJSONErrorWithDetails(w, apperr.New(apperr.Internal, "no events could be accepted"), resp, http.StatusInternalServerError)
+		}
+	}
+}
+
+// writeEventError writes the error of a single-event request.
+func writeEventError(w http.ResponseWriter, err error) {
+	if seconds, ok := retryAfter(err); ok {
+		setRetryAfter(w, seconds)
+	}
+	utils.WriteJSONError(w, err, statusFor(err))
+}
+
+func statusFor(err error) int {
+	switch apperr.GetCode(err) {
+	case apperr.ValidationFailed, apperr.JSONDecodingFailed, apperr.BadRequest:
+		return http.StatusBadRequest
+	case apperr.Unauthorized:
+		return http.StatusUnauthorized
+	case apperr.Forbidden:
+		return http.StatusForbidden
+	case apperr.RateLimited:
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
 }
@@ -0,0 +1,143 @@
+package ingestion
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/lavish-gambhir/dashbeam/pkg/apperr"
+)
+
+func accepted(id string) EventResult { return EventResult{EventID: id, Status: EventAccepted} }
+
+func duplicate(id string) EventResult { return EventResult{EventID: id, Status: EventDuplicate} }
+
+func rejected(code apperr.ErrCode, retryAfter int) EventResult {
+	return EventResult{Status: EventRejected, ErrorCode: string(code), RetryAfter: retryAfter}
+}
+
+func TestWriteEventResults(t *testing.T) {
+	tests := []struct {
+		name           string
+		results        []EventResult
+		wantStatus     int
+		wantBody       string // status of the response body
+		wantCode       apperr.ErrCode
+		wantRetryAfter int
+		wantProcessed  int
+		wantDuplicates int
+	}{
+		{
+			name:       "all accepted",
+			results:    []EventResult{accepted("a"), accepted("b")},
+			wantStatus: http.StatusOK, wantBody: "success", wantProcessed: 2,
+		},
+		{
+			name:       "accepted and duplicate",
+			results:    []EventResult{accepted("a"), duplicate("b")},
+			wantStatus: http.StatusOK, wantBody: "success", wantProcessed: 1, wantDuplicates: 1,
+		},
+		{
+			name:       "some invalid",
+			results:    []EventResult{accepted("a"), rejected(apperr.ValidationFailed, 0)},
+			wantStatus: http.StatusMultiStatus, wantBody: StatusPartialSuccess, wantProcessed: 1,
+		},
+		{
+			name:       "some rate limited",
+			results:    []EventResult{accepted("a"), rejected(apperr.RateLimited, 3), rejected(apperr.RateLimited, 7)},
+			wantStatus: http.StatusMultiStatus, wantBody: StatusPartialSuccess, wantRetryAfter: 7, wantProcessed: 1,
+		},
+		{
+			name:       "all rate limited",
+			results:    []EventResult{rejected(apperr.RateLimited, 3), rejected(apperr.RateLimited, 7)},
+			wantStatus: http.StatusTooManyRequests, wantBody: "error", wantCode: apperr.RateLimited, wantRetryAfter: 7,
+		},
+		{
+			name:       "all invalid or forbidden",
+			results:    []EventResult{rejected(apperr.ValidationFailed, 0), rejected(apperr.Forbidden, 0)},
+			wantStatus: http.StatusBadRequest, wantBody: "error", wantCode: apperr.ValidationFailed,
+		},
+		{
+			name:       "all rate limited or invalid",
+			results:    []EventResult{rejected(apperr.RateLimited, 3), rejected(apperr.ValidationFailed, 0)},
+			wantStatus: http.StatusBadRequest, wantBody: "error", wantCode: apperr.ValidationFailed, wantRetryAfter: 3,
+		},
+		{
+			name:       "all failed on the server",
+			results:    []EventResult{rejected(apperr.Internal, 0), rejected(apperr.ValidationFailed, 0)},
+			wantStatus: http.StatusInternalServerError, wantBody: "error", wantCode: apperr.Internal,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			writeEventResults(rec, discardLogger(), tt.results)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			wantRetryAfter := ""
+			if tt.wantRetryAfter > 0 {
+				wantRetryAfter = strconv.Itoa(tt.wantRetryAfter)
+			}
+			if got := rec.Header().Get("Retry-After"); got != wantRetryAfter {
+				t.Errorf("Retry-After = %q, want %q", got, wantRetryAfter)
+			}
+
+			// Successes carry the results as data, errors as details.
+			var body struct {
+				Code    string        `json:"code"`
+				Data    EventResponse `json:"data"`
+				Details EventResponse `json:"details"`
+			}
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			resp := body.Data
+			if body.Code != "" {
+				resp = body.Details
+			}
+			if body.Code != string(tt.wantCode) {
+				t.Errorf("code = %q, want %q", body.Code, tt.wantCode)
+			}
+			if resp.Status != tt.wantBody || len(resp.Results) != len(tt.results) {
+				t.Errorf("body status = %q with %d results, want %q with %d", resp.Status, len(resp.Results), tt.wantBody, len(tt.results))
+			}
+			if resp.Processed != tt.wantProcessed || len(resp.DuplicateIDs) != tt.wantDuplicates {
+				t.Errorf("processed = %d, duplicates = %v, want %d, %d", resp.Processed, resp.DuplicateIDs, tt.wantProcessed, tt.wantDuplicates)
+			}
+		})
+	}
+}
+
+func TestWriteEventError(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		wantStatus     int
+		wantRetryAfter string
+	}{
+		{name: "invalid", err: apperr.New(apperr.ValidationFailed, "screen_name is required"), wantStatus: http.StatusBadRequest},
+		{name: "forbidden", err: apperr.New(apperr.Forbidden, "user_id does not match the token"), wantStatus: http.StatusForbidden},
+		{
+			name:       "rate limited",
+			err:        apperr.New(apperr.RateLimited, "rate limit exceeded").WithDetails(RateLimitDetails{RetryAfter: 4}),
+			wantStatus: http.StatusTooManyRequests, wantRetryAfter: "4",
+		},
+		{name: "database down", err: apperr.New(apperr.DBQueryFailed, "connection refused"), wantStatus: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			writeEventError(rec, tt.err)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Header().Get("Retry-After"); got != tt.wantRetryAfter {
+				t.Errorf("Retry-After = %q, want %q", got, tt.wantRetryAfter)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package ingestion
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/lavish-gambhir/dashbeam/pkg/apperr"
+	"github.com/lavish-gambhir/dashbeam/services/ingestion/repository"
+	sharedcontext "github.com/lavish-gambhir/dashbeam/shared/context"
+	"github.com/lavish-gambhir/dashbeam/shared/models"
+	"github.com/lavish-gambhir/dashbeam/shared/streaming"
+)
+
+// Identity modes, see config.IngestionConfig.IdentityMode.
+const (
+	IdentityReject  = "reject"
+	IdentityRewrite = "rewrite"
+)
+
+// maxCachedQuizSessions bounds the quiz sessions whose classroom is cached;
+// the cache starts over once it is full.
+const maxCachedQuizSessions = 10000
+
+// identityPolicy checks the identity fields of ingested events against the
+// token they were sent with. An event may only be written for the token's
+// user, school and classroom, except that a teacher may write events for
+// active members of their own classroom, e.g. when syncing a classroom
+// device. The quiz session an event belongs to must be held in the token's
+// school, and in its classroom or one the event's user is a member of.
+type identityPolicy struct {
+	classroomRepo repository.Classroom
+	rewrite       bool
+
+	mu       sync.Mutex
+	sessions map[uuid.UUID]sessionClassroom // a session's classroom never changes
+}
+
+type sessionClassroom struct {
+	classroomID uuid.UUID
+	schoolID    uuid.UUID
+}
+
+// newIdentityPolicy returns the policy for mode. Anything but
+// IdentityRewrite rejects, so a misconfigured mode fails closed.
+func newIdentityPolicy(classroomRepo repository.Classroom, mode string) *identityPolicy {
+	return &identityPolicy{
+		classroomRepo: classroomRepo,
+		rewrite:       mode == IdentityRewrite,
+		sessions:      make(map[uuid.UUID]sessionClassroom),
+	}
+}
+
+// authorize checks event against the UserContext in ctx. In rewrite mode,
+// mismatching fields are set to the token's values and reported; otherwise
+// the event is rejected with a Forbidden error. A quiz session of another
+// school or classroom has no value to rewrite to, so it is rejected in
+// either mode.
+func (p *identityPolicy) authorize(ctx context.Context, event *streaming.Event) ([]string, error) {
+	uc, ok := sharedcontext.GetUserContext(ctx)
+	if !ok {
+		return nil, apperr.New(apperr.Unauthorized, "authentication context missing")
+	}
+
+	var mismatched []string
+	if event.SchoolID != uc.SchoolID {
+		mismatched = append(mismatched, "school_id")
+	}
+	if event.ClassroomID != nil && !uc.HasClassroomAccess(*event.ClassroomID) {
+		mismatched = append(mismatched, "classroom_id")
+	}
+	if event.UserID != uc.UserID {
+		onBehalf, err := p.teacherOfUser(ctx, uc, event)
+		if err != nil {
+			return nil, err
+		}
+		if !onBehalf || len(mismatched) > 0 {
+			mismatched = append(mismatched, "user_id")
+		}
+	}
+
+	if len(mismatched) > 0 {
+		if !p.rewrite {
+			return nil, apperr.Newf(apperr.Forbidden, "event %s does not match the token: %s", event.ID, strings.Join(mismatched, ", "))
+		}
+		event.SchoolID = uc.SchoolID
+		event.UserID = uc.UserID
+		if event.ClassroomID != nil && !uc.HasClassroomAccess(*event.ClassroomID) {
+			event.ClassroomID = copyUUID(uc.ClassroomID)
+		}
+	}
+
+	if sessionID, ok := event.QuizSessionID(); ok {
+		if err := p.authorizeQuizSession(ctx, uc, event, sessionID); err != nil {
+			return nil, err
+		}
+	}
+	return mismatched, nil
+}
+
+// authorizeQuizSession checks that the quiz session of event is held in the
+// token's school, and in the token's classroom or one the event's user is an
+// active member of.
+func (p *identityPolicy) authorizeQuizSession(ctx context.Context, uc *models.UserContext, event *streaming.Event, sessionID uuid.UUID) error {
+	session, err := p.quizSessionClassroom(ctx, sessionID)
+	if err != nil {
+		if apperr.Is(err, apperr.DBRecordNotFound) {
+			return apperr.Newf(apperr.Forbidden, "event %s is for unknown quiz session %s", event.ID, sessionID)
+		}
+		return apperr.Wrapf(err, apperr.Internal, "failed to check quiz session for event %s", event.ID)
+	}
+	if session.schoolID != uc.SchoolID {
+		return apperr.Newf(apperr.Forbidden, "quiz session %s of event %s is not in the token's school", sessionID, event.ID)
+	}
+	if uc.HasClassroomAccess(session.classroomID) {
+		return nil
+	}
+	member, err := p.classroomRepo.IsActiveMember(ctx, session.classroomID, event.UserID)
+	if err != nil {
+		return apperr.Wrapf(err, apperr.Internal, "failed to check classroom membership for event %s", event.ID)
+	}
+	if !member {
+		return apperr.Newf(apperr.Forbidden, "quiz session %s of event %s is not in the user's classroom", sessionID, event.ID)
+	}
+	return nil
+}
+
+func (p *identityPolicy) quizSessionClassroom(ctx context.Context, sessionID uuid.UUID) (sessionClassroom, error) {
+	p.mu.Lock()
+	session, ok := p.sessions[sessionID]
+	p.mu.Unlock()
+	if ok {
+		return session, nil
+	}
+
+	classroomID, schoolID, err := p.classroomRepo.QuizSessionClassroom(ctx, sessionID)
+	if err != nil {
+		return sessionClassroom{}, err
+	}
+	session = sessionClassroom{classroomID: classroomID, schoolID: schoolID}
+
+	p.mu.Lock()
+	if len(p.sessions) >= maxCachedQuizSessions {
+		p.sessions = make(map[uuid.UUID]sessionClassroom)
+	}
+	p.sessions[sessionID] = session
+	p.mu.Unlock()
+	return session, nil
+}
+
+// teacherOfUser reports whether uc is a teacher writing event for an active
+// member of the teacher's own classroom.
+func (p *identityPolicy) teacherOfUser(ctx context.Context, uc *models.UserContext, event *streaming.Event) (bool, error) {
+	if !uc.IsTeacher() || event.ClassroomID == nil || !uc.HasClassroomAccess(*event.ClassroomID) {
+		return false, nil
+	}
+	member, err := p.classroomRepo.IsActiveMember(ctx, *event.ClassroomID, event.UserID)
+	if err != nil {
+		return false, apperr.Wrapf(err, apperr.Internal, "failed to check classroom membership for event %s", event.ID)
+	}
+	return member, nil
+}
+
+func copyUUID(id *uuid.UUID) *uuid.UUID {
+	if id == nil {
+		return nil
+	}
+	c := *id
+	return &c
+}
@@ -0,0 +1,188 @@
+package ingestion
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/lavish-gambhir/dashbeam/pkg/apperr"
+	sharedcontext "github.com/lavish-gambhir/dashbeam/shared/context"
+	"github.com/lavish-gambhir/dashbeam/shared/models"
+	"github.com/lavish-gambhir/dashbeam/shared/streaming"
+)
+
+// fakeClassroomRepo holds classroom members and quiz sessions in memory.
+type fakeClassroomRepo struct {
+	members  map[uuid.UUID][]uuid.UUID // classroom -> users
+	sessions map[uuid.UUID]sessionClassroom
+	lookups  int
+}
+
+func (r *fakeClassroomRepo) IsActiveMember(_ context.Context, classroomID, userID uuid.UUID) (bool, error) {
+	for _, member := range r.members[classroomID] {
+		if member == userID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeClassroomRepo) QuizSessionClassroom(_ context.Context, sessionID uuid.UUID) (uuid.UUID, uuid.UUID, error) {
+	r.lookups++
+	session, ok := r.sessions[sessionID]
+	if !ok {
+		return uuid.Nil, uuid.Nil, apperr.New(apperr.DBRecordNotFound, "quiz session not found")
+	}
+	return session.classroomID, session.schoolID, nil
+}
+
+func TestIdentityPolicyAuthorize(t *testing.T) {
+	school, otherSchool := uuid.New(), uuid.New()
+	classroom, otherClassroom := uuid.New(), uuid.New()
+	student, classmate, stranger := uuid.New(), uuid.New(), uuid.New()
+	teacher := uuid.New()
+	repo := &fakeClassroomRepo{
+		members: map[uuid.UUID][]uuid.UUID{
+			classroom:      {student, classmate},
+			otherClassroom: {stranger},
+		},
+	}
+
+	studentToken := &models.UserContext{UserID: student, SchoolID: school, ClassroomID: &classroom, Role: "student"}
+	teacherToken := &models.UserContext{UserID: teacher, SchoolID: school, ClassroomID: &classroom, Role: "teacher"}
+
+	tests := []struct {
+		name         string
+		token        *models.UserContext
+		userID       uuid.UUID
+		schoolID     uuid.UUID
+		classroom    *uuid.UUID
+		rewrite      bool
+		wantErr      apperr.ErrCode
+		wantMismatch []string
+	}{
+		{name: "own event", token: studentToken, userID: student, schoolID: school, classroom: &classroom},
+		{name: "no classroom", token: studentToken, userID: student, schoolID: school},
+		{name: "other user", token: studentToken, userID: classmate, schoolID: school, classroom: &classroom, wantErr: apperr.Forbidden},
+		{name: "other school", token: studentToken, userID: student, schoolID: otherSchool, wantErr: apperr.Forbidden},
+		{name: "other classroom", token: studentToken, userID: student, schoolID: school, classroom: &otherClassroom, wantErr: apperr.Forbidden},
+		{name: "teacher for member", token: teacherToken, userID: classmate, schoolID: school, classroom: &classroom},
+		{name: "teacher for non-member", token: teacherToken, userID: stranger, schoolID: school, classroom: &classroom, wantErr: apperr.Forbidden},
+		{name: "teacher without classroom", token: teacherToken, userID: classmate, schoolID: school, wantErr: apperr.Forbidden},
+		{
+			name: "rewrite other user", token: studentToken, userID: classmate, schoolID: school, classroom: &classroom,
+			rewrite: true, wantMismatch: []string{"user_id"},
+		},
+		{
+			name: "rewrite everything", token: studentToken, userID: stranger, schoolID: otherSchool, classroom: &otherClassroom,
+			rewrite: true, wantMismatch: []string{"school_id", "classroom_id", "user_id"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mode := IdentityReject
+			if tt.rewrite {
+				mode = IdentityRewrite
+			}
+			p := newIdentityPolicy(repo, mode)
+			event := newTestEvent(streaming.AppForeground, streaming.AppForegroundPayload{})
+			event.UserID, event.SchoolID, event.ClassroomID = tt.userID, tt.schoolID, copyUUID(tt.classroom)
+
+			ctx := sharedcontext.WithUserContext(context.Background(), tt.token)
+			mismatched, err := p.authorize(ctx, &event)
+			if tt.wantErr != "" {
+				if !apperr.Is(err, tt.wantErr) {
+					t.Fatalf("authorize() error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("authorize() error = %v", err)
+			}
+			if !slices.Equal(mismatched, tt.wantMismatch) {
+				t.Errorf("mismatched = %v, want %v", mismatched, tt.wantMismatch)
+			}
+			if tt.rewrite {
+				if event.UserID != tt.token.UserID || event.SchoolID != tt.token.SchoolID {
+					t.Errorf("event not rewritten to the token: user %s, school %s", event.UserID, event.SchoolID)
+				}
+				if event.ClassroomID != nil && !tt.token.HasClassroomAccess(*event.ClassroomID) {
+					t.Errorf("classroom %s not rewritten to the token's", event.ClassroomID)
+				}
+			}
+		})
+	}
+}
+
+func TestIdentityPolicyQuizSession(t *testing.T) {
+	school, otherSchool := uuid.New(), uuid.New()
+	classroom, otherClassroom, joinedClassroom := uuid.New(), uuid.New(), uuid.New()
+	student := uuid.New()
+	own, otherSchools, otherClassrooms, joined := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	repo := &fakeClassroomRepo{
+		members: map[uuid.UUID][]uuid.UUID{joinedClassroom: {student}},
+		sessions: map[uuid.UUID]sessionClassroom{
+			own:             {classroomID: classroom, schoolID: school},
+			otherSchools:    {classroomID: otherClassroom, schoolID: otherSchool},
+			otherClassrooms: {classroomID: otherClassroom, schoolID: school},
+			joined:          {classroomID: joinedClassroom, schoolID: school},
+		},
+	}
+	token := &models.UserContext{UserID: student, SchoolID: school, ClassroomID: &classroom, Role: "student"}
+
+	tests := []struct {
+		name      string
+		sessionID uuid.UUID
+		rewrite   bool
+		wantErr   apperr.ErrCode
+	}{
+		{name: "token's classroom", sessionID: own},
+		{name: "classroom the user is a member of", sessionID: joined},
+		{name: "other school", sessionID: otherSchools, wantErr: apperr.Forbidden},
+		{name: "other school in rewrite mode", sessionID: otherSchools, rewrite: true, wantErr: apperr.Forbidden},
+		{name: "other classroom", sessionID: otherClassrooms, wantErr: apperr.Forbidden},
+		{name: "unknown session", sessionID: uuid.New(), wantErr: apperr.Forbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mode := IdentityReject
+			if tt.rewrite {
+				mode = IdentityRewrite
+			}
+			p := newIdentityPolicy(repo, mode)
+			event := newTestEvent(streaming.QuizSessionStarted, streaming.QuizSessionStartedPayload{
+				QuizID: uuid.New(), SessionID: tt.sessionID,
+			})
+			event.UserID, event.SchoolID = student, school
+
+			ctx := sharedcontext.WithUserContext(context.Background(), token)
+			_, err := p.authorize(ctx, &event)
+			if tt.wantErr != "" {
+				if !apperr.Is(err, tt.wantErr) {
+					t.Fatalf("authorize() error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("authorize() error = %v", err)
+			}
+		})
+	}
+
+	// A session's classroom is looked up once.
+	p := newIdentityPolicy(repo, IdentityReject)
+	ctx := sharedcontext.WithUserContext(context.Background(), token)
+	repo.lookups = 0
+	for range 3 {
+		event := newTestEvent(streaming.QuizSessionPaused, streaming.QuizSessionPausedPayload{QuizID: uuid.New(), SessionID: own})
+		event.UserID, event.SchoolID = student, school
+		if _, err := p.authorize(ctx, &event); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if repo.lookups != 1 {
+		t.Errorf("looked up the session %d times, want 1", repo.lookups)
+	}
+}
@@ -0,0 +1,2914 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v5.29.3
+// source: services/ingestion/ingestionpb/ingestion.proto
+
+// The ingestion API over gRPC. It mirrors the HTTP endpoints under /events:
+// the same events are accepted, with the same validation, and the same JWT is
+// expected, as "authorization: Bearer <token>" metadata.
+//
+// Regenerate the Go code with make proto after changing this file.
+
+package ingestionpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type EventStatus int32
+
+const (
+	EventStatus_EVENT_STATUS_UNSPECIFIED EventStatus = 0
+	EventStatus_EVENT_STATUS_ACCEPTED    EventStatus = 1
+	// Accepted earlier, not processed again.
+	EventStatus_EVENT_STATUS_DUPLICATE EventStatus = 2
+	EventStatus_EVENT_STATUS_REJECTED  EventStatus = 3
+)
+
+// Enum value maps for EventStatus.
+var (
+	EventStatus_name = map[int32]string{
+		0: "EVENT_STATUS_UNSPECIFIED",
+		1: "EVENT_STATUS_ACCEPTED",
+		2: "EVENT_STATUS_DUPLICATE",
+		3: "EVENT_STATUS_REJECTED",
+	}
+	EventStatus_value = map[string]int32{
+		"EVENT_STATUS_UNSPECIFIED": 0,
+		"EVENT_STATUS_ACCEPTED":    1,
+		"EVENT_STATUS_DUPLICATE":   2,
+		"EVENT_STATUS_REJECTED":    3,
+	}
+)
+
+func (x EventStatus) Enum() *EventStatus {
+	p := new(EventStatus)
+	*p = x
+	return p
+}
+
+func (x EventStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (EventStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_services_ingestion_ingestionpb_ingestion_proto_enumTypes[0].Descriptor()
+}
+
+func (EventStatus) Type() protoreflect.EnumType {
+	return &file_services_ingestion_ingestionpb_ingestion_proto_enumTypes[0]
+}
+
+func (x EventStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use EventStatus.Descriptor instead.
+func (EventStatus) EnumDescriptor() ([]byte, []int) {
+	return file_services_ingestion_ingestionpb_ingestion_proto_rawDescGZIP(), []int{0}
+}
+
+type AppType int32
+
+const (
+	AppType_APP_TYPE_UNSPECIFIED AppType = 0
+	AppType_APP_TYPE_WHITEBOARD  AppType = 1
+	AppType_APP_TYPE_NOTEBOOK    AppType = 2
+)
+
+// Enum value maps for AppType.
+var (
+	AppType_name = map[int32]string{
+		0: "APP_TYPE_UNSPECIFIED",
+		1: "APP_TYPE_WHITEBOARD",
+		2: "APP_TYPE_NOTEBOOK",
+	}
+	AppType_value = map[string]int32{
+		"APP_TYPE_UNSPECIFIED": 0,
+		"APP_TYPE_WHITEBOARD":  1,
+		"APP_TYPE_NOTEBOOK":    2,
+	}
+)
+
+func (x AppType) Enum() *AppType {
+	p := new(AppType)
+	*p = x
+	return p
+}
+
+func (x AppType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (AppType) Descriptor() protoreflect.EnumDescriptor {
+	return file_services_ingestion_ingestionpb_ingestion_proto_enumTypes[1].Descriptor()
+}
+
+func (AppType) Type() protoreflect.EnumType {
+	return &file_services_ingestion_ingestionpb_ingestion_proto_enumTypes[1]
+}
+
+func (x AppType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use AppType.Descriptor instead.
+func (AppType) EnumDescriptor() ([]byte, []int) {
+	return file_services_ingestion_ingestionpb_ingestion_proto_rawDescGZIP(), []int{1}
+}
+
+type SendBatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Events        []*Event               `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SendBatchRequest) Reset() {
+	*x = SendBatchRequest{}
+	mi := &file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendBatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendBatchRequest) ProtoMessage() {}
+
+func (x *SendBatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendBatchRequest.ProtoReflect.Descriptor instead.
+func (*SendBatchRequest) Descriptor() ([]byte, []int) {
+	return file_services_ingestion_ingestionpb_ingestion_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SendBatchRequest) GetEvents() []*Event {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+type SendEventRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Event         *Event                 `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SendEventRequest) Reset() {
+	*x = SendEventRequest{}
+	mi := &file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendEventRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendEventRequest) ProtoMessage() {}
+
+func (x *SendEventRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendEventRequest.ProtoReflect.Descriptor instead.
+func (*SendEventRequest) Descriptor() ([]byte, []int) {
+	return file_services_ingestion_ingestionpb_ingestion_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SendEventRequest) GetEvent() *Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+type UploadEventsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Events        []*Event               `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadEventsRequest) Reset() {
+	*x = UploadEventsRequest{}
+	mi := &file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadEventsRequest) ProtoMessage() {}
+
+func (x *UploadEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadEventsRequest.ProtoReflect.Descriptor instead.
+func (*UploadEventsRequest) Descriptor() ([]byte, []int) {
+	return file_services_ingestion_ingestionpb_ingestion_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *UploadEventsRequest) GetEvents() []*Event {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+type SendBatchResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// success, partial_success or error.
+	Status   string   `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	EventIds []string `protobuf:"bytes,2,rep,name=event_ids,json=eventIds,proto3" json:"event_ids,omitempty"`
+	// Accepted earlier, not processed again.
+	DuplicateEventIds []string `protobuf:"bytes,3,rep,name=duplicate_event_ids,json=duplicateEventIds,proto3" json:"duplicate_event_ids,omitempty"`
+	Processed         int32    `protobuf:"varint,4,opt,name=processed,proto3" json:"processed,omitempty"`
+	Rejected          int32    `protobuf:"varint,5,opt,name=rejected,proto3" json:"rejected,omitempty"`
+	// One per event, in request order.
+	Results       []*EventResult         `protobuf:"bytes,6,rep,name=results,proto3" json:"results,omitempty"`
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SendBatchResponse) Reset() {
+	*x = SendBatchResponse{}
+	mi := &file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendBatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendBatchResponse) ProtoMessage() {}
+
+func (x *SendBatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendBatchResponse.ProtoReflect.Descriptor instead.
+func (*SendBatchResponse) Descriptor() ([]byte, []int) {
+	return file_services_ingestion_ingestionpb_ingestion_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *SendBatchResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *SendBatchResponse) GetEventIds() []string {
+	if x != nil {
+		return x.EventIds
+	}
+	return nil
+}
+
+func (x *SendBatchResponse) GetDuplicateEventIds() []string {
+	if x != nil {
+		return x.DuplicateEventIds
+	}
+	return nil
+}
+
+func (x *SendBatchResponse) GetProcessed() int32 {
+	if x != nil {
+		return x.Processed
+	}
+	return 0
+}
+
+func (x *SendBatchResponse) GetRejected() int32 {
+	if x != nil {
+		return x.Rejected
+	}
+	return 0
+}
+
+func (x *SendBatchResponse) GetResults() []*EventResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+func (x *SendBatchResponse) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+type SendEventResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	EventId       string                 `protobuf:"bytes,1,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	Status        EventStatus            `protobuf:"varint,2,opt,name=status,proto3,enum=dashbeam.ingestion.v1.EventStatus" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SendEventResponse) Reset() {
+	*x = SendEventResponse{}
+	mi := &file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendEventResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendEventResponse) ProtoMessage() {}
+
+func (x *SendEventResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendEventResponse.ProtoReflect.Descriptor instead.
+func (*SendEventResponse) Descriptor() ([]byte, []int) {
+	return file_services_ingestion_ingestionpb_ingestion_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *SendEventResponse) GetEventId() string {
+	if x != nil {
+		return x.EventId
+	}
+	return ""
+}
+
+func (x *SendEventResponse) GetStatus() EventStatus {
+	if x != nil {
+		return x.Status
+	}
+	return EventStatus_EVENT_STATUS_UNSPECIFIED
+}
+
+// EventResult is the outcome for one event. Accepted and duplicate events can
+// be dropped from the client's offline buffer; rejected ones with a
+// validation or FORBIDDEN error code will never be accepted as sent, others
+// may be retried.
+type EventResult struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Index   int32                  `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	EventId string                 `protobuf:"bytes,2,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	Status  EventStatus            `protobuf:"varint,3,opt,name=status,proto3,enum=dashbeam.ingestion.v1.EventStatus" json:"status,omitempty"`
+	// Identity fields replaced with the token's.
+	RewrittenFields []string `protobuf:"bytes,4,rep,name=rewritten_fields,json=rewrittenFields,proto3" json:"rewritten_fields,omitempty"`
+	ErrorCode       string   `protobuf:"bytes,5,opt,name=error_code,json=errorCode,proto3" json:"error_code,omitempty"`
+	Error           string   `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
+	// For RATE_LIMITED.
+	RetryAfterSeconds int32 `protobuf:"varint,7,opt,name=retry_after_seconds,json=retryAfterSeconds,proto3" json:"retry_after_seconds,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *EventResult) Reset() {
+	*x = EventResult{}
+	mi := &file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EventResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EventResult) ProtoMessage() {}
+
+func (x *EventResult) ProtoReflect() protoreflect.Message {
+	mi := &file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EventResult.ProtoReflect.Descriptor instead.
+func (*EventResult) Descriptor() ([]byte, []int) {
+	return file_services_ingestion_ingestionpb_ingestion_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *EventResult) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *EventResult) GetEventId() string {
+	if x != nil {
+		return x.EventId
+	}
+	return ""
+}
+
+func (x *EventResult) GetStatus() EventStatus {
+	if x != nil {
+		return x.Status
+	}
+	return EventStatus_EVENT_STATUS_UNSPECIFIED
+}
+
+func (x *EventResult) GetRewrittenFields() []string {
+	if x != nil {
+		return x.RewrittenFields
+	}
+	return nil
+}
+
+func (x *EventResult) GetErrorCode() string {
+	if x != nil {
+		return x.ErrorCode
+	}
+	return ""
+}
+
+func (x *EventResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *EventResult) GetRetryAfterSeconds() int32 {
+	if x != nil {
+		return x.RetryAfterSeconds
+	}
+	return 0
+}
+
+// Event is the envelope of an analytics event. The payload field set is the
+// event type: the field name with dots for underscores, e.g.
+// quiz_session_started is quiz.session.started. Payload field names are those
+// of the JSON payloads.
+type Event struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Assigned by the server when empty.
+	EventId string `protobuf:"bytes,1,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	// Set to the time received when missing.
+	Timestamp   *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	UserId      string                 `protobuf:"bytes,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	SchoolId    string                 `protobuf:"bytes,4,opt,name=school_id,json=schoolId,proto3" json:"school_id,omitempty"`
+	ClassroomId *string                `protobuf:"bytes,5,opt,name=classroom_id,json=classroomId,proto3,oneof" json:"classroom_id,omitempty"`
+	AppType     AppType                `protobuf:"varint,6,opt,name=app_type,json=appType,proto3,enum=dashbeam.ingestion.v1.AppType" json:"app_type,omitempty"`
+	Metadata    *Metadata              `protobuf:"bytes,7,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	// Types that are valid to be assigned to Payload:
+	//
+	//	*Event_QuizSessionStarted
+	//	*Event_QuizQuestionShown
+	//	*Event_QuizAnswerSubmitted
+	//	*Event_QuizSessionCompleted
+	//	*Event_QuizSessionAbandoned
+	//	*Event_QuizSessionPaused
+	//	*Event_QuizSessionResumed
+	//	*Event_UserLogin
+	//	*Event_UserLogout
+	//	*Event_AppInteraction
+	//	*Event_AppNavigation
+	//	*Event_AppFocusChange
+	//	*Event_AppBackground
+	//	*Event_AppForeground
+	//	*Event_ApiRequest
+	//	*Event_ApiResponse
+	//	*Event_ErrorOccurred
+	//	*Event_SystemStartup
+	//	*Event_SystemShutdown
+	Payload       isEvent_Payload `protobuf_oneof:"payload"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	mi := &file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_services_ingestion_ingestionpb_ingestion_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Event) GetEventId() string {
+	if x != nil {
+		return x.EventId
+	}
+	return ""
+}
+
+func (x *Event) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *Event) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *Event) GetSchoolId() string {
+	if x != nil {
+		return x.SchoolId
+	}
+	return ""
+}
+
+func (x *Event) GetClassroomId() string {
+	if x != nil && x.ClassroomId != nil {
+		return *x.ClassroomId
+	}
+	return ""
+}
+
+func (x *Event) GetAppType() AppType {
+	if x != nil {
+		return x.AppType
+	}
+	return AppType_APP_TYPE_UNSPECIFIED
+}
+
+func (x *Event) GetMetadata() *Metadata {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *Event) GetPayload() isEvent_Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *Event) GetQuizSessionStarted() *QuizSessionStarted {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_QuizSessionStarted); ok {
+			return x.QuizSessionStarted
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetQuizQuestionShown() *QuizQuestionShown {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_QuizQuestionShown); ok {
+			return x.QuizQuestionShown
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetQuizAnswerSubmitted() *QuizAnswerSubmitted {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_QuizAnswerSubmitted); ok {
+			return x.QuizAnswerSubmitted
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetQuizSessionCompleted() *QuizSessionCompleted {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_QuizSessionCompleted); ok {
+			return x.QuizSessionCompleted
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetQuizSessionAbandoned() *QuizSessionAbandoned {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_QuizSessionAbandoned); ok {
+			return x.QuizSessionAbandoned
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetQuizSessionPaused() *QuizSessionPaused {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_QuizSessionPaused); ok {
+			return x.QuizSessionPaused
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetQuizSessionResumed() *QuizSessionResumed {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_QuizSessionResumed); ok {
+			return x.QuizSessionResumed
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetUserLogin() *UserLogin {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_UserLogin); ok {
+			return x.UserLogin
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetUserLogout() *UserLogout {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_UserLogout); ok {
+			return x.UserLogout
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetAppInteraction() *AppInteraction {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_AppInteraction); ok {
+			return x.AppInteraction
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetAppNavigation() *AppNavigation {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_AppNavigation); ok {
+			return x.AppNavigation
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetAppFocusChange() *AppFocusChange {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_AppFocusChange); ok {
+			return x.AppFocusChange
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetAppBackground() *AppBackground {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_AppBackground); ok {
+			return x.AppBackground
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetAppForeground() *AppForeground {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_AppForeground); ok {
+			return x.AppForeground
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetApiRequest() *ApiRequest {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_ApiRequest); ok {
+			return x.ApiRequest
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetApiResponse() *ApiResponse {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_ApiResponse); ok {
+			return x.ApiResponse
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetErrorOccurred() *ErrorOccurred {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_ErrorOccurred); ok {
+			return x.ErrorOccurred
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetSystemStartup() *SystemStartup {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_SystemStartup); ok {
+			return x.SystemStartup
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetSystemShutdown() *SystemShutdown {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_SystemShutdown); ok {
+			return x.SystemShutdown
+		}
+	}
+	return nil
+}
+
+type isEvent_Payload interface {
+	isEvent_Payload()
+}
+
+type Event_QuizSessionStarted struct {
+	QuizSessionStarted *QuizSessionStarted `protobuf:"bytes,20,opt,name=quiz_session_started,json=quizSessionStarted,proto3,oneof"`
+}
+
+type Event_QuizQuestionShown struct {
+	QuizQuestionShown *QuizQuestionShown `protobuf:"bytes,21,opt,name=quiz_question_shown,json=quizQuestionShown,proto3,oneof"`
+}
+
+type Event_QuizAnswerSubmitted struct {
+	QuizAnswerSubmitted *QuizAnswerSubmitted `protobuf:"bytes,22,opt,name=quiz_answer_submitted,json=quizAnswerSubmitted,proto3,oneof"`
+}
+
+type Event_QuizSessionCompleted struct {
+	QuizSessionCompleted *QuizSessionCompleted `protobuf:"bytes,23,opt,name=quiz_session_completed,json=quizSessionCompleted,proto3,oneof"`
+}
+
+type Event_QuizSessionAbandoned struct {
+	QuizSessionAbandoned *QuizSessionAbandoned `protobuf:"bytes,24,opt,name=quiz_session_abandoned,json=quizSessionAbandoned,proto3,oneof"`
+}
+
+type Event_QuizSessionPaused struct {
+	QuizSessionPaused *QuizSessionPaused `protobuf:"bytes,25,opt,name=quiz_session_paused,json=quizSessionPaused,proto3,oneof"`
+}
+
+type Event_QuizSessionResumed struct {
+	QuizSessionResumed *QuizSessionResumed `protobuf:"bytes,26,opt,name=quiz_session_resumed,json=quizSessionResumed,proto3,oneof"`
+}
+
+type Event_UserLogin struct {
+	UserLogin *UserLogin `protobuf:"bytes,40,opt,name=user_login,json=userLogin,proto3,oneof"`
+}
+
+type Event_UserLogout struct {
+	UserLogout *UserLogout `protobuf:"bytes,41,opt,name=user_logout,json=userLogout,proto3,oneof"`
+}
+
+type Event_AppInteraction struct {
+	AppInteraction *AppInteraction `protobuf:"bytes,42,opt,name=app_interaction,json=appInteraction,proto3,oneof"`
+}
+
+type Event_AppNavigation struct {
+	AppNavigation *AppNavigation `protobuf:"bytes,43,opt,name=app_navigation,json=appNavigation,proto3,oneof"`
+}
+
+type Event_AppFocusChange struct {
+	AppFocusChange *AppFocusChange `protobuf:"bytes,44,opt,name=app_focus_change,json=appFocusChange,proto3,oneof"`
+}
+
+type Event_AppBackground struct {
+	AppBackground *AppBackground `protobuf:"bytes,45,opt,name=app_background,json=appBackground,proto3,oneof"`
+}
+
+type Event_AppForeground struct {
+	AppForeground *AppForeground `protobuf:"bytes,46,opt,name=app_foreground,json=appForeground,proto3,oneof"`
+}
+
+type Event_ApiRequest struct {
+	ApiRequest *ApiRequest `protobuf:"bytes,60,opt,name=api_request,json=apiRequest,proto3,oneof"`
+}
+
+type Event_ApiResponse struct {
+	ApiResponse *ApiResponse `protobuf:"bytes,61,opt,name=api_response,json=apiResponse,proto3,oneof"`
+}
+
+type Event_ErrorOccurred struct {
+	ErrorOccurred *ErrorOccurred `protobuf:"bytes,62,opt,name=error_occurred,json=errorOccurred,proto3,oneof"`
+}
+
+type Event_SystemStartup struct {
+	SystemStartup *SystemStartup `protobuf:"bytes,63,opt,name=system_startup,json=systemStartup,proto3,oneof"`
+}
+
+type Event_SystemShutdown struct {
+	SystemShutdown *SystemShutdown `protobuf:"bytes,64,opt,name=system_shutdown,json=systemShutdown,proto3,oneof"`
+}
+
+func (*Event_QuizSessionStarted) isEvent_Payload() {}
+
+func (*Event_QuizQuestionShown) isEvent_Payload() {}
+
+func (*Event_QuizAnswerSubmitted) isEvent_Payload() {}
+
+func (*Event_QuizSessionCompleted) isEvent_Payload() {}
+
+func (*Event_QuizSessionAbandoned) isEvent_Payload() {}
+
+func (*Event_QuizSessionPaused) isEvent_Payload() {}
+
+func (*Event_QuizSessionResumed) isEvent_Payload() {}
+
+func (*Event_UserLogin) isEvent_Payload() {}
+
+func (*Event_UserLogout) isEvent_Payload() {}
+
+func (*Event_AppInteraction) isEvent_Payload() {}
+
+func (*Event_AppNavigation) isEvent_Payload() {}
+
+func (*Event_AppFocusChange) isEvent_Payload() {}
+
+func (*Event_AppBackground) isEvent_Payload() {}
+
+func (*Event_AppForeground) isEvent_Payload() {}
+
+func (*Event_ApiRequest) isEvent_Payload() {}
+
+func (*Event_ApiResponse) isEvent_Payload() {}
+
+func (*Event_ErrorOccurred) isEvent_Payload() {}
+
+func (*Event_SystemStartup) isEvent_Payload() {}
+
+func (*Event_SystemShutdown) isEvent_Payload() {}
+
+type Metadata struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	AppVersion  string                 `protobuf:"bytes,1,opt,name=app_version,json=appVersion,proto3" json:"app_version,omitempty"`
+	DeviceType  string                 `protobuf:"bytes,2,opt,name=device_type,json=deviceType,proto3" json:"device_type,omitempty"`
+	DeviceId    string                 `protobuf:"bytes,3,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	NetworkType *string                `protobuf:"bytes,4,opt,name=network_type,json=networkType,proto3,oneof" json:"network_type,omitempty"`
+	// Device clock when the event was sent, RFC 3339.
+	ClientTime    *string `protobuf:"bytes,5,opt,name=client_time,json=clientTime,proto3,oneof" json:"client_time,omitempty"`
+	SessionId     *string `protobuf:"bytes,6,opt,name=session_id,json=sessionId,proto3,oneof" json:"session_id,omitempty"`
+	IpAddress     *string `protobuf:"bytes,7,opt,name=ip_address,json=ipAddress,proto3,oneof" json:"ip_address,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Metadata) Reset() {
+	*x = Metadata{}
+	mi := &file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Metadata) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Metadata) ProtoMessage() {}
+
+func (x *Metadata) ProtoReflect() protoreflect.Message {
+	mi := &file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Metadata.ProtoReflect.Descriptor instead.
+func (*Metadata) Descriptor() ([]byte, []int) {
+	return file_services_ingestion_ingestionpb_ingestion_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Metadata) GetAppVersion() string {
+	if x != nil {
+		return x.AppVersion
+	}
+	return ""
+}
+
+func (x *Metadata) GetDeviceType() string {
+	if x != nil {
+		return x.DeviceType
+	}
+	return ""
+}
+
+func (x *Metadata) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *Metadata) GetNetworkType() string {
+	if x != nil && x.NetworkType != nil {
+		return *x.NetworkType
+	}
+	return ""
+}
+
+func (x *Metadata) GetClientTime() string {
+	if x != nil && x.ClientTime != nil {
+		return *x.ClientTime
+	}
+	return ""
+}
+
+func (x *Metadata) GetSessionId() string {
+	if x != nil && x.SessionId != nil {
+		return *x.SessionId
+	}
+	return ""
+}
+
+func (x *Metadata) GetIpAddress() string {
+	if x != nil && x.IpAddress != nil {
+		return *x.IpAddress
+	}
+	return ""
+}
+
+type QuizSessionStarted struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	QuizId           string                 `protobuf:"bytes,1,opt,name=quiz_id,json=quizId,proto3" json:"quiz_id,omitempty"`
+	SessionId        string                 `protobuf:"bytes,2,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	SessionCode      string                 `protobuf:"bytes,3,opt,name=session_code,json=sessionCode,proto3" json:"session_code,omitempty"`
+	TotalQuestions   int32                  `protobuf:"varint,4,opt,name=total_questions,json=totalQuestions,proto3" json:"total_questions,omitempty"`
+	TimeLimitSeconds *int32                 `protobuf:"varint,5,opt,name=time_limit_seconds,json=timeLimitSeconds,proto3,oneof" json:"time_limit_seconds,omitempty"`
+	MaxScore         float64                `protobuf:"fixed64,6,opt,name=max_score,json=maxScore,proto3" json:"max_score,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *QuizSessionStarted) Reset() {
+	*x = QuizSessionStarted{}
+	mi := &file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QuizSessionStarted) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QuizSessionStarted) ProtoMessage() {}
+
+func (x *QuizSessionStarted) ProtoReflect() protoreflect.Message {
+	mi := &file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QuizSessionStarted.ProtoReflect.Descriptor instead.
+func (*QuizSessionStarted) Descriptor() ([]byte, []int) {
+	return file_services_ingestion_ingestionpb_ingestion_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *QuizSessionStarted) GetQuizId() string {
+	if x != nil {
+		return x.QuizId
+	}
+	return ""
+}
+
+func (x *QuizSessionStarted) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *QuizSessionStarted) GetSessionCode() string {
+	if x != nil {
+		return x.SessionCode
+	}
+	return ""
+}
+
+func (x *QuizSessionStarted) GetTotalQuestions() int32 {
+	if x != nil {
+		return x.TotalQuestions
+	}
+	return 0
+}
+
+func (x *QuizSessionStarted) GetTimeLimitSeconds() int32 {
+	if x != nil && x.TimeLimitSeconds != nil {
+		return *x.TimeLimitSeconds
+	}
+	return 0
+}
+
+func (x *QuizSessionStarted) GetMaxScore() float64 {
+	if x != nil {
+		return x.MaxScore
+	}
+	return 0
+}
+
+type QuizQuestionShown struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	QuizId           string                 `protobuf:"bytes,1,opt,name=quiz_id,json=quizId,proto3" json:"quiz_id,omitempty"`
+	SessionId        string                 `protobuf:"bytes,2,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	QuestionId       string                 `protobuf:"bytes,3,opt,name=question_id,json=questionId,proto3" json:"question_id,omitempty"`
+	QuestionSequence int32                  `protobuf:"varint,4,opt,name=question_sequence,json=questionSequence,proto3" json:"question_sequence,omitempty"`
+	QuestionType     string                 `protobuf:"bytes,5,opt,name=question_type,json=questionType,proto3" json:"question_type,omitempty"`
+	TimeLimitSeconds *int32                 `protobuf:"varint,6,opt,name=time_limit_seconds,json=timeLimitSeconds,proto3,oneof" json:"time_limit_seconds,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *QuizQuestionShown) Reset() {
+	*x = QuizQuestionShown{}
+	mi := &file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QuizQuestionShown) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QuizQuestionShown) ProtoMessage() {}
+
+func (x *QuizQuestionShown) ProtoReflect() protoreflect.Message {
+	mi := &file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QuizQuestionShown.ProtoReflect.Descriptor instead.
+func (*QuizQuestionShown) Descriptor() ([]byte, []int) {
+	return file_services_ingestion_ingestionpb_ingestion_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *QuizQuestionShown) GetQuizId() string {
+	if x != nil {
+		return x.QuizId
+	}
+	return ""
+}
+
+func (x *QuizQuestionShown) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *QuizQuestionShown) GetQuestionId() string {
+	if x != nil {
+		return x.QuestionId
+	}
+	return ""
+}
+
+func (x *QuizQuestionShown) GetQuestionSequence() int32 {
+	if x != nil {
+		return x.QuestionSequence
+	}
+	return 0
+}
+
+func (x *QuizQuestionShown) GetQuestionType() string {
+	if x != nil {
+		return x.QuestionType
+	}
+	return ""
+}
+
+func (x *QuizQuestionShown) GetTimeLimitSeconds() int32 {
+	if x != nil && x.TimeLimitSeconds != nil {
+		return *x.TimeLimitSeconds
+	}
+	return 0
+}
+
+type QuizAnswerSubmitted struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	QuizId           string                 `protobuf:"bytes,1,opt,name=quiz_id,json=quizId,proto3" json:"quiz_id,omitempty"`
+	SessionId        string                 `protobuf:"bytes,2,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	QuestionId       string                 `protobuf:"bytes,3,opt,name=question_id,json=questionId,proto3" json:"question_id,omitempty"`
+	QuestionSequence int32                  `protobuf:"varint,4,opt,name=question_sequence,json=questionSequence,proto3" json:"question_sequence,omitempty"`
+	Answer           *structpb.Value        `protobuf:"bytes,5,opt,name=answer,proto3" json:"answer,omitempty"`
+	IsCorrect        *bool                  `protobuf:"varint,6,opt,name=is_correct,json=isCorrect,proto3,oneof" json:"is_correct,omitempty"`
+	ResponseTimeMs   int32                  `protobuf:"varint,7,opt,name=response_time_ms,json=responseTimeMs,proto3" json:"response_time_ms,omitempty"`
+	AnswerChanges    *int32                 `protobuf:"varint,8,opt,name=answer_changes,json=answerChanges,proto3,oneof" json:"answer_changes,omitempty"`
+	Points           *float64               `protobuf:"fixed64,9,opt,name=points,proto3,oneof" json:"points,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *QuizAnswerSubmitted) Reset() {
+	*x = QuizAnswerSubmitted{}
+	mi := &file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QuizAnswerSubmitted) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QuizAnswerSubmitted) ProtoMessage() {}
+
+func (x *QuizAnswerSubmitted) ProtoReflect() protoreflect.Message {
+	mi := &file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QuizAnswerSubmitted.ProtoReflect.Descriptor instead.
+func (*QuizAnswerSubmitted) Descriptor() ([]byte, []int) {
+	return file_services_ingestion_ingestionpb_ingestion_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *QuizAnswerSubmitted) GetQuizId() string {
+	if x != nil {
+		return x.QuizId
+	}
+	return ""
+}
+
+func (x *QuizAnswerSubmitted) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *QuizAnswerSubmitted) GetQuestionId() string {
+	if x != nil {
+		return x.QuestionId
+	}
+	return ""
+}
+
+func (x *QuizAnswerSubmitted) GetQuestionSequence() int32 {
+	if x != nil {
+		return x.QuestionSequence
+	}
+	return 0
+}
+
+func (x *QuizAnswerSubmitted) GetAnswer() *structpb.Value {
+	if x != nil {
+		return x.Answer
+	}
+	return nil
+}
+
+func (x *QuizAnswerSubmitted) GetIsCorrect() bool {
+	if x != nil && x.IsCorrect != nil {
+		return *x.IsCorrect
+	}
+	return false
+}
+
+func (x *QuizAnswerSubmitted) GetResponseTimeMs() int32 {
+	if x != nil {
+		return x.ResponseTimeMs
+	}
+	return 0
+}
+
+func (x *QuizAnswerSubmitted) GetAnswerChanges() int32 {
+	if x != nil && x.AnswerChanges != nil {
+		return *x.AnswerChanges
+	}
+	return 0
+}
+
+func (x *QuizAnswerSubmitted) GetPoints() float64 {
+	if x != nil && x.Points != nil {
+		return *x.Points
+	}
+	return 0
+}
+
+type QuizSessionCompleted struct {
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	QuizId                string                 `protobuf:"bytes,1,opt,name=quiz_id,json=quizId,proto3" json:"quiz_id,omitempty"`
+	SessionId             string                 `protobuf:"bytes,2,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	TotalScore            float64                `protobuf:"fixed64,3,opt,name=total_score,json=totalScore,proto3" json:"total_score,omitempty"`
+	MaxScore              float64                `protobuf:"fixed64,4,opt,name=max_score,json=maxScore,proto3" json:"max_score,omitempty"`
+	CompletionTimeMs      int32                  `protobuf:"varint,5,opt,name=completion_time_ms,json=completionTimeMs,proto3" json:"completion_time_ms,omitempty"`
+	QuestionsCorrect      int32                  `protobuf:"varint,6,opt,name=questions_correct,json=questionsCorrect,proto3" json:"questions_correct,omitempty"`
+	QuestionsAnswered     int32                  `protobuf:"varint,7,opt,name=questions_answered,json=questionsAnswered,proto3" json:"questions_answered,omitempty"`
+	QuestionsSkipped      int32                  `protobuf:"varint,8,opt,name=questions_skipped,json=questionsSkipped,proto3" json:"questions_skipped,omitempty"`
+	AverageResponseTimeMs int32                  `protobuf:"varint,9,opt,name=average_response_time_ms,json=averageResponseTimeMs,proto3" json:"average_response_time_ms,omitempty"`
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *QuizSessionCompleted) Reset() {
+	*x = QuizSessionCompleted{}
+	mi := &file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QuizSessionCompleted) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QuizSessionCompleted) ProtoMessage() {}
+
+func (x *QuizSessionCompleted) ProtoReflect() protoreflect.Message {
+	mi := &file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QuizSessionCompleted.ProtoReflect.Descriptor instead.
+func (*QuizSessionCompleted) Descriptor() ([]byte, []int) {
+	return file_services_ingestion_ingestionpb_ingestion_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *QuizSessionCompleted) GetQuizId() string {
+	if x != nil {
+		return x.QuizId
+	}
+	return ""
+}
+
+func (x *QuizSessionCompleted) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *QuizSessionCompleted) GetTotalScore() float64 {
+	if x != nil {
+		return x.TotalScore
+	}
+	return 0
+}
+
+func (x *QuizSessionCompleted) GetMaxScore() float64 {
+	if x != nil {
+		return x.MaxScore
+	}
+	return 0
+}
+
+func (x *QuizSessionCompleted) GetCompletionTimeMs() int32 {
+	if x != nil {
+		return x.CompletionTimeMs
+	}
+	return 0
+}
+
+func (x *QuizSessionCompleted) GetQuestionsCorrect() int32 {
+	if x != nil {
+		return x.QuestionsCorrect
+	}
+	return 0
+}
+
+func (x *QuizSessionCompleted) GetQuestionsAnswered() int32 {
+	if x != nil {
+		return x.QuestionsAnswered
+	}
+	return 0
+}
+
+func (x *QuizSessionCompleted) GetQuestionsSkipped() int32 {
+	if x != nil {
+		return x.QuestionsSkipped
+	}
+	return 0
+}
+
+func (x *QuizSessionCompleted) GetAverageResponseTimeMs() int32 {
+	if x != nil {
+		return x.AverageResponseTimeMs
+	}
+	return 0
+}
+
+type QuizSessionAbandoned struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	QuizId            string                 `protobuf:"bytes,1,opt,name=quiz_id,json=quizId,proto3" json:"quiz_id,omitempty"`
+	SessionId         string                 `protobuf:"bytes,2,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	QuestionsAnswered int32                  `protobuf:"varint,3,opt,name=questions_answered,json=questionsAnswered,proto3" json:"questions_answered,omitempty"`
+	TimeSpentMs       int32                  `protobuf:"varint,4,opt,name=time_spent_ms,json=timeSpentMs,proto3" json:"time_spent_ms,omitempty"`
+	// e.g. closed, timeout, navigated_away
+	Reason        string `protobuf:"bytes,5,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QuizSessionAbandoned) Reset() {
+	*x = QuizSessionAbandoned{}
+	mi := &file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QuizSessionAbandoned) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QuizSessionAbandoned) ProtoMessage() {}
+
+func (x *QuizSessionAbandoned) ProtoReflect() protoreflect.Message {
+	mi := &file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QuizSessionAbandoned.ProtoReflect.Descriptor instead.
+func (*QuizSessionAbandoned) Descriptor() ([]byte, []int) {
+	return file_services_ingestion_ingestionpb_ingestion_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *QuizSessionAbandoned) GetQuizId() string {
+	if x != nil {
+		return x.QuizId
+	}
+	return ""
+}
+
+func (x *QuizSessionAbandoned) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *QuizSessionAbandoned) GetQuestionsAnswered() int32 {
+	if x != nil {
+		return x.QuestionsAnswered
+	}
+	return 0
+}
+
+func (x *QuizSessionAbandoned) GetTimeSpentMs() int32 {
+	if x != nil {
+		return x.TimeSpentMs
+	}
+	return 0
+}
+
+func (x *QuizSessionAbandoned) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type QuizSessionPaused struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	QuizId           string                 `protobuf:"bytes,1,opt,name=quiz_id,json=quizId,proto3" json:"quiz_id,omitempty"`
+	SessionId        string                 `protobuf:"bytes,2,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	QuestionSequence *int32                 `protobuf:"varint,3,opt,name=question_sequence,json=questionSequence,proto3,oneof" json:"question_sequence,omitempty"`
+	// e.g. manual, teacher, app_background
+	Reason        string `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QuizSessionPaused) Reset() {
+	*x = QuizSessionPaused{}
+	mi := &file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QuizSessionPaused) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QuizSessionPaused) ProtoMessage() {}
+
+func (x *QuizSessionPaused) ProtoReflect() protoreflect.Message {
+	mi := &file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QuizSessionPaused.ProtoReflect.Descriptor instead.
+func (*QuizSessionPaused) Descriptor() ([]byte, []int) {
+	return file_services_ingestion_ingestionpb_ingestion_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *QuizSessionPaused) GetQuizId() string {
+	if x != nil {
+		return x.QuizId
+	}
+	return ""
+}
+
+func (x *QuizSessionPaused) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *QuizSessionPaused) GetQuestionSequence() int32 {
+	if x != nil && x.QuestionSequence != nil {
+		return *x.QuestionSequence
+	}
+	return 0
+}
+
+func (x *QuizSessionPaused) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type QuizSessionResumed struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	QuizId           string                 `protobuf:"bytes,1,opt,name=quiz_id,json=quizId,proto3" json:"quiz_id,omitempty"`
+	SessionId        string                 `protobuf:"bytes,2,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	QuestionSequence *int32                 `protobuf:"varint,3,opt,name=question_sequence,json=questionSequence,proto3,oneof" json:"question_sequence,omitempty"`
+	PausedDurationMs int32                  `protobuf:"varint,4,opt,name=paused_duration_ms,json=pausedDurationMs,proto3" json:"paused_duration_ms,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *QuizSessionResumed) Reset() {
+	*x = QuizSessionResumed{}
+	mi := &file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QuizSessionResumed) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QuizSessionResumed) ProtoMessage() {}
+
+func (x *QuizSessionResumed) ProtoReflect() protoreflect.Message {
+	mi := &file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QuizSessionResumed.ProtoReflect.Descriptor instead.
+func (*QuizSessionResumed) Descriptor() ([]byte, []int) {
+	return file_services_ingestion_ingestionpb_ingestion_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *QuizSessionResumed) GetQuizId() string {
+	if x != nil {
+		return x.QuizId
+	}
+	return ""
+}
+
+func (x *QuizSessionResumed) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *QuizSessionResumed) GetQuestionSequence() int32 {
+	if x != nil && x.QuestionSequence != nil {
+		return *x.QuestionSequence
+	}
+	return 0
+}
+
+func (x *QuizSessionResumed) GetPausedDurationMs() int32 {
+	if x != nil {
+		return x.PausedDurationMs
+	}
+	return 0
+}
+
+type UserLogin struct {
+	state                  protoimpl.MessageState `protogen:"open.v1"`
+	LoginMethod            string                 `protobuf:"bytes,1,opt,name=login_method,json=loginMethod,proto3" json:"login_method,omitempty"`
+	SessionStart           bool                   `protobuf:"varint,2,opt,name=session_start,json=sessionStart,proto3" json:"session_start,omitempty"`
+	PreviousLoginTimestamp *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=previous_login_timestamp,json=previousLoginTimestamp,proto3" json:"previous_login_timestamp,omitempty"`
+	UserAgent              *string                `protobuf:"bytes,4,opt,name=user_agent,json=userAgent,proto3,oneof" json:"user_agent,omitempty"`
+	IpAddress              *string                `protobuf:"bytes,5,opt,name=ip_address,json=ipAddress,proto3,oneof" json:"ip_address,omitempty"`
+	Email                  *string                `protobuf:"bytes,6,opt,name=email,proto3,oneof" json:"email,omitempty"`
+	Name                   *string                `protobuf:"bytes,7,opt,name=name,proto3,oneof" json:"name,omitempty"`
+	Role                   *string                `protobuf:"bytes,8,opt,name=role,proto3,oneof" json:"role,omitempty"`
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *UserLogin) Reset() {
+	*x = UserLogin{}
+	mi := &file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UserLogin) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserLogin) ProtoMessage() {}
+
+func (x *UserLogin) ProtoReflect() protoreflect.Message {
+	mi := &file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserLogin.ProtoReflect.Descriptor instead.
+func (*UserLogin) Descriptor() ([]byte, []int) {
+	return file_services_ingestion_ingestionpb_ingestion_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *UserLogin) GetLoginMethod() string {
+	if x != nil {
+		return x.LoginMethod
+	}
+	return ""
+}
+
+func (x *UserLogin) GetSessionStart() bool {
+	if x != nil {
+		return x.SessionStart
+	}
+	return false
+}
+
+func (x *UserLogin) GetPreviousLoginTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.PreviousLoginTimestamp
+	}
+	return nil
+}
+
+func (x *UserLogin) GetUserAgent() string {
+	if x != nil && x.UserAgent != nil {
+		return *x.UserAgent
+	}
+	return ""
+}
+
+func (x *UserLogin) GetIpAddress() string {
+	if x != nil && x.IpAddress != nil {
+		return *x.IpAddress
+	}
+	return ""
+}
+
+func (x *UserLogin) GetEmail() string {
+	if x != nil && x.Email != nil {
+		return *x.Email
+	}
+	return ""
+}
+
+func (x *UserLogin) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *UserLogin) GetRole() string {
+	if x != nil && x.Role != nil {
+		return *x.Role
+	}
+	return ""
+}
+
+type UserLogout struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	SessionDurationMs int32                  `protobuf:"varint,1,opt,name=session_duration_ms,json=sessionDurationMs,proto3" json:"session_duration_ms,omitempty"`
+	// e.g. manual, timeout, forced
+	LogoutReason  string `protobuf:"bytes,2,opt,name=logout_reason,json=logoutReason,proto3" json:"logout_reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UserLogout) Reset() {
+	*x = UserLogout{}
+	mi := &file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UserLogout) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserLogout) ProtoMessage() {}
+
+func (x *UserLogout) ProtoReflect() protoreflect.Message {
+	mi := &file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserLogout.ProtoReflect.Descriptor instead.
+func (*UserLogout) Descriptor() ([]byte, []int) {
+	return file_services_ingestion_ingestionpb_ingestion_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *UserLogout) GetSessionDurationMs() int32 {
+	if x != nil {
+		return x.SessionDurationMs
+	}
+	return 0
+}
+
+func (x *UserLogout) GetLogoutReason() string {
+	if x != nil {
+		return x.LogoutReason
+	}
+	return ""
+}
+
+type AppInteraction struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	InteractionType string                 `protobuf:"bytes,1,opt,name=interaction_type,json=interactionType,proto3" json:"interaction_type,omitempty"`
+	ScreenName      string                 `protobuf:"bytes,2,opt,name=screen_name,json=screenName,proto3" json:"screen_name,omitempty"`
+	ElementClicked  *string                `protobuf:"bytes,3,opt,name=element_clicked,json=elementClicked,proto3,oneof" json:"element_clicked,omitempty"`
+	TimeSpentMs     *int32                 `protobuf:"varint,4,opt,name=time_spent_ms,json=timeSpentMs,proto3,oneof" json:"time_spent_ms,omitempty"`
+	ElementData     *string                `protobuf:"bytes,5,opt,name=element_data,json=elementData,proto3,oneof" json:"element_data,omitempty"`
+	// e.g. tap, swipe, long_press
+	Action        *string `protobuf:"bytes,6,opt,name=action,proto3,oneof" json:"action,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AppInteraction) Reset() {
+	*x = AppInteraction{}
+	mi := &file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AppInteraction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AppInteraction) ProtoMessage() {}
+
+func (x *AppInteraction) ProtoReflect() protoreflect.Message {
+	mi := &file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AppInteraction.ProtoReflect.Descriptor instead.
+func (*AppInteraction) Descriptor() ([]byte, []int) {
+	return file_services_ingestion_ingestionpb_ingestion_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *AppInteraction) GetInteractionType() string {
+	if x != nil {
+		return x.InteractionType
+	}
+	return ""
+}
+
+func (x *AppInteraction) GetScreenName() string {
+	if x != nil {
+		return x.ScreenName
+	}
+	return ""
+}
+
+func (x *AppInteraction) GetElementClicked() string {
+	if x != nil && x.ElementClicked != nil {
+		return *x.ElementClicked
+	}
+	return ""
+}
+
+func (x *AppInteraction) GetTimeSpentMs() int32 {
+	if x != nil && x.TimeSpentMs != nil {
+		return *x.TimeSpentMs
+	}
+	return 0
+}
+
+func (x *AppInteraction) GetElementData() string {
+	if x != nil && x.ElementData != nil {
+		return *x.ElementData
+	}
+	return ""
+}
+
+func (x *AppInteraction) GetAction() string {
+	if x != nil && x.Action != nil {
+		return *x.Action
+	}
+	return ""
+}
+
+type AppNavigation struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	FromScreen string                 `protobuf:"bytes,1,opt,name=from_screen,json=fromScreen,proto3" json:"from_screen,omitempty"`
+	ToScreen   string                 `protobuf:"bytes,2,opt,name=to_screen,json=toScreen,proto3" json:"to_screen,omitempty"`
+	// e.g. push, pop, replace
+	NavigationType string  `protobuf:"bytes,3,opt,name=navigation_type,json=navigationType,proto3" json:"navigation_type,omitempty"`
+	TimeSpentMs    *int32  `protobuf:"varint,4,opt,name=time_spent_ms,json=timeSpentMs,proto3,oneof" json:"time_spent_ms,omitempty"`
+	NavigationData *string `protobuf:"bytes,5,opt,name=navigation_data,json=navigationData,proto3,oneof" json:"navigation_data,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *AppNavigation) Reset() {
+	*x = AppNavigation{}
+	mi := &file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AppNavigation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AppNavigation) ProtoMessage() {}
+
+func (x *AppNavigation) ProtoReflect() protoreflect.Message {
+	mi := &file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AppNavigation.ProtoReflect.Descriptor instead.
+func (*AppNavigation) Descriptor() ([]byte, []int) {
+	return file_services_ingestion_ingestionpb_ingestion_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *AppNavigation) GetFromScreen() string {
+	if x != nil {
+		return x.FromScreen
+	}
+	return ""
+}
+
+func (x *AppNavigation) GetToScreen() string {
+	if x != nil {
+		return x.ToScreen
+	}
+	return ""
+}
+
+func (x *AppNavigation) GetNavigationType() string {
+	if x != nil {
+		return x.NavigationType
+	}
+	return ""
+}
+
+func (x *AppNavigation) GetTimeSpentMs() int32 {
+	if x != nil && x.TimeSpentMs != nil {
+		return *x.TimeSpentMs
+	}
+	return 0
+}
+
+func (x *AppNavigation) GetNavigationData() string {
+	if x != nil && x.NavigationData != nil {
+		return *x.NavigationData
+	}
+	return ""
+}
+
+type AppFocusChange struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	HasFocus   bool                   `protobuf:"varint,1,opt,name=has_focus,json=hasFocus,proto3" json:"has_focus,omitempty"`
+	ScreenName string                 `protobuf:"bytes,2,opt,name=screen_name,json=screenName,proto3" json:"screen_name,omitempty"`
+	// Set when focus changed during a quiz.
+	QuizSessionId *string `protobuf:"bytes,3,opt,name=quiz_session_id,json=quizSessionId,proto3,oneof" json:"quiz_session_id,omitempty"`
+	// Time spent in the previous focus state.
+	DurationMs    *int32 `protobuf:"varint,4,opt,name=duration_ms,json=durationMs,proto3,oneof" json:"duration_ms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AppFocusChange) Reset() {
+	*x = AppFocusChange{}
+	mi := &file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AppFocusChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AppFocusChange) ProtoMessage() {}
+
+func (x *AppFocusChange) ProtoReflect() protoreflect.Message {
+	mi := &file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AppFocusChange.ProtoReflect.Descriptor instead.
+func (*AppFocusChange) Descriptor() ([]byte, []int) {
+	return file_services_ingestion_ingestionpb_ingestion_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *AppFocusChange) GetHasFocus() bool {
+	if x != nil {
+		return x.HasFocus
+	}
+	return false
+}
+
+func (x *AppFocusChange) GetScreenName() string {
+	if x != nil {
+		return x.ScreenName
+	}
+	return ""
+}
+
+func (x *AppFocusChange) GetQuizSessionId() string {
+	if x != nil && x.QuizSessionId != nil {
+		return *x.QuizSessionId
+	}
+	return ""
+}
+
+func (x *AppFocusChange) GetDurationMs() int32 {
+	if x != nil && x.DurationMs != nil {
+		return *x.DurationMs
+	}
+	return 0
+}
+
+type AppBackground struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	ScreenName           string                 `protobuf:"bytes,1,opt,name=screen_name,json=screenName,proto3" json:"screen_name,omitempty"`
+	ForegroundDurationMs *int32                 `protobuf:"varint,2,opt,name=foreground_duration_ms,json=foregroundDurationMs,proto3,oneof" json:"foreground_duration_ms,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *AppBackground) Reset() {
+	*x = AppBackground{}
+	mi := &file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AppBackground) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AppBackground) ProtoMessage() {}
+
+func (x *AppBackground) ProtoReflect() protoreflect.Message {
+	mi := &file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AppBackground.ProtoReflect.Descriptor instead.
+func (*AppBackground) Descriptor() ([]byte, []int) {
+	return file_services_ingestion_ingestionpb_ingestion_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *AppBackground) GetScreenName() string {
+	if x != nil {
+		return x.ScreenName
+	}
+	return ""
+}
+
+func (x *AppBackground) GetForegroundDurationMs() int32 {
+	if x != nil && x.ForegroundDurationMs != nil {
+		return *x.ForegroundDurationMs
+	}
+	return 0
+}
+
+type AppForeground struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	ScreenName           string                 `protobuf:"bytes,1,opt,name=screen_name,json=screenName,proto3" json:"screen_name,omitempty"`
+	BackgroundDurationMs *int32                 `protobuf:"varint,2,opt,name=background_duration_ms,json=backgroundDurationMs,proto3,oneof" json:"background_duration_ms,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *AppForeground) Reset() {
+	*x = AppForeground{}
+	mi := &file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AppForeground) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AppForeground) ProtoMessage() {}
+
+func (x *AppForeground) ProtoReflect() protoreflect.Message {
+	mi := &file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AppForeground.ProtoReflect.Descriptor instead.
+func (*AppForeground) Descriptor() ([]byte, []int) {
+	return file_services_ingestion_ingestionpb_ingestion_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *AppForeground) GetScreenName() string {
+	if x != nil {
+		return x.ScreenName
+	}
+	return ""
+}
+
+func (x *AppForeground) GetBackgroundDurationMs() int32 {
+	if x != nil && x.BackgroundDurationMs != nil {
+		return *x.BackgroundDurationMs
+	}
+	return 0
+}
+
+type ApiRequest struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Method            string                 `protobuf:"bytes,1,opt,name=method,proto3" json:"method,omitempty"`
+	Endpoint          string                 `protobuf:"bytes,2,opt,name=endpoint,proto3" json:"endpoint,omitempty"`
+	StatusCode        *int32                 `protobuf:"varint,3,opt,name=status_code,json=statusCode,proto3,oneof" json:"status_code,omitempty"`
+	ResponseTimeMs    *int32                 `protobuf:"varint,4,opt,name=response_time_ms,json=responseTimeMs,proto3,oneof" json:"response_time_ms,omitempty"`
+	ErrorCode         *string                `protobuf:"bytes,5,opt,name=error_code,json=errorCode,proto3,oneof" json:"error_code,omitempty"`
+	RequestSizeBytes  *int32                 `protobuf:"varint,6,opt,name=request_size_bytes,json=requestSizeBytes,proto3,oneof" json:"request_size_bytes,omitempty"`
+	ResponseSizeBytes *int32                 `protobuf:"varint,7,opt,name=response_size_bytes,json=responseSizeBytes,proto3,oneof" json:"response_size_bytes,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *ApiRequest) Reset() {
+	*x = ApiRequest{}
+	mi := &file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApiRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApiRequest) ProtoMessage() {}
+
+func (x *ApiRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApiRequest.ProtoReflect.Descriptor instead.
+func (*ApiRequest) Descriptor() ([]byte, []int) {
+	return file_services_ingestion_ingestionpb_ingestion_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ApiRequest) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *ApiRequest) GetEndpoint() string {
+	if x != nil {
+		return x.Endpoint
+	}
+	return ""
+}
+
+func (x *ApiRequest) GetStatusCode() int32 {
+	if x != nil && x.StatusCode != nil {
+		return *x.StatusCode
+	}
+	return 0
+}
+
+func (x *ApiRequest) GetResponseTimeMs() int32 {
+	if x != nil && x.ResponseTimeMs != nil {
+		return *x.ResponseTimeMs
+	}
+	return 0
+}
+
+func (x *ApiRequest) GetErrorCode() string {
+	if x != nil && x.ErrorCode != nil {
+		return *x.ErrorCode
+	}
+	return ""
+}
+
+func (x *ApiRequest) GetRequestSizeBytes() int32 {
+	if x != nil && x.RequestSizeBytes != nil {
+		return *x.RequestSizeBytes
+	}
+	return 0
+}
+
+func (x *ApiRequest) GetResponseSizeBytes() int32 {
+	if x != nil && x.ResponseSizeBytes != nil {
+		return *x.ResponseSizeBytes
+	}
+	return 0
+}
+
+type ApiResponse struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Method            string                 `protobuf:"bytes,1,opt,name=method,proto3" json:"method,omitempty"`
+	Endpoint          string                 `protobuf:"bytes,2,opt,name=endpoint,proto3" json:"endpoint,omitempty"`
+	StatusCode        int32                  `protobuf:"varint,3,opt,name=status_code,json=statusCode,proto3" json:"status_code,omitempty"`
+	ResponseTimeMs    *int32                 `protobuf:"varint,4,opt,name=response_time_ms,json=responseTimeMs,proto3,oneof" json:"response_time_ms,omitempty"`
+	ErrorCode         *string                `protobuf:"bytes,5,opt,name=error_code,json=errorCode,proto3,oneof" json:"error_code,omitempty"`
+	ResponseSizeBytes *int32                 `protobuf:"varint,6,opt,name=response_size_bytes,json=responseSizeBytes,proto3,oneof" json:"response_size_bytes,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *ApiResponse) Reset() {
+	*x = ApiResponse{}
+	mi := &file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApiResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApiResponse) ProtoMessage() {}
+
+func (x *ApiResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApiResponse.ProtoReflect.Descriptor instead.
+func (*ApiResponse) Descriptor() ([]byte, []int) {
+	return file_services_ingestion_ingestionpb_ingestion_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *ApiResponse) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *ApiResponse) GetEndpoint() string {
+	if x != nil {
+		return x.Endpoint
+	}
+	return ""
+}
+
+func (x *ApiResponse) GetStatusCode() int32 {
+	if x != nil {
+		return x.StatusCode
+	}
+	return 0
+}
+
+func (x *ApiResponse) GetResponseTimeMs() int32 {
+	if x != nil && x.ResponseTimeMs != nil {
+		return *x.ResponseTimeMs
+	}
+	return 0
+}
+
+func (x *ApiResponse) GetErrorCode() string {
+	if x != nil && x.ErrorCode != nil {
+		return *x.ErrorCode
+	}
+	return ""
+}
+
+func (x *ApiResponse) GetResponseSizeBytes() int32 {
+	if x != nil && x.ResponseSizeBytes != nil {
+		return *x.ResponseSizeBytes
+	}
+	return 0
+}
+
+type ErrorOccurred struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	ErrorType    string                 `protobuf:"bytes,1,opt,name=error_type,json=errorType,proto3" json:"error_type,omitempty"`
+	ErrorMessage string                 `protobuf:"bytes,2,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	ErrorCode    *string                `protobuf:"bytes,3,opt,name=error_code,json=errorCode,proto3,oneof" json:"error_code,omitempty"`
+	StackTrace   *string                `protobuf:"bytes,4,opt,name=stack_trace,json=stackTrace,proto3,oneof" json:"stack_trace,omitempty"`
+	Context      *string                `protobuf:"bytes,5,opt,name=context,proto3,oneof" json:"context,omitempty"`
+	// low, medium, high, critical
+	Severity      string `protobuf:"bytes,6,opt,name=severity,proto3" json:"severity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ErrorOccurred) Reset() {
+	*x = ErrorOccurred{}
+	mi := &file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ErrorOccurred) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ErrorOccurred) ProtoMessage() {}
+
+func (x *ErrorOccurred) ProtoReflect() protoreflect.Message {
+	mi := &file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ErrorOccurred.ProtoReflect.Descriptor instead.
+func (*ErrorOccurred) Descriptor() ([]byte, []int) {
+	return file_services_ingestion_ingestionpb_ingestion_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *ErrorOccurred) GetErrorType() string {
+	if x != nil {
+		return x.ErrorType
+	}
+	return ""
+}
+
+func (x *ErrorOccurred) GetErrorMessage() string {
+	if x != nil {
+		return x.ErrorMessage
+	}
+	return ""
+}
+
+func (x *ErrorOccurred) GetErrorCode() string {
+	if x != nil && x.ErrorCode != nil {
+		return *x.ErrorCode
+	}
+	return ""
+}
+
+func (x *ErrorOccurred) GetStackTrace() string {
+	if x != nil && x.StackTrace != nil {
+		return *x.StackTrace
+	}
+	return ""
+}
+
+func (x *ErrorOccurred) GetContext() string {
+	if x != nil && x.Context != nil {
+		return *x.Context
+	}
+	return ""
+}
+
+func (x *ErrorOccurred) GetSeverity() string {
+	if x != nil {
+		return x.Severity
+	}
+	return ""
+}
+
+type SystemStartup struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ColdStart     bool                   `protobuf:"varint,1,opt,name=cold_start,json=coldStart,proto3" json:"cold_start,omitempty"`
+	StartupTimeMs *int32                 `protobuf:"varint,2,opt,name=startup_time_ms,json=startupTimeMs,proto3,oneof" json:"startup_time_ms,omitempty"`
+	OsVersion     *string                `protobuf:"bytes,3,opt,name=os_version,json=osVersion,proto3,oneof" json:"os_version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SystemStartup) Reset() {
+	*x = SystemStartup{}
+	mi := &file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SystemStartup) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SystemStartup) ProtoMessage() {}
+
+func (x *SystemStartup) ProtoReflect() protoreflect.Message {
+	mi := &file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SystemStartup.ProtoReflect.Descriptor instead.
+func (*SystemStartup) Descriptor() ([]byte, []int) {
+	return file_services_ingestion_ingestionpb_ingestion_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *SystemStartup) GetColdStart() bool {
+	if x != nil {
+		return x.ColdStart
+	}
+	return false
+}
+
+func (x *SystemStartup) GetStartupTimeMs() int32 {
+	if x != nil && x.StartupTimeMs != nil {
+		return *x.StartupTimeMs
+	}
+	return 0
+}
+
+func (x *SystemStartup) GetOsVersion() string {
+	if x != nil && x.OsVersion != nil {
+		return *x.OsVersion
+	}
+	return ""
+}
+
+type SystemShutdown struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// e.g. user, low_battery, update, crash
+	Reason        string `protobuf:"bytes,1,opt,name=reason,proto3" json:"reason,omitempty"`
+	UptimeMs      int32  `protobuf:"varint,2,opt,name=uptime_ms,json=uptimeMs,proto3" json:"uptime_ms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SystemShutdown) Reset() {
+	*x = SystemShutdown{}
+	mi := &file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SystemShutdown) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SystemShutdown) ProtoMessage() {}
+
+func (x *SystemShutdown) ProtoReflect() protoreflect.Message {
+	mi := &file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SystemShutdown.ProtoReflect.Descriptor instead.
+func (*SystemShutdown) Descriptor() ([]byte, []int) {
+	return file_services_ingestion_ingestionpb_ingestion_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *SystemShutdown) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *SystemShutdown) GetUptimeMs() int32 {
+	if x != nil {
+		return x.UptimeMs
+	}
+	return 0
+}
+
+var File_services_ingestion_ingestionpb_ingestion_proto protoreflect.FileDescriptor
+
+const file_services_ingestion_ingestionpb_ingestion_proto_rawDesc = "" +
+	"\n" +
+	".services/ingestion/ingestionpb/ingestion.proto\x12\x15dashbeam.ingestion.v1\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"H\n" +
+	"\x10SendBatchRequest\x124\n" +
+	"\x06events\x18\x01 \x03(\v2\x1c.dashbeam.ingestion.v1.EventR\x06events\"F\n" +
+	"\x10SendEventRequest\x122\n" +
+	"\x05event\x18\x01 \x01(\v2\x1c.dashbeam.ingestion.v1.EventR\x05event\"K\n" +
+	"\x13UploadEventsRequest\x124\n" +
+	"\x06events\x18\x01 \x03(\v2\x1c.dashbeam.ingestion.v1.EventR\x06events\"\xaa\x02\n" +
+	"\x11SendBatchResponse\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x12\x1b\n" +
+	"\tevent_ids\x18\x02 \x03(\tR\beventIds\x12.\n" +
+	"\x13duplicate_event_ids\x18\x03 \x03(\tR\x11duplicateEventIds\x12\x1c\n" +
+	"\tprocessed\x18\x04 \x01(\x05R\tprocessed\x12\x1a\n" +
+	"\brejected\x18\x05 \x01(\x05R\brejected\x12<\n" +
+	"\aresults\x18\x06 \x03(\v2\".dashbeam.ingestion.v1.EventResultR\aresults\x128\n" +
+	"\ttimestamp\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\"j\n" +
+	"\x11SendEventResponse\x12\x19\n" +
+	"\bevent_id\x18\x01 \x01(\tR\aeventId\x12:\n" +
+	"\x06status\x18\x02 \x01(\x0e2\".dashbeam.ingestion.v1.EventStatusR\x06status\"\x8a\x02\n" +
+	"\vEventResult\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x05R\x05index\x12\x19\n" +
+	"\bevent_id\x18\x02 \x01(\tR\aeventId\x12:\n" +
+	"\x06status\x18\x03 \x01(\x0e2\".dashbeam.ingestion.v1.EventStatusR\x06status\x12)\n" +
+	"\x10rewritten_fields\x18\x04 \x03(\tR\x0frewrittenFields\x12\x1d\n" +
+	"\n" +
+	"error_code\x18\x05 \x01(\tR\terrorCode\x12\x14\n" +
+	"\x05error\x18\x06 \x01(\tR\x05error\x12.\n" +
+	"\x13retry_after_seconds\x18\a \x01(\x05R\x11retryAfterSeconds\"\x8a\x0f\n" +
+	"\x05Event\x12\x19\n" +
+	"\bevent_id\x18\x01 \x01(\tR\aeventId\x128\n" +
+	"\ttimestamp\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12\x17\n" +
+	"\auser_id\x18\x03 \x01(\tR\x06userId\x12\x1b\n" +
+	"\tschool_id\x18\x04 \x01(\tR\bschoolId\x12&\n" +
+	"\fclassroom_id\x18\x05 \x01(\tH\x01R\vclassroomId\x88\x01\x01\x129\n" +
+	"\bapp_type\x18\x06 \x01(\x0e2\x1e.dashbeam.ingestion.v1.AppTypeR\aappType\x12;\n" +
+	"\bmetadata\x18\a \x01(\v2\x1f.dashbeam.ingestion.v1.MetadataR\bmetadata\x12]\n" +
+	"\x14quiz_session_started\x18\x14 \x01(\v2).dashbeam.ingestion.v1.QuizSessionStartedH\x00R\x12quizSessionStarted\x12Z\n" +
+	"\x13quiz_question_shown\x18\x15 \x01(\v2(.dashbeam.ingestion.v1.QuizQuestionShownH\x00R\x11quizQuestionShown\x12`\n" +
+	"\x15quiz_answer_submitted\x18\x16 \x01(\v2*.dashbeam.ingestion.v1.QuizAnswerSubmittedH\x00R\x13quizAnswerSubmitted\x12c\n" +
+	"\x16quiz_session_completed\x18\x17 \x01(\v2+.dashbeam.ingestion.v1.QuizSessionCompletedH\x00R\x14quizSessionCompleted\x12c\n" +
+	"\x16quiz_session_abandoned\x18\x18 \x01(\v2+.dashbeam.ingestion.v1.QuizSessionAbandonedH\x00R\x14quizSessionAbandoned\x12Z\n" +
+	"\x13quiz_session_paused\x18\x19 \x01(\v2(.dashbeam.ingestion.v1.QuizSessionPausedH\x00R\x11quizSessionPaused\x12]\n" +
+	"\x14quiz_session_resumed\x18\x1a \x01(\v2).dashbeam.ingestion.v1.QuizSessionResumedH\x00R\x12quizSessionResumed\x12A\n" +
+	"\n" +
+	"user_login\x18( \x01(\v2 .dashbeam.ingestion.v1.UserLoginH\x00R\tuserLogin\x12D\n" +
+	"\vuser_logout\x18) \x01(\v2!.dashbeam.ingestion.v1.UserLogoutH\x00R\n" +
+	"userLogout\x12P\n" +
+	"\x0fapp_interaction\x18* \x01(\v2%.dashbeam.ingestion.v1.AppInteractionH\x00R\x0eappInteraction\x12M\n" +
+	"\x0eapp_navigation\x18+ \x01(\v2$.dashbeam.ingestion.v1.AppNavigationH\x00R\rappNavigation\x12Q\n" +
+	"\x10app_focus_change\x18, \x01(\v2%.dashbeam.ingestion.v1.AppFocusChangeH\x00R\x0eappFocusChange\x12M\n" +
+	"\x0eapp_background\x18- \x01(\v2$.dashbeam.ingestion.v1.AppBackgroundH\x00R\rappBackground\x12M\n" +
+	"\x0eapp_foreground\x18. \x01(\v2$.dashbeam.ingestion.v1.AppForegroundH\x00R\rappForeground\x12D\n" +
+	"\vapi_request\x18< \x01(\v2!.dashbeam.ingestion.v1.ApiRequestH\x00R\n" +
+	"apiRequest\x12G\n" +
+	"\fapi_response\x18= \x01(\v2\".dashbeam.ingestion.v1.ApiResponseH\x00R\vapiResponse\x12M\n" +
+	"\x0eerror_occurred\x18> \x01(\v2$.dashbeam.ingestion.v1.ErrorOccurredH\x00R\rerrorOccurred\x12M\n" +
+	"\x0esystem_startup\x18? \x01(\v2$.dashbeam.ingestion.v1.SystemStartupH\x00R\rsystemStartup\x12P\n" +
+	"\x0fsystem_shutdown\x18@ \x01(\v2%.dashbeam.ingestion.v1.SystemShutdownH\x00R\x0esystemShutdownB\t\n" +
+	"\apayloadB\x0f\n" +
+	"\r_classroom_id\"\xbe\x02\n" +
+	"\bMetadata\x12\x1f\n" +
+	"\vapp_version\x18\x01 \x01(\tR\n" +
+	"appVersion\x12\x1f\n" +
+	"\vdevice_type\x18\x02 \x01(\tR\n" +
+	"deviceType\x12\x1b\n" +
+	"\tdevice_id\x18\x03 \x01(\tR\bdeviceId\x12&\n" +
+	"\fnetwork_type\x18\x04 \x01(\tH\x00R\vnetworkType\x88\x01\x01\x12$\n" +
+	"\vclient_time\x18\x05 \x01(\tH\x01R\n" +
+	"clientTime\x88\x01\x01\x12\"\n" +
+	"\n" +
+	"session_id\x18\x06 \x01(\tH\x02R\tsessionId\x88\x01\x01\x12\"\n" +
+	"\n" +
+	"ip_address\x18\a \x01(\tH\x03R\tipAddress\x88\x01\x01B\x0f\n" +
+	"\r_network_typeB\x0e\n" +
+	"\f_client_timeB\r\n" +
+	"\v_session_idB\r\n" +
+	"\v_ip_address\"\xff\x01\n" +
+	"\x12QuizSessionStarted\x12\x17\n" +
+	"\aquiz_id\x18\x01 \x01(\tR\x06quizId\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x02 \x01(\tR\tsessionId\x12!\n" +
+	"\fsession_code\x18\x03 \x01(\tR\vsessionCode\x12'\n" +
+	"\x0ftotal_questions\x18\x04 \x01(\x05R\x0etotalQuestions\x121\n" +
+	"\x12time_limit_seconds\x18\x05 \x01(\x05H\x00R\x10timeLimitSeconds\x88\x01\x01\x12\x1b\n" +
+	"\tmax_score\x18\x06 \x01(\x01R\bmaxScoreB\x15\n" +
+	"\x13_time_limit_seconds\"\x88\x02\n" +
+	"\x11QuizQuestionShown\x12\x17\n" +
+	"\aquiz_id\x18\x01 \x01(\tR\x06quizId\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x02 \x01(\tR\tsessionId\x12\x1f\n" +
+	"\vquestion_id\x18\x03 \x01(\tR\n" +
+	"questionId\x12+\n" +
+	"\x11question_sequence\x18\x04 \x01(\x05R\x10questionSequence\x12#\n" +
+	"\rquestion_type\x18\x05 \x01(\tR\fquestionType\x121\n" +
+	"\x12time_limit_seconds\x18\x06 \x01(\x05H\x00R\x10timeLimitSeconds\x88\x01\x01B\x15\n" +
+	"\x13_time_limit_seconds\"\x8f\x03\n" +
+	"\x13QuizAnswerSubmitted\x12\x17\n" +
+	"\aquiz_id\x18\x01 \x01(\tR\x06quizId\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x02 \x01(\tR\tsessionId\x12\x1f\n" +
+	"\vquestion_id\x18\x03 \x01(\tR\n" +
+	"questionId\x12+\n" +
+	"\x11question_sequence\x18\x04 \x01(\x05R\x10questionSequence\x12.\n" +
+	"\x06answer\x18\x05 \x01(\v2\x16.google.protobuf.ValueR\x06answer\x12\"\n" +
+	"\n" +
+	"is_correct\x18\x06 \x01(\bH\x00R\tisCorrect\x88\x01\x01\x12(\n" +
+	"\x10response_time_ms\x18\a \x01(\x05R\x0eresponseTimeMs\x12*\n" +
+	"\x0eanswer_changes\x18\b \x01(\x05H\x01R\ranswerChanges\x88\x01\x01\x12\x1b\n" +
+	"\x06points\x18\t \x01(\x01H\x02R\x06points\x88\x01\x01B\r\n" +
+	"\v_is_correctB\x11\n" +
+	"\x0f_answer_changesB\t\n" +
+	"\a_points\"\xfc\x02\n" +
+	"\x14QuizSessionCompleted\x12\x17\n" +
+	"\aquiz_id\x18\x01 \x01(\tR\x06quizId\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x02 \x01(\tR\tsessionId\x12\x1f\n" +
+	"\vtotal_score\x18\x03 \x01(\x01R\n" +
+	"totalScore\x12\x1b\n" +
+	"\tmax_score\x18\x04 \x01(\x01R\bmaxScore\x12,\n" +
+	"\x12completion_time_ms\x18\x05 \x01(\x05R\x10completionTimeMs\x12+\n" +
+	"\x11questions_correct\x18\x06 \x01(\x05R\x10questionsCorrect\x12-\n" +
+	"\x12questions_answered\x18\a \x01(\x05R\x11questionsAnswered\x12+\n" +
+	"\x11questions_skipped\x18\b \x01(\x05R\x10questionsSkipped\x127\n" +
+	"\x18average_response_time_ms\x18\t \x01(\x05R\x15averageResponseTimeMs\"\xb9\x01\n" +
+	"\x14QuizSessionAbandoned\x12\x17\n" +
+	"\aquiz_id\x18\x01 \x01(\tR\x06quizId\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x02 \x01(\tR\tsessionId\x12-\n" +
+	"\x12questions_answered\x18\x03 \x01(\x05R\x11questionsAnswered\x12\"\n" +
+	"\rtime_spent_ms\x18\x04 \x01(\x05R\vtimeSpentMs\x12\x16\n" +
+	"\x06reason\x18\x05 \x01(\tR\x06reason\"\xab\x01\n" +
+	"\x11QuizSessionPaused\x12\x17\n" +
+	"\aquiz_id\x18\x01 \x01(\tR\x06quizId\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x02 \x01(\tR\tsessionId\x120\n" +
+	"\x11question_sequence\x18\x03 \x01(\x05H\x00R\x10questionSequence\x88\x01\x01\x12\x16\n" +
+	"\x06reason\x18\x04 \x01(\tR\x06reasonB\x14\n" +
+	"\x12_question_sequence\"\xc2\x01\n" +
+	"\x12QuizSessionResumed\x12\x17\n" +
+	"\aquiz_id\x18\x01 \x01(\tR\x06quizId\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x02 \x01(\tR\tsessionId\x120\n" +
+	"\x11question_sequence\x18\x03 \x01(\x05H\x00R\x10questionSequence\x88\x01\x01\x12,\n" +
+	"\x12paused_duration_ms\x18\x04 \x01(\x05R\x10pausedDurationMsB\x14\n" +
+	"\x12_question_sequence\"\xf8\x02\n" +
+	"\tUserLogin\x12!\n" +
+	"\flogin_method\x18\x01 \x01(\tR\vloginMethod\x12#\n" +
+	"\rsession_start\x18\x02 \x01(\bR\fsessionStart\x12T\n" +
+	"\x18previous_login_timestamp\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x16previousLoginTimestamp\x12\"\n" +
+	"\n" +
+	"user_agent\x18\x04 \x01(\tH\x00R\tuserAgent\x88\x01\x01\x12\"\n" +
+	"\n" +
+	"ip_address\x18\x05 \x01(\tH\x01R\tipAddress\x88\x01\x01\x12\x19\n" +
+	"\x05email\x18\x06 \x01(\tH\x02R\x05email\x88\x01\x01\x12\x17\n" +
+	"\x04name\x18\a \x01(\tH\x03R\x04name\x88\x01\x01\x12\x17\n" +
+	"\x04role\x18\b \x01(\tH\x04R\x04role\x88\x01\x01B\r\n" +
+	"\v_user_agentB\r\n" +
+	"\v_ip_addressB\b\n" +
+	"\x06_emailB\a\n" +
+	"\x05_nameB\a\n" +
+	"\x05_role\"a\n" +
+	"\n" +
+	"UserLogout\x12.\n" +
+	"\x13session_duration_ms\x18\x01 \x01(\x05R\x11sessionDurationMs\x12#\n" +
+	"\rlogout_reason\x18\x02 \x01(\tR\flogoutReason\"\xba\x02\n" +
+	"\x0eAppInteraction\x12)\n" +
+	"\x10interaction_type\x18\x01 \x01(\tR\x0finteractionType\x12\x1f\n" +
+	"\vscreen_name\x18\x02 \x01(\tR\n" +
+	"screenName\x12,\n" +
+	"\x0felement_clicked\x18\x03 \x01(\tH\x00R\x0eelementClicked\x88\x01\x01\x12'\n" +
+	"\rtime_spent_ms\x18\x04 \x01(\x05H\x01R\vtimeSpentMs\x88\x01\x01\x12&\n" +
+	"\felement_data\x18\x05 \x01(\tH\x02R\velementData\x88\x01\x01\x12\x1b\n" +
+	"\x06action\x18\x06 \x01(\tH\x03R\x06action\x88\x01\x01B\x12\n" +
+	"\x10_element_clickedB\x10\n" +
+	"\x0e_time_spent_msB\x0f\n" +
+	"\r_element_dataB\t\n" +
+	"\a_action\"\xf3\x01\n" +
+	"\rAppNavigation\x12\x1f\n" +
+	"\vfrom_screen\x18\x01 \x01(\tR\n" +
+	"fromScreen\x12\x1b\n" +
+	"\tto_screen\x18\x02 \x01(\tR\btoScreen\x12'\n" +
+	"\x0fnavigation_type\x18\x03 \x01(\tR\x0enavigationType\x12'\n" +
+	"\rtime_spent_ms\x18\x04 \x01(\x05H\x00R\vtimeSpentMs\x88\x01\x01\x12,\n" +
+	"\x0fnavigation_data\x18\x05 \x01(\tH\x01R\x0enavigationData\x88\x01\x01B\x10\n" +
+	"\x0e_time_spent_msB\x12\n" +
+	"\x10_navigation_data\"\xc5\x01\n" +
+	"\x0eAppFocusChange\x12\x1b\n" +
+	"\thas_focus\x18\x01 \x01(\bR\bhasFocus\x12\x1f\n" +
+	"\vscreen_name\x18\x02 \x01(\tR\n" +
+	"screenName\x12+\n" +
+	"\x0fquiz_session_id\x18\x03 \x01(\tH\x00R\rquizSessionId\x88\x01\x01\x12$\n" +
+	"\vduration_ms\x18\x04 \x01(\x05H\x01R\n" +
+	"durationMs\x88\x01\x01B\x12\n" +
+	"\x10_quiz_session_idB\x0e\n" +
+	"\f_duration_ms\"\x86\x01\n" +
+	"\rAppBackground\x12\x1f\n" +
+	"\vscreen_name\x18\x01 \x01(\tR\n" +
+	"screenName\x129\n" +
+	"\x16foreground_duration_ms\x18\x02 \x01(\x05H\x00R\x14foregroundDurationMs\x88\x01\x01B\x19\n" +
+	"\x17_foreground_duration_ms\"\x86\x01\n" +
+	"\rAppForeground\x12\x1f\n" +
+	"\vscreen_name\x18\x01 \x01(\tR\n" +
+	"screenName\x129\n" +
+	"\x16background_duration_ms\x18\x02 \x01(\x05H\x00R\x14backgroundDurationMs\x88\x01\x01B\x19\n" +
+	"\x17_background_duration_ms\"\x84\x03\n" +
+	"\n" +
+	"ApiRequest\x12\x16\n" +
+	"\x06method\x18\x01 \x01(\tR\x06method\x12\x1a\n" +
+	"\bendpoint\x18\x02 \x01(\tR\bendpoint\x12$\n" +
+	"\vstatus_code\x18\x03 \x01(\x05H\x00R\n" +
+	"statusCode\x88\x01\x01\x12-\n" +
+	"\x10response_time_ms\x18\x04 \x01(\x05H\x01R\x0eresponseTimeMs\x88\x01\x01\x12\"\n" +
+	"\n" +
+	"error_code\x18\x05 \x01(\tH\x02R\terrorCode\x88\x01\x01\x121\n" +
+	"\x12request_size_bytes\x18\x06 \x01(\x05H\x03R\x10requestSizeBytes\x88\x01\x01\x123\n" +
+	"\x13response_size_bytes\x18\a \x01(\x05H\x04R\x11responseSizeBytes\x88\x01\x01B\x0e\n" +
+	"\f_status_codeB\x13\n" +
+	"\x11_response_time_msB\r\n" +
+	"\v_error_codeB\x15\n" +
+	"\x13_request_size_bytesB\x16\n" +
+	"\x14_response_size_bytes\"\xa6\x02\n" +
+	"\vApiResponse\x12\x16\n" +
+	"\x06method\x18\x01 \x01(\tR\x06method\x12\x1a\n" +
+	"\bendpoint\x18\x02 \x01(\tR\bendpoint\x12\x1f\n" +
+	"\vstatus_code\x18\x03 \x01(\x05R\n" +
+	"statusCode\x12-\n" +
+	"\x10response_time_ms\x18\x04 \x01(\x05H\x00R\x0eresponseTimeMs\x88\x01\x01\x12\"\n" +
+	"\n" +
+	"error_code\x18\x05 \x01(\tH\x01R\terrorCode\x88\x01\x01\x123\n" +
+	"\x13response_size_bytes\x18\x06 \x01(\x05H\x02R\x11responseSizeBytes\x88\x01\x01B\x13\n" +
+	"\x11_response_time_msB\r\n" +
+	"\v_error_codeB\x16\n" +
+	"\x14_response_size_bytes\"\x83\x02\n" +
+	"\rErrorOccurred\x12\x1d\n" +
+	"\n" +
+	"error_type\x18\x01 \x01(\tR\terrorType\x12#\n" +
+	"\rerror_message\x18\x02 \x01(\tR\ferrorMessage\x12\"\n" +
+	"\n" +
+	"error_code\x18\x03 \x01(\tH\x00R\terrorCode\x88\x01\x01\x12$\n" +
+	"\vstack_trace\x18\x04 \x01(\tH\x01R\n" +
+	"stackTrace\x88\x01\x01\x12\x1d\n" +
+	"\acontext\x18\x05 \x01(\tH\x02R\acontext\x88\x01\x01\x12\x1a\n" +
+	"\bseverity\x18\x06 \x01(\tR\bseverityB\r\n" +
+	"\v_error_codeB\x0e\n" +
+	"\f_stack_traceB\n" +
+	"\n" +
+	"\b_context\"\xa2\x01\n" +
+	"\rSystemStartup\x12\x1d\n" +
+	"\n" +
+	"cold_start\x18\x01 \x01(\bR\tcoldStart\x12+\n" +
+	"\x0fstartup_time_ms\x18\x02 \x01(\x05H\x00R\rstartupTimeMs\x88\x01\x01\x12\"\n" +
+	"\n" +
+	"os_version\x18\x03 \x01(\tH\x01R\tosVersion\x88\x01\x01B\x12\n" +
+	"\x10_startup_time_msB\r\n" +
+	"\v_os_version\"E\n" +
+	"\x0eSystemShutdown\x12\x16\n" +
+	"\x06reason\x18\x01 \x01(\tR\x06reason\x12\x1b\n" +
+	"\tuptime_ms\x18\x02 \x01(\x05R\buptimeMs*}\n" +
+	"\vEventStatus\x12\x1c\n" +
+	"\x18EVENT_STATUS_UNSPECIFIED\x10\x00\x12\x19\n" +
+	"\x15EVENT_STATUS_ACCEPTED\x10\x01\x12\x1a\n" +
+	"\x16EVENT_STATUS_DUPLICATE\x10\x02\x12\x19\n" +
+	"\x15EVENT_STATUS_REJECTED\x10\x03*S\n" +
+	"\aAppType\x12\x18\n" +
+	"\x14APP_TYPE_UNSPECIFIED\x10\x00\x12\x17\n" +
+	"\x13APP_TYPE_WHITEBOARD\x10\x01\x12\x15\n" +
+	"\x11APP_TYPE_NOTEBOOK\x10\x022\x88\x04\n" +
+	"\x10IngestionService\x12^\n" +
+	"\tSendBatch\x12'.dashbeam.ingestion.v1.SendBatchRequest\x1a(.dashbeam.ingestion.v1.SendBatchResponse\x12b\n" +
+	"\rSendQuizEvent\x12'.dashbeam.ingestion.v1.SendEventRequest\x1a(.dashbeam.ingestion.v1.SendEventResponse\x12b\n" +
+	"\rSendUserEvent\x12'.dashbeam.ingestion.v1.SendEventRequest\x1a(.dashbeam.ingestion.v1.SendEventResponse\x12d\n" +
+	"\x0fSendSystemEvent\x12'.dashbeam.ingestion.v1.SendEventRequest\x1a(.dashbeam.ingestion.v1.SendEventResponse\x12f\n" +
+	"\fUploadEvents\x12*.dashbeam.ingestion.v1.UploadEventsRequest\x1a(.dashbeam.ingestion.v1.SendBatchResponse(\x01BCZAgithub.com/lavish-gambhir/dashbeam/services/ingestion/ingestionpbb\x06proto3"
+
+var (
+	file_services_ingestion_ingestionpb_ingestion_proto_rawDescOnce sync.Once
+	file_services_ingestion_ingestionpb_ingestion_proto_rawDescData []byte
+)
+
+func file_services_ingestion_ingestionpb_ingestion_proto_rawDescGZIP() []byte {
+	file_services_ingestion_ingestionpb_ingestion_proto_rawDescOnce.Do(func() {
+		file_services_ingestion_ingestionpb_ingestion_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_services_ingestion_ingestionpb_ingestion_proto_rawDesc), len(file_services_ingestion_ingestionpb_ingestion_proto_rawDesc)))
+	})
+	return file_services_ingestion_ingestionpb_ingestion_proto_rawDescData
+}
+
+var file_services_ingestion_ingestionpb_ingestion_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_services_ingestion_ingestionpb_ingestion_proto_msgTypes = make([]protoimpl.MessageInfo, 27)
+var file_services_ingestion_ingestionpb_ingestion_proto_goTypes = []any{
+	(EventStatus)(0),              // 0: dashbeam.ingestion.v1.EventStatus
+	(AppType)(0),                  // 1: dashbeam.ingestion.v1.AppType
+	(*SendBatchRequest)(nil),      // 2: dashbeam.ingestion.v1.SendBatchRequest
+	(*SendEventRequest)(nil),      // 3: dashbeam.ingestion.v1.SendEventRequest
+	(*UploadEventsRequest)(nil),   // 4: dashbeam.ingestion.v1.UploadEventsRequest
+	(*SendBatchResponse)(nil),     // 5: dashbeam.ingestion.v1.SendBatchResponse
+	(*SendEventResponse)(nil),     // 6: dashbeam.ingestion.v1.SendEventResponse
+	(*EventResult)(nil),           // 7: dashbeam.ingestion.v1.EventResult
+	(*Event)(nil),                 // 8: dashbeam.ingestion.v1.Event
+	(*Metadata)(nil),              // 9: dashbeam.ingestion.v1.Metadata
+	(*QuizSessionStarted)(nil),    // 10: dashbeam.ingestion.v1.QuizSessionStarted
+	(*QuizQuestionShown)(nil),     // 11: dashbeam.ingestion.v1.QuizQuestionShown
+	(*QuizAnswerSubmitted)(nil),   // 12: dashbeam.ingestion.v1.QuizAnswerSubmitted
+	(*QuizSessionCompleted)(nil),  // 13: dashbeam.ingestion.v1.QuizSessionCompleted
+	(*QuizSessionAbandoned)(nil),  // 14: dashbeam.ingestion.v1.QuizSessionAbandoned
+	(*QuizSessionPaused)(nil),     // 15: dashbeam.ingestion.v1.QuizSessionPaused
+	(*QuizSessionResumed)(nil),    // 16: dashbeam.ingestion.v1.QuizSessionResumed
+	(*UserLogin)(nil),             // 17: dashbeam.ingestion.v1.UserLogin
+	(*UserLogout)(nil),            // 18: dashbeam.ingestion.v1.UserLogout
+	(*AppInteraction)(nil),        // 19: dashbeam.ingestion.v1.AppInteraction
+	(*AppNavigation)(nil),         // 20: dashbeam.ingestion.v1.AppNavigation
+	(*AppFocusChange)(nil),        // 21: dashbeam.ingestion.v1.AppFocusChange
+	(*AppBackground)(nil),         // 22: dashbeam.ingestion.v1.AppBackground
+	(*AppForeground)(nil),         // 23: dashbeam.ingestion.v1.AppForeground
+	(*ApiRequest)(nil),            // 24: dashbeam.ingestion.v1.ApiRequest
+	(*ApiResponse)(nil),           // 25: dashbeam.ingestion.v1.ApiResponse
+	(*ErrorOccurred)(nil),         // 26: dashbeam.ingestion.v1.ErrorOccurred
+	(*SystemStartup)(nil),         // 27: dashbeam.ingestion.v1.SystemStartup
+	(*SystemShutdown)(nil),        // 28: dashbeam.ingestion.v1.SystemShutdown
+	(*timestamppb.Timestamp)(nil), // 29: google.protobuf.Timestamp
+	(*structpb.Value)(nil),        // 30: google.protobuf.Value
+}
+var file_services_ingestion_ingestionpb_ingestion_proto_depIdxs = []int32{
+	8,  // 0: dashbeam.ingestion.v1.SendBatchRequest.events:type_name -> dashbeam.ingestion.v1.Event
+	8,  // 1: dashbeam.ingestion.v1.SendEventRequest.event:type_name -> dashbeam.ingestion.v1.Event
+	8,  // 2: dashbeam.ingestion.v1.UploadEventsRequest.events:type_name -> dashbeam.ingestion.v1.Event
+	7,  // 3: dashbeam.ingestion.v1.SendBatchResponse.results:type_name -> dashbeam.ingestion.v1.EventResult
+	29, // 4: dashbeam.ingestion.v1.SendBatchResponse.timestamp:type_name -> google.protobuf.Timestamp
+	0,  // 5: dashbeam.ingestion.v1.SendEventResponse.status:type_name -> dashbeam.ingestion.v1.EventStatus
+	0,  // 6: dashbeam.ingestion.v1.EventResult.status:type_name -> dashbeam.ingestion.v1.EventStatus
+	29, // 7: dashbeam.ingestion.v1.Event.timestamp:type_name -> google.protobuf.Timestamp
+	1,  // 8: dashbeam.ingestion.v1.Event.app_type:type_name -> dashbeam.ingestion.v1.AppType
+	9,  // 9: dashbeam.ingestion.v1.Event.metadata:type_name -> dashbeam.ingestion.v1.Metadata
+	10, // 10: dashbeam.ingestion.v1.Event.quiz_session_started:type_name -> dashbeam.ingestion.v1.QuizSessionStarted
+	11, // 11: dashbeam.ingestion.v1.Event.quiz_question_shown:type_name -> dashbeam.ingestion.v1.QuizQuestionShown
+	12, // 12: dashbeam.ingestion.v1.Event.quiz_answer_submitted:type_name -> dashbeam.ingestion.v1.QuizAnswerSubmitted
+	13, // 13: dashbeam.ingestion.v1.Event.quiz_session_completed:type_name -> dashbeam.ingestion.v1.QuizSessionCompleted
+	14, // 14: dashbeam.ingestion.v1.Event.quiz_session_abandoned:type_name -> dashbeam.ingestion.v1.QuizSessionAbandoned
+	15, // 15: dashbeam.ingestion.v1.Event.quiz_session_paused:type_name -> dashbeam.ingestion.v1.QuizSessionPaused
+	16, // 16: dashbeam.ingestion.v1.Event.quiz_session_resumed:type_name -> dashbeam.ingestion.v1.QuizSessionResumed
+	17, // 17: dashbeam.ingestion.v1.Event.user_login:type_name -> dashbeam.ingestion.v1.UserLogin
+	18, // 18: dashbeam.ingestion.v1.Event.user_logout:type_name -> dashbeam.ingestion.v1.UserLogout
+	19, // 19: dashbeam.ingestion.v1.Event.app_interaction:type_name -> dashbeam.ingestion.v1.AppInteraction
+	20, // 20: dashbeam.ingestion.v1.Event.app_navigation:type_name -> dashbeam.ingestion.v1.AppNavigation
+	21, // 21: dashbeam.ingestion.v1.Event.app_focus_change:type_name -> dashbeam.ingestion.v1.AppFocusChange
+	22, // 22: dashbeam.ingestion.v1.Event.app_background:type_name -> dashbeam.ingestion.v1.AppBackground
+	23, // 23: dashbeam.ingestion.v1.Event.app_foreground:type_name -> dashbeam.ingestion.v1.AppForeground
+	24, // 24: dashbeam.ingestion.v1.Event.api_request:type_name -> dashbeam.ingestion.v1.ApiRequest
+	25, // 25: dashbeam.ingestion.v1.Event.api_response:type_name -> dashbeam.ingestion.v1.ApiResponse
+	26, // 26: dashbeam.ingestion.v1.Event.error_occurred:type_name -> dashbeam.ingestion.v1.ErrorOccurred
+	27, // 27: dashbeam.ingestion.v1.Event.system_startup:type_name -> dashbeam.ingestion.v1.SystemStartup
+	28, // 28: dashbeam.ingestion.v1.Event.system_shutdown:type_name -> dashbeam.ingestion.v1.SystemShutdown
+	30, // 29: dashbeam.ingestion.v1.QuizAnswerSubmitted.answer:type_name -> google.protobuf.Value
+	29, // 30: dashbeam.ingestion.v1.UserLogin.previous_login_timestamp:type_name -> google.protobuf.Timestamp
+	2,  // 31: dashbeam.ingestion.v1.IngestionService.SendBatch:input_type -> dashbeam.ingestion.v1.SendBatchRequest
+	3,  // 32: dashbeam.ingestion.v1.IngestionService.SendQuizEvent:input_type -> dashbeam.ingestion.v1.SendEventRequest
+	3,  // 33: dashbeam.ingestion.v1.IngestionService.SendUserEvent:input_type -> dashbeam.ingestion.v1.SendEventRequest
+	3,  // 34: dashbeam.ingestion.v1.IngestionService.SendSystemEvent:input_type -> dashbeam.ingestion.v1.SendEventRequest
+	4,  // 35: dashbeam.ingestion.v1.IngestionService.UploadEvents:input_type -> dashbeam.ingestion.v1.UploadEventsRequest
+	5,  // 36: dashbeam.ingestion.v1.IngestionService.SendBatch:output_type -> dashbeam.ingestion.v1.SendBatchResponse
+	6,  // 37: dashbeam.ingestion.v1.IngestionService.SendQuizEvent:output_type -> dashbeam.ingestion.v1.SendEventResponse
+	6,  // 38: dashbeam.ingestion.v1.IngestionService.SendUserEvent:output_type -> dashbeam.ingestion.v1.SendEventResponse
+	6,  // 39: dashbeam.ingestion.v1.IngestionService.SendSystemEvent:output_type -> dashbeam.ingestion.v1.SendEventResponse
+	5,  // 40: dashbeam.ingestion.v1.IngestionService.UploadEvents:output_type -> dashbeam.ingestion.v1.SendBatchResponse
+	36, // [36:41] is the sub-list for method output_type
+	31, // [31:36] is the sub-list for method input_type
+	31, // [31:31] is the sub-list for extension type_name
+	31, // [31:31] is the sub-list for extension extendee
+	0,  // [0:31] is the sub-list for field type_name
+}
+
+func init() { file_services_ingestion_ingestionpb_ingestion_proto_init() }
+func file_services_ingestion_ingestionpb_ingestion_proto_init() {
+	if File_services_ingestion_ingestionpb_ingestion_proto != nil {
+		return
+	}
+	file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[6].OneofWrappers = []any{
+		(*Event_QuizSessionStarted)(nil),
+		(*Event_QuizQuestionShown)(nil),
+		(*Event_QuizAnswerSubmitted)(nil),
+		(*Event_QuizSessionCompleted)(nil),
+		(*Event_QuizSessionAbandoned)(nil),
+		(*Event_QuizSessionPaused)(nil),
+		(*Event_QuizSessionResumed)(nil),
+		(*Event_UserLogin)(nil),
+		(*Event_UserLogout)(nil),
+		(*Event_AppInteraction)(nil),
+		(*Event_AppNavigation)(nil),
+		(*Event_AppFocusChange)(nil),
+		(*Event_AppBackground)(nil),
+		(*Event_AppForeground)(nil),
+		(*Event_ApiRequest)(nil),
+		(*Event_ApiResponse)(nil),
+		(*Event_ErrorOccurred)(nil),
+		(*Event_SystemStartup)(nil),
+		(*Event_SystemShutdown)(nil),
+	}
+	file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[7].OneofWrappers = []any{}
+	file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[8].OneofWrappers = []any{}
+	file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[9].OneofWrappers = []any{}
+	file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[10].OneofWrappers = []any{}
+	file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[13].OneofWrappers = []any{}
+	file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[14].OneofWrappers = []any{}
+	file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[15].OneofWrappers = []any{}
+	file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[17].OneofWrappers = []any{}
+	file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[18].OneofWrappers = []any{}
+	file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[19].OneofWrappers = []any{}
+	file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[20].OneofWrappers = []any{}
+	file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[21].OneofWrappers = []any{}
+	file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[22].OneofWrappers = []any{}
+	file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[23].OneofWrappers = []any{}
+	file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[24].OneofWrappers = []any{}
+	file_services_ingestion_ingestionpb_ingestion_proto_msgTypes[25].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_services_ingestion_ingestionpb_ingestion_proto_rawDesc), len(file_services_ingestion_ingestionpb_ingestion_proto_rawDesc)),
+			NumEnums:      2,
+			NumMessages:   27,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_services_ingestion_ingestionpb_ingestion_proto_goTypes,
+		DependencyIndexes: file_services_ingestion_ingestionpb_ingestion_proto_depIdxs,
+		EnumInfos:         file_services_ingestion_ingestionpb_ingestion_proto_enumTypes,
+		MessageInfos:      file_services_ingestion_ingestionpb_ingestion_proto_msgTypes,
+	}.Build()
+	File_services_ingestion_ingestionpb_ingestion_proto = out.File
+	file_services_ingestion_ingestionpb_ingestion_proto_goTypes = nil
+	file_services_ingestion_ingestionpb_ingestion_proto_depIdxs = nil
+}
@@ -0,0 +1,295 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: services/ingestion/ingestionpb/ingestion.proto
+
+// The ingestion API over gRPC. It mirrors the HTTP endpoints under /events:
+// the same events are accepted, with the same validation, and the same JWT is
+// expected, as "authorization: Bearer <token>" metadata.
+//
+// Regenerate the Go code with make proto after changing this file.
+
+package ingestionpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	IngestionService_SendBatch_FullMethodName       = "/dashbeam.ingestion.v1.IngestionService/SendBatch"
+	IngestionService_SendQuizEvent_FullMethodName   = "/dashbeam.ingestion.v1.IngestionService/SendQuizEvent"
+	IngestionService_SendUserEvent_FullMethodName   = "/dashbeam.ingestion.v1.IngestionService/SendUserEvent"
+	IngestionService_SendSystemEvent_FullMethodName = "/dashbeam.ingestion.v1.IngestionService/SendSystemEvent"
+	IngestionService_UploadEvents_FullMethodName    = "/dashbeam.ingestion.v1.IngestionService/UploadEvents"
+)
+
+// IngestionServiceClient is the client API for IngestionService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type IngestionServiceClient interface {
+	// SendBatch accepts up to ingestion.max_batch_size events, each on its own,
+	// like POST /events/batch. The response has one result per event; the call
+	// only fails if the request as a whole is invalid.
+	SendBatch(ctx context.Context, in *SendBatchRequest, opts ...grpc.CallOption) (*SendBatchResponse, error)
+	// SendQuizEvent, SendUserEvent and SendSystemEvent accept a single event of
+	// their category, like POST /events/quiz, /events/user and /events/system.
+	// A rejected event fails the call; RESOURCE_EXHAUSTED comes with a
+	// retry-after header in seconds.
+	SendQuizEvent(ctx context.Context, in *SendEventRequest, opts ...grpc.CallOption) (*SendEventResponse, error)
+	SendUserEvent(ctx context.Context, in *SendEventRequest, opts ...grpc.CallOption) (*SendEventResponse, error)
+	SendSystemEvent(ctx context.Context, in *SendEventRequest, opts ...grpc.CallOption) (*SendEventResponse, error)
+	// UploadEvents accepts a bulk upload, e.g. an offline buffer, in any number
+	// of messages, up to ingestion.stream_max_events events in all. Events are
+	// processed as they arrive and reported on once the client closes the
+	// stream, in upload order.
+	UploadEvents(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[UploadEventsRequest, SendBatchResponse], error)
+}
+
+type ingestionServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewIngestionServiceClient(cc grpc.ClientConnInterface) IngestionServiceClient {
+	return &ingestionServiceClient{cc}
+}
+
+func (c *ingestionServiceClient) SendBatch(ctx context.Context, in *SendBatchRequest, opts ...grpc.CallOption) (*SendBatchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SendBatchResponse)
+	err := c.cc.Invoke(ctx, IngestionService_SendBatch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ingestionServiceClient) SendQuizEvent(ctx context.Context, in *SendEventRequest, opts ...grpc.CallOption) (*SendEventResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SendEventResponse)
+	err := c.cc.Invoke(ctx, IngestionService_SendQuizEvent_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ingestionServiceClient) SendUserEvent(ctx context.Context, in *SendEventRequest, opts ...grpc.CallOption) (*SendEventResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SendEventResponse)
+	err := c.cc.Invoke(ctx, IngestionService_SendUserEvent_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ingestionServiceClient) SendSystemEvent(ctx context.Context, in *SendEventRequest, opts ...grpc.CallOption) (*SendEventResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SendEventResponse)
+	err := c.cc.Invoke(ctx, IngestionService_SendSystemEvent_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ingestionServiceClient) UploadEvents(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[UploadEventsRequest, SendBatchResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &IngestionService_ServiceDesc.Streams[0], IngestionService_UploadEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[UploadEventsRequest, SendBatchResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type IngestionService_UploadEventsClient = grpc.ClientStreamingClient[UploadEventsRequest, SendBatchResponse]
+
+// IngestionServiceServer is the server API for IngestionService service.
+// All implementations must embed UnimplementedIngestionServiceServer
+// for forward compatibility.
+type IngestionServiceServer interface {
+	// SendBatch accepts up to ingestion.max_batch_size events, each on its own,
+	// like POST /events/batch. The response has one result per event; the call
+	// only fails if the request as a whole is invalid.
+	SendBatch(context.Context, *SendBatchRequest) (*SendBatchResponse, error)
+	// SendQuizEvent, SendUserEvent and SendSystemEvent accept a single event of
+	// their category, like POST /events/quiz, /events/user and /events/system.
+	// A rejected event fails the call; RESOURCE_EXHAUSTED comes with a
+	// retry-after header in seconds.
+	SendQuizEvent(context.Context, *SendEventRequest) (*SendEventResponse, error)
+	SendUserEvent(context.Context, *SendEventRequest) (*SendEventResponse, error)
+	SendSystemEvent(context.Context, *SendEventRequest) (*SendEventResponse, error)
+	// UploadEvents accepts a bulk upload, e.g. an offline buffer, in any number
+	// of messages, up to ingestion.stream_max_events events in all. Events are
+	// processed as they arrive and reported on once the client closes the
+	// stream, in upload order.
+	UploadEvents(grpc.ClientStreamingServer[UploadEventsRequest, SendBatchResponse]) error
+	mustEmbedUnimplementedIngestionServiceServer()
+}
+
+// UnimplementedIngestionServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedIngestionServiceServer struct{}
+
+func (UnimplementedIngestionServiceServer) SendBatch(context.Context, *SendBatchRequest) (*SendBatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendBatch not implemented")
+}
+func (UnimplementedIngestionServiceServer) SendQuizEvent(context.Context, *SendEventRequest) (*SendEventResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendQuizEvent not implemented")
+}
+func (UnimplementedIngestionServiceServer) SendUserEvent(context.Context, *SendEventRequest) (*SendEventResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendUserEvent not implemented")
+}
+func (UnimplementedIngestionServiceServer) SendSystemEvent(context.Context, *SendEventRequest) (*SendEventResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendSystemEvent not implemented")
+}
+func (UnimplementedIngestionServiceServer) UploadEvents(grpc.ClientStreamingServer[UploadEventsRequest, SendBatchResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method UploadEvents not implemented")
+}
+func (UnimplementedIngestionServiceServer) mustEmbedUnimplementedIngestionServiceServer() {}
+func (UnimplementedIngestionServiceServer) testEmbeddedByValue()                          {}
+
+// UnsafeIngestionServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to IngestionServiceServer will
+// result in compilation errors.
+type UnsafeIngestionServiceServer interface {
+	mustEmbedUnimplementedIngestionServiceServer()
+}
+
+func RegisterIngestionServiceServer(s grpc.ServiceRegistrar, srv IngestionServiceServer) {
+	// If the following call pancis, it indicates UnimplementedIngestionServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&IngestionService_ServiceDesc, srv)
+}
+
+func _IngestionService_SendBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IngestionServiceServer).SendBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IngestionService_SendBatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IngestionServiceServer).SendBatch(ctx, req.(*SendBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IngestionService_SendQuizEvent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendEventRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IngestionServiceServer).SendQuizEvent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IngestionService_SendQuizEvent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IngestionServiceServer).SendQuizEvent(ctx, req.(*SendEventRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IngestionService_SendUserEvent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendEventRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IngestionServiceServer).SendUserEvent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IngestionService_SendUserEvent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IngestionServiceServer).SendUserEvent(ctx, req.(*SendEventRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IngestionService_SendSystemEvent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendEventRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IngestionServiceServer).SendSystemEvent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IngestionService_SendSystemEvent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IngestionServiceServer).SendSystemEvent(ctx, req.(*SendEventRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IngestionService_UploadEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(IngestionServiceServer).UploadEvents(&grpc.GenericServerStream[UploadEventsRequest, SendBatchResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type IngestionService_UploadEventsServer = grpc.ClientStreamingServer[UploadEventsRequest, SendBatchResponse]
+
+// IngestionService_ServiceDesc is the grpc.ServiceDesc for IngestionService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var IngestionService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "dashbeam.ingestion.v1.IngestionService",
+	HandlerType: (*IngestionServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SendBatch",
+			Handler:    _IngestionService_SendBatch_Handler,
+		},
+		{
+			MethodName: "SendQuizEvent",
+			Handler:    _IngestionService_SendQuizEvent_Handler,
+		},
+		{
+			MethodName: "SendUserEvent",
+			Handler:    _IngestionService_SendUserEvent_Handler,
+		},
+		{
+			MethodName: "SendSystemEvent",
+			Handler:    _IngestionService_SendSystemEvent_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "UploadEvents",
+			Handler:       _IngestionService_UploadEvents_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "services/ingestion/ingestionpb/ingestion.proto",
+}
@@ -0,0 +1,255 @@
+package ingestion
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+
+	"github.com/lavish-gambhir/dashbeam/pkg/apperr"
+	"github.com/lavish-gambhir/dashbeam/pkg/utils"
+	sharedcontext "github.com/lavish-gambhir/dashbeam/shared/context"
+	"github.com/lavish-gambhir/dashbeam/shared/streaming"
+)
+
+const (
+	liveWriteTimeout      = 10 * time.Second
+	liveDisconnectTimeout = 5 * time.Second
+)
+
+var liveUpgrader = websocket.Upgrader{
+	Error: func(w http.ResponseWriter, r *http.Request, status int, reason error) {
+		utils.WriteJSONError(w, apperr.New(apperr.BadRequest, reason.Error()), status)
+	},
+}
+
+// handleLiveEvents upgrades to a WebSocket over which a device sends events
+// as they happen during a live quiz, one streaming.Event JSON per text
+// message, without a request per event. Each event is processed like one of
+// a batch and acked, in order, with its EventResult.
+//
+// The server pings every live_ping_interval, and closes a socket from which
+// nothing, not even a pong, was heard for two intervals. While the socket is
+// open its user is tracked as connected to the quiz sessions its events
+// belong to, and marked disconnected from them when it closes. The socket is
+// closed when the token expires.
+func (h *handler) handleLiveEvents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID, _ := sharedcontext.GetRequestID(ctx)
+	logger := h.logger.With("fn", "handleLiveEvents").With("requestID", reqID)
+
+	userContext, ok := sharedcontext.GetUserContext(ctx)
+	if !ok {
+		logger.Error("user context not found - middleware not applied correctly")
+		utils.WriteJSONError(w, apperr.New(apperr.Unauthorized, "authentication context missing"), http.StatusUnauthorized)
+		return
+	}
+	logger = logger.With("userID", userContext.UserID.String()).With("schoolID", userContext.SchoolID.String())
+
+	conn, err := liveUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		logger.Warn("websocket upgrade failed", slog.Any("error", err))
+		return
+	}
+	defer conn.Close()
+	conn.SetReadLimit(int64(h.config.StreamMaxLineBytes))
+
+	liveConnections.Add(1)
+	defer liveConnections.Add(-1)
+	logger.Info("live socket opened")
+
+	live := &liveSocket{
+		h:        h,
+		conn:     conn,
+		userID:   userContext.UserID.String(),
+		interval: h.config.LivePingInterval,
+		logger:   logger,
+		sessions: make(map[uuid.UUID]struct{}),
+	}
+	live.run(ctx, userContext.ExpiresAt)
+}
+
+// liveSocket is the state of one live socket. Apart from pinging and closing
+// on token expiry, it is only used by the goroutine reading the socket.
+type liveSocket struct {
+	h        *handler
+	conn     *websocket.Conn
+	userID   string
+	interval time.Duration
+	logger   *slog.Logger
+
+	// Once the token expired, events are no longer processed and the socket
+	// is closed by closeBy at the latest.
+	expired atomic.Bool
+	closeBy time.Time
+
+	sessions   map[uuid.UUID]struct{} // quiz sessions the user is connected to
+	lastMarked time.Time              // when sessions were last marked connected
+}
+
+func (l *liveSocket) run(ctx context.Context, tokenExpiresAt time.Time) {
+	done := make(chan struct{})
+	defer close(done)
+	l.closeBy = tokenExpiresAt.Add(liveDisconnectTimeout)
+	go l.ping(done, tokenExpiresAt)
+
+	l.conn.SetPongHandler(func(string) error {
+		l.alive(ctx)
+		return nil
+	})
+	l.alive(ctx)
+	defer l.disconnect(ctx)
+
+	for seq := 0; ; seq++ {
+		messageType, data, err := l.conn.ReadMessage()
+		if err != nil {
+			l.closed(err)
+			return
+		}
+		if l.expired.Load() {
+			// Only the client's answer to the close is waited for.
+			continue
+		}
+		l.alive(ctx)
+		if messageType != websocket.TextMessage {
+			l.close(websocket.CloseUnsupportedData, "events must be sent as text messages")
+			return
+		}
+
+		result, event := l.h.processRawEventWith(ctx, EventResult{Index: seq}, data)
+		if event != nil && result.Status != EventRejected {
+			l.track(ctx, event)
+		}
+
+		ack, err := json.Marshal(result)
+		if err != nil {
+			l.logger.Error("failed to encode ack", slog.Any("error", err))
+			l.close(websocket.CloseInternalServerErr, "")
+			return
+		}
+		l.conn.SetWriteDeadline(time.Now().Add(liveWriteTimeout))
+		if err := l.conn.WriteMessage(websocket.TextMessage, ack); err != nil {
+			l.logger.Warn("failed to ack event", slog.Any("error", err))
+			return
+		}
+	}
+}
+
+// ping sends heartbeats until done, and closes the socket once the token
+// expired.
+func (l *liveSocket) ping(done <-chan struct{}, tokenExpiresAt time.Time) {
+	ticker := time.NewTicker(l.interval)
+	defer ticker.Stop()
+	expired := time.NewTimer(time.Until(tokenExpiresAt))
+	defer expired.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if err := l.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(liveWriteTimeout)); err != nil {
+				return
+			}
+		case <-expired.C:
+			l.expired.Store(true)
+			l.close(websocket.ClosePolicyViolation, "token expired")
+			// Give the client a moment to answer the close.
+			l.conn.SetReadDeadline(time.Now().Add(liveDisconnectTimeout))
+			return
+		}
+	}
+}
+
+// alive is called whenever the client is heard from. The sessions it is in
+// are marked connected at most once per ping interval. Once the token
+// expired, the client is no longer given more time.
+func (l *liveSocket) alive(ctx context.Context) {
+	if l.expired.Load() {
+		return
+	}
+	now := time.Now()
+	// Capped in case the token expires while the deadline is being set.
+	deadline := now.Add(2 * l.interval)
+	if deadline.After(l.closeBy) {
+		deadline = l.closeBy
+	}
+	l.conn.SetReadDeadline(deadline)
+	if now.Sub(l.lastMarked) < l.interval {
+		return
+	}
+	l.lastMarked = now
+	for sessionID := range l.sessions {
+		l.markConnected(ctx, sessionID, now)
+	}
+}
+
+// track follows the quiz session of an accepted event: the user is connected
+// to it until it completes or is abandoned.
+func (l *liveSocket) track(ctx context.Context, event *streaming.Event) {
+	sessionID, ok := event.QuizSessionID()
+	if !ok {
+		return
+	}
+	switch event.Type {
+	case streaming.QuizSessionCompleted, streaming.QuizSessionAbandoned:
+		delete(l.sessions, sessionID)
+		return
+	}
+	if _, ok := l.sessions[sessionID]; !ok {
+		l.sessions[sessionID] = struct{}{}
+		l.markConnected(ctx, sessionID, time.Now())
+	}
+}
+
+func (l *liveSocket) markConnected(ctx context.Context, sessionID uuid.UUID, at time.Time) {
+	err := l.h.quizRepo.MarkParticipantConnected(ctx, sessionID.String(), l.userID, at.UTC())
+	if err != nil && !apperr.Is(err, apperr.DBRecordNotFound) {
+		l.logger.Warn("failed to mark participant connected", "session_id", sessionID.String(), "error", err)
+	}
+}
+
+// disconnect marks the user disconnected from the sessions it was in.
+func (l *liveSocket) disconnect(ctx context.Context) {
+	if len(l.sessions) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), liveDisconnectTimeout)
+	defer cancel()
+
+	now := time.Now().UTC()
+	for sessionID := range l.sessions {
+		err := l.h.quizRepo.MarkParticipantDisconnected(ctx, sessionID.String(), l.userID, now)
+		if err != nil && !apperr.Is(err, apperr.DBRecordNotFound) {
+			l.logger.Warn("failed to mark participant disconnected", "session_id", sessionID.String(), "error", err)
+		}
+	}
+}
+
+func (l *liveSocket) closed(err error) {
+	var closeErr *websocket.CloseError
+	var netErr net.Error
+	switch {
+	case errors.As(err, &closeErr):
+		l.logger.Info("live socket closed", slog.Int("code", closeErr.Code))
+	case errors.As(err, &netErr) && netErr.Timeout():
+		l.logger.Info("live socket timed out")
+		l.close(websocket.CloseGoingAway, "heartbeat timeout")
+	default:
+		l.logger.Warn("live socket failed", slog.Any("error", err))
+	}
+}
+
+// close starts the closing handshake. It is safe to call from the pinging
+// goroutine.
+func (l *liveSocket) close(code int, reason string) {
+	message := websocket.FormatCloseMessage(code, reason)
+	l.conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(liveWriteTimeout))
+}
@@ -0,0 +1,40 @@
+package ingestion
+
+import (
+	"expvar"
+	"fmt"
+
+	"github.com/lavish-gambhir/dashbeam/shared/streaming"
+)
+
+// Ingestion counters, published with expvar (see GET /admin/metrics).
+var (
+	// eventsBySchemaVersion counts decoded events by "<event type>/v<schema
+	// version>" as sent, before upcasting. Once an old version stops showing
+	// up, its upcaster can be retired.
+	eventsBySchemaVersion = expvar.NewMap("ingestion_events_by_schema_version")
+
+	// eventsUpcast counts events by type whose payload was upcast from an
+	// older schema version.
+	eventsUpcast = expvar.NewMap("ingestion_events_upcast")
+
+	// eventsSkewCorrected counts events whose timestamp was corrected for
+	// device clock skew, and eventsSkewSuspect those of them whose skew was
+	// above max_clock_skew.
+	eventsSkewCorrected = expvar.NewInt("ingestion_events_skew_corrected")
+	eventsSkewSuspect   = expvar.NewInt("ingestion_events_skew_suspect")
+
+	// eventsRateLimited counts events by type rejected by rate limits.
+	eventsRateLimited = expvar.NewMap("ingestion_events_rate_limited")
+
+	// liveConnections is the number of open live sockets.
+	liveConnections = expvar.NewInt("ingestion_live_connections")
+)
+
+func recordSchemaVersion(event *streaming.Event) {
+	received := event.ReceivedSchemaVersion()
+	eventsBySchemaVersion.Add(fmt.Sprintf("%s/v%d", event.Type, received), 1)
+	if received < event.SchemaVersion {
+		eventsUpcast.Add(event.Type.String(), 1)
+	}
+}
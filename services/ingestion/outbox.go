@@ -0,0 +1,150 @@
+package ingestion
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/lavish-gambhir/dashbeam/services/ingestion/repository"
+	"github.com/lavish-gambhir/dashbeam/shared/config"
+	"github.com/lavish-gambhir/dashbeam/shared/streaming"
+)
+
+const outboxPurgeInterval = time.Hour
+
+// OutboxRelay publishes the events that ingestion added to the outbox along
+// with their operational updates. Delivery is at least once: an entry is
+// marked published in the transaction that claimed it, after the publish, so
+// a crash in between publishes it again. Consumers dedupe by event ID.
+type OutboxRelay struct {
+	outboxRepo   repository.Outbox
+	transactor   repository.Transactor
+	messageQueue streaming.MessageQueue
+	config       config.IngestionConfig
+	logger       *slog.Logger
+}
+
+func NewOutboxRelay(
+	outboxRepo repository.Outbox,
+	transactor repository.Transactor,
+	messageQueue streaming.MessageQueue,
+	config config.IngestionConfig,
+	logger *slog.Logger,
+) *OutboxRelay {
+	return &OutboxRelay{
+		outboxRepo:   outboxRepo,
+		transactor:   transactor,
+		messageQueue: messageQueue,
+		config:       withDefaults(config),
+		logger:       logger.With("component", "outbox_relay"),
+	}
+}
+
+// Run relays outbox entries until ctx is cancelled, polling every
+// OutboxPollInterval and draining the backlog in OutboxBatchSize batches.
+// Published entries are deleted once older than OutboxRetention.
+func (r *OutboxRelay) Run(ctx context.Context) {
+	poll := time.NewTicker(r.config.OutboxPollInterval)
+	defer poll.Stop()
+	purge := time.NewTicker(outboxPurgeInterval)
+	defer purge.Stop()
+
+	r.logger.Info("outbox relay started", slog.Duration("poll_interval", r.config.OutboxPollInterval))
+	for {
+		select {
+		case <-ctx.Done():
+			r.logger.Info("outbox relay stopped")
+			return
+		case <-poll.C:
+			r.drain(ctx)
+		case <-purge.C:
+			r.purge(ctx)
+		}
+	}
+}
+
+// drain relays batches until the outbox is empty or a batch fails.
+func (r *OutboxRelay) drain(ctx context.Context) {
+	for ctx.Err() == nil {
+		n, err := r.relayBatch(ctx)
+		if err != nil {
+			r.logger.Error("failed to relay outbox entries", slog.Any("err", err))
+			return
+		}
+		if n < r.config.OutboxBatchSize {
+			return
+		}
+	}
+}
+
+// relayBatch publishes one batch of pending entries in order and returns how
+// many entries it claimed. It stops at the first failed publish, leaving the
+// rest pending for the next poll.
+func (r *OutboxRelay) relayBatch(ctx context.Context) (int, error) {
+	txCtx, err := r.transactor.TransactionContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			if err := r.transactor.Rollback(txCtx); err != nil {
+				r.logger.Warn("failed to roll back transaction", slog.Any("err", err))
+			}
+		}
+	}()
+
+	entries, err := r.outboxRepo.ClaimPending(txCtx, r.config.OutboxBatchSize)
+	if err != nil {
+		return 0, err
+	}
+	if len(entries) == 0 {
+		return 0, nil
+	}
+
+	published := make([]int64, 0, len(entries))
+	var publishErr error
+	for _, entry := range entries {
+		var event streaming.Event
+		if err := json.Unmarshal(entry.Event, &event); err != nil {
+			// Retrying won't help; keep it out of the way of later entries.
+			r.logger.Error("failed to decode outbox entry, marking failed", slog.Int64("entry_id", entry.ID), slog.String("event_id", entry.EventID.String()), slog.Any("err", err))
+			if err := r.outboxRepo.MarkFailed(txCtx, entry.ID, err.Error()); err != nil {
+				return 0, err
+			}
+			continue
+		}
+		if publishErr = r.messageQueue.Publish(ctx, entry.Topic, event); publishErr != nil {
+			break
+		}
+		published = append(published, entry.ID)
+	}
+
+	if err := r.outboxRepo.MarkPublished(txCtx, published); err != nil {
+		return 0, err
+	}
+	if err := r.transactor.Commit(txCtx); err != nil {
+		return 0, err
+	}
+	committed = true
+
+	if len(published) > 0 {
+		r.logger.Debug("relayed outbox entries", slog.Int("count", len(published)))
+	}
+	if publishErr != nil {
+		return len(entries), publishErr
+	}
+	return len(entries), nil
+}
+
+func (r *OutboxRelay) purge(ctx context.Context) {
+	deleted, err := r.outboxRepo.DeletePublished(ctx, time.Now().UTC().Add(-r.config.OutboxRetention))
+	if err != nil {
+		r.logger.Error("failed to purge published outbox entries", slog.Any("err", err))
+		return
+	}
+	if deleted > 0 {
+		r.logger.Info("purged published outbox entries", slog.Int64("count", deleted))
+	}
+}
@@ -2,65 +2,116 @@ package ingestion
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/lavish-gambhir/dashbeam/pkg/apperr"
+	sharedcontext "github.com/lavish-gambhir/dashbeam/shared/context"
 	"github.com/lavish-gambhir/dashbeam/shared/models"
 	"github.com/lavish-gambhir/dashbeam/shared/streaming"
 )
 
-// processBatchEvents processes multiple events in a batch
-func (h *handler) processBatchEvents(ctx context.Context, events []streaming.Event) ([]string, error) {
-	var eventIDs []string
+// processBatchEvents processes each event of a batch on its own and returns
+// one result per event, in request order. A rejected event does not affect
+// the others.
+func (h *handler) processBatchEvents(ctx context.Context, rawEvents []json.RawMessage) []EventResult {
+	results := make([]EventResult, 0, len(rawEvents))
+	for i, raw := range rawEvents {
+		results = append(results, h.processRawEvent(ctx, EventResult{Index: i}, raw))
+	}
+	return results
+}
 
-	for i, event := range events {
-		if event.ID == uuid.Nil {
-			id, err := uuid.NewV7()
-			if err != nil {
-				return nil, apperr.Wrapf(err, apperr.Internal, "failed to generate event ID for batch item %d", i)
-			}
-			event.ID = id
-			events[i] = event
-		}
+// processRawEvent decodes, validates and accepts one event of a multi-event
+// request, filling in result.
+func (h *handler) processRawEvent(ctx context.Context, result EventResult, raw []byte) EventResult {
+	result, _ = h.processRawEventWith(ctx, result, raw)
+	return result
+}
 
-		if event.Timestamp.IsZero() {
-			event.Timestamp = time.Now().UTC()
-			events[i] = event
+// processRawEventWith is processRawEvent, also returning the event as
+// accepted, if it could be decoded.
+func (h *handler) processRawEventWith(ctx context.Context, result EventResult, raw []byte) (EventResult, *streaming.Event) {
+	var event streaming.Event
+	if err := json.Unmarshal(raw, &event); err != nil {
+		// Still echo the ID back if it can be read.
+		var ref struct {
+			ID string `json:"event_id"`
 		}
-
-		if err := event.Validate(); err != nil {
-			return nil, apperr.Wrapf(err, apperr.ValidationFailed, "validation failed for batch item %d", i)
-		}
-
-		if err := event.Payload.Validate(); err != nil {
-			return nil, apperr.Wrapf(err, apperr.ValidationFailed, "payload validation failed for batch item %d", i)
+		if json.Unmarshal(raw, &ref) == nil {
+			result.EventID = ref.ID
 		}
+		return rejectedResult(result, apperr.Wrapf(err, apperr.JSONDecodingFailed, "invalid event at item %d", result.Index)), nil
+	}
+	return h.processEvent(ctx, result, &event), &event
+}
 
-		if err := h.processOperationalData(ctx, event); err != nil {
-			h.logger.Warn("failed to update operational data", "event_id", event.ID.String(), "error", err)
-			// do not fail: Continue processing other events
+// processEvent validates and accepts one decoded event of a multi-event
+// request, filling in result.
+func (h *handler) processEvent(ctx context.Context, result EventResult, event *streaming.Event) EventResult {
+	h.clock.correct(event, time.Now().UTC())
+	if err := prepareEvent(event); err != nil {
+		if event.ID != uuid.Nil {
+			result.EventID = event.ID.String()
 		}
+		return rejectedResult(result, err)
+	}
+	result.EventID = event.ID.String()
+	if err := h.limits.take(ctx, event); err != nil {
+		return rejectedResult(result, err)
+	}
+	rewritten, err := h.authorizeEvent(ctx, event)
+	if err != nil {
+		return rejectedResult(result, err)
+	}
+	result.Rewritten = rewritten
+	h.enrichers.enrich(ctx, event)
 
-		// Publish event to message queue
-		topic := streaming.GetTopicForEventType(event.Type)
-		if err := h.messageQueue.Publish(ctx, topic, event); err != nil {
-			h.logger.Error("failed to publish event", "event_id", event.ID.String(), "topic", topic, "error", err)
-			// do not fail: continue processing other events for batch
-		}
+	duplicate, err := h.acceptEvent(ctx, *event)
+	switch {
+	case err != nil:
+		h.logger.Error("failed to accept event", "event_id", event.ID.String(), "error", err)
+		result = rejectedResult(result, err)
+	case duplicate:
+		result.Status = EventDuplicate
+	default:
+		result.Status = EventAccepted
+	}
+	return result
+}
 
-		eventIDs = append(eventIDs, event.ID.String())
+func (h *handler) processSingleEvent(ctx context.Context, event streaming.Event) (string, bool, error) {
+	h.clock.correct(&event, time.Now().UTC())
+	if err := prepareEvent(&event); err != nil {
+		return "", false, err
+	}
+	if err := h.limits.take(ctx, &event); err != nil {
+		return "", false, err
 	}
+	if _, err := h.authorizeEvent(ctx, &event); err != nil {
+		return "", false, err
+	}
+	h.enrichers.enrich(ctx, &event)
 
-	return eventIDs, nil
+	duplicate, err := h.acceptEvent(ctx, event)
+	if err != nil {
+		return "", false, err
+	}
+	return event.ID.String(), duplicate, nil
 }
 
-func (h *handler) processSingleEvent(ctx context.Context, event streaming.Event) (string, error) {
+// prepareEvent fills in a missing ID and timestamp and validates the event.
+func prepareEvent(event *streaming.Event) error {
+	recordSchemaVersion(event)
+
 	if event.ID == uuid.Nil {
 		id, err := uuid.NewV7()
 		if err != nil {
-			return "", apperr.Wrap(err, apperr.Internal, "failed to generate event ID")
+			return apperr.Wrap(err, apperr.Internal, "failed to generate event ID")
 		}
 		event.ID = id
 	}
@@ -70,42 +121,228 @@ func (h *handler) processSingleEvent(ctx context.Context, event streaming.Event)
 	}
 
 	if err := event.Validate(); err != nil {
-		return "", apperr.Wrap(err, apperr.ValidationFailed, "event validation failed")
+		return apperr.Wrap(err, apperr.ValidationFailed, "event validation failed")
+	}
+	if event.Payload == nil {
+		return apperr.Wrap(streaming.ErrInvalidPayload, apperr.ValidationFailed, "event payload is missing")
 	}
 	if err := event.Payload.Validate(); err != nil {
-		return "", apperr.Wrapf(err, apperr.ValidationFailed, "%s: %s", "event payload validation failed for event", event.ID.String())
+		return apperr.Wrapf(err, apperr.ValidationFailed, "%s: %s", "event payload validation failed for event", event.ID.String())
+	}
+	return nil
+}
+
+// authorizeEvent applies the identity policy to event, logging rewrites and
+// rejections.
+func (h *handler) authorizeEvent(ctx context.Context, event *streaming.Event) ([]string, error) {
+	rewritten, err := h.identity.authorize(ctx, event)
+	if err != nil {
+		if apperr.Is(err, apperr.Forbidden) {
+			h.logger.Warn("rejecting event not matching token", "event_id", event.ID.String(), "error", err)
+		}
+		return nil, err
 	}
+	if len(rewritten) > 0 {
+		h.logger.Warn("rewrote event identity to match token", "event_id", event.ID.String(), "fields", rewritten)
+	}
+	return rewritten, nil
+}
 
-	// Process operational data updates
-	if err := h.processOperationalData(ctx, event); err != nil {
-		h.logger.Warn("failed to update operational data", "event_id", event.ID.String(), "error", err)
-		// Continue with event publishing
+// rejectedResult marks result as rejected with err. For client errors the
+// underlying cause is included, so the app can tell what to fix.
+func rejectedResult(result EventResult, err error) EventResult {
+	result.Status = EventRejected
+	result.ErrorCode = string(apperr.GetCode(err))
+	result.Error = apperr.GetMessage(err)
+	result.RetryAfter, _ = retryAfter(err)
+	switch apperr.GetCode(err) {
+	case apperr.ValidationFailed, apperr.JSONDecodingFailed:
+		if cause := errors.Unwrap(err); cause != nil {
+			result.Error = fmt.Sprintf("%s: %v", result.Error, cause)
+		}
 	}
+	return result
+}
 
-	// Publish event to message queue
+// acceptEvent applies the operational updates of a validated event and
+// publishes it, at most once per event ID. It reports true, and does
+// nothing, for an event that was already accepted.
+//
+// Resent events are caught by the dedupe window first; past the window, the
+// processed_events record written with the operational updates keeps them
+// from being applied twice, and the analytics sink skips events it has
+// already stored.
+func (h *handler) acceptEvent(ctx context.Context, event streaming.Event) (bool, error) {
+	fresh, err := h.dedup.Claim(ctx, event.ID)
+	if err != nil {
+		// Fail open: the processed_events record and the sink still dedupe.
+		h.logger.Warn("failed to check event for duplicates", "event_id", event.ID.String(), "error", err)
+		fresh = true
+	}
+	if !fresh {
+		h.logger.Info("dropping duplicate event", "event_id", event.ID.String(), "event_type", event.Type.String())
+		return true, nil
+	}
+
+	duplicate, err := h.applyAndPublish(ctx, event)
+	if err != nil {
+		// Let the client's retry through.
+		if relErr := h.dedup.Release(ctx, event.ID); relErr != nil {
+			h.logger.Warn("failed to release event claim", "event_id", event.ID.String(), "error", relErr)
+		}
+		return false, err
+	}
+	if duplicate {
+		h.logger.Info("dropping already processed event", "event_id", event.ID.String(), "event_type", event.Type.String())
+	}
+	return duplicate, nil
+}
+
+// applyAndPublish records the event in processed_events, applies its
+// operational updates and adds it to the outbox, all in one transaction. The
+// outbox relay publishes it once committed, so the updates and the event are
+// never out of step: an event whose updates fail on a database error is not
+// published either. Updates that find nothing to update are skipped.
+// Events without operational updates are published directly.
+func (h *handler) applyAndPublish(ctx context.Context, event streaming.Event) (bool, error) {
+	if !hasOperationalData(event.Type) {
+		return false, h.publishEvent(ctx, event)
+	}
+
+	txCtx, err := h.transactor.TransactionContext(ctx)
+	if err != nil {
+		return false, apperr.Wrap(err, apperr.DBQueryFailed, "failed to begin transaction")
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			if err := h.transactor.Rollback(txCtx); err != nil {
+				h.logger.Warn("failed to roll back transaction", "event_id", event.ID.String(), "error", err)
+			}
+		}
+	}()
+
+	fresh, err := h.processedRepo.MarkProcessed(txCtx, event.ID, event.Type.String())
+	if err != nil {
+		return false, err
+	}
+	if !fresh {
+		return true, nil
+	}
+
+	if err := h.processOperationalData(txCtx, event); err != nil {
+		if !skippableUpdate(err) {
+			// Rejected as a whole: nothing is recorded or published, and
+			// the claim is released so the client's retry gets another
+			// chance at the update.
+			h.logger.Warn("failed to update operational data", "event_id", event.ID.String(), "error", err)
+			return false, err
+		}
+		// The event itself is fine, e.g. a question shown before its
+		// session start arrived, or a second pause. Resending it would
+		// not change that, so it is still recorded and published.
+		h.logger.Warn("skipped operational update", "event_id", event.ID.String(), "event_type", event.Type.String(), "error", err)
+	}
+
+	if err := h.addToOutbox(txCtx, event); err != nil {
+		return false, err
+	}
+	if err := h.transactor.Commit(txCtx); err != nil {
+		return false, apperr.Wrapf(err, apperr.DBQueryFailed, "failed to commit operational data for event %s", event.ID)
+	}
+	committed = true
+	return false, nil
+}
+
+// skippableUpdate reports whether an operational update failed only because
+// the rows it updates are missing or in another state. Such errors do not
+// abort the transaction, unlike failed queries.
+func skippableUpdate(err error) bool {
+	return apperr.Is(err, apperr.DBRecordNotFound) || apperr.Is(err, apperr.Conflict)
+}
+
+func (h *handler) addToOutbox(ctx context.Context, event streaming.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return apperr.Wrapf(err, apperr.JSONEncodingFailed, "failed to marshal event %s", event.ID)
+	}
+	return h.outboxRepo.Add(ctx, event.ID, streaming.GetTopicForEventType(event.Type), data)
+}
+
+func (h *handler) publishEvent(ctx context.Context, event streaming.Event) error {
 	topic := streaming.GetTopicForEventType(event.Type)
 	if err := h.messageQueue.Publish(ctx, topic, event); err != nil {
-		return "", apperr.Wrapf(err, apperr.Internal, "failed to publish event to topic %s", topic)
+		return apperr.Wrapf(err, apperr.Internal, "failed to publish event to topic %s", topic)
 	}
+	return nil
+}
 
-	return event.ID.String(), nil
+// hasOperationalData reports whether processOperationalData acts on the event
+// type; keep the two in sync. Interaction, navigation, API and error events
+// are high-volume telemetry and only feed analytics.
+func hasOperationalData(eventType streaming.EventType) bool {
+	switch eventType {
+	case streaming.QuizSessionStarted, streaming.QuizQuestionShown, streaming.QuizAnswerSubmitted,
+		streaming.QuizSessionCompleted, streaming.QuizSessionAbandoned, streaming.QuizSessionPaused,
+		streaming.QuizSessionResumed,
+		streaming.UserLogin, streaming.UserLogout,
+		streaming.AppFocusChange, streaming.AppBackground, streaming.AppForeground,
+		streaming.SystemStartup, streaming.SystemShutdown:
+		return true
+	default:
+		return false
+	}
 }
 
 // processOperationalData updates operational database based on event type
 func (h *handler) processOperationalData(ctx context.Context, event streaming.Event) error {
 	switch event.Type {
+	case streaming.QuizSessionStarted:
+		return h.processQuizSessionStarted(ctx, event)
+	case streaming.QuizQuestionShown:
+		return h.processQuizQuestionShown(ctx, event)
 	case streaming.QuizAnswerSubmitted:
 		return h.processQuizAnswerSubmitted(ctx, event)
 	case streaming.QuizSessionCompleted:
 		return h.processQuizSessionCompleted(ctx, event)
+	case streaming.QuizSessionAbandoned:
+		return h.processQuizSessionAbandoned(ctx, event)
+	case streaming.QuizSessionPaused:
+		return h.processQuizSessionPaused(ctx, event)
+	case streaming.QuizSessionResumed:
+		return h.processQuizSessionResumed(ctx, event)
 	case streaming.UserLogin:
 		return h.processUserLogin(ctx, event)
+	case streaming.AppFocusChange:
+		return h.processAppFocusChange(ctx, event)
+	case streaming.UserLogout, streaming.AppBackground, streaming.AppForeground,
+		streaming.SystemStartup, streaming.SystemShutdown:
+		// Session boundaries: the user was last seen now.
+		return h.userRepo.UpdateUserLastSeen(ctx, event.UserID.String())
 	default:
 		// No operational data update needed for this event type
 		return nil
 	}
 }
 
+func (h *handler) processQuizSessionStarted(ctx context.Context, event streaming.Event) error {
+	payload, ok := event.Payload.(streaming.QuizSessionStartedPayload)
+	if !ok {
+		return apperr.New(apperr.ValidationFailed, "invalid payload type for quiz session started event")
+	}
+
+	return h.quizRepo.StartParticipantSession(ctx, payload.SessionID.String(), event.UserID.String(), event.Timestamp)
+}
+
+func (h *handler) processQuizQuestionShown(ctx context.Context, event streaming.Event) error {
+	payload, ok := event.Payload.(streaming.QuizQuestionShownPayload)
+	if !ok {
+		return apperr.New(apperr.ValidationFailed, "invalid payload type for quiz question shown event")
+	}
+
+	return h.quizRepo.RecordParticipantInteraction(ctx, payload.SessionID.String(), event.UserID.String(), false)
+}
+
 func (h *handler) processQuizAnswerSubmitted(ctx context.Context, event streaming.Event) error {
 	payload, ok := event.Payload.(streaming.QuizAnswerSubmittedPayload)
 	if !ok {
@@ -146,6 +383,50 @@ func (h *handler) processQuizSessionCompleted(ctx context.Context, event streami
 	)
 }
 
+func (h *handler) processQuizSessionAbandoned(ctx context.Context, event streaming.Event) error {
+	payload, ok := event.Payload.(streaming.QuizSessionAbandonedPayload)
+	if !ok {
+		return apperr.New(apperr.ValidationFailed, "invalid payload type for quiz session abandoned event")
+	}
+
+	return h.quizRepo.AbandonParticipantSession(ctx, payload.SessionID.String(), event.UserID.String(), event.Timestamp)
+}
+
+func (h *handler) processQuizSessionPaused(ctx context.Context, event streaming.Event) error {
+	payload, ok := event.Payload.(streaming.QuizSessionPausedPayload)
+	if !ok {
+		return apperr.New(apperr.ValidationFailed, "invalid payload type for quiz session paused event")
+	}
+
+	return h.quizRepo.PauseParticipantSession(ctx, payload.SessionID.String(), event.UserID.String(), event.Timestamp)
+}
+
+func (h *handler) processQuizSessionResumed(ctx context.Context, event streaming.Event) error {
+	payload, ok := event.Payload.(streaming.QuizSessionResumedPayload)
+	if !ok {
+		return apperr.New(apperr.ValidationFailed, "invalid payload type for quiz session resumed event")
+	}
+
+	return h.quizRepo.ResumeParticipantSession(
+		ctx,
+		payload.SessionID.String(),
+		event.UserID.String(),
+		payload.PausedDurationMS/1000, // Convert to seconds
+	)
+}
+
+func (h *handler) processAppFocusChange(ctx context.Context, event streaming.Event) error {
+	payload, ok := event.Payload.(streaming.AppFocusChangePayload)
+	if !ok {
+		return apperr.New(apperr.ValidationFailed, "invalid payload type for app focus change event")
+	}
+
+	if payload.QuizSessionID != nil && !payload.HasFocus {
+		return h.quizRepo.RecordParticipantInteraction(ctx, payload.QuizSessionID.String(), event.UserID.String(), true)
+	}
+	return h.userRepo.UpdateUserLastSeen(ctx, event.UserID.String())
+}
+
 func (h *handler) processUserLogin(ctx context.Context, event streaming.Event) error {
 	payload, ok := event.Payload.(streaming.UserLoginPayload)
 	if !ok {
@@ -164,10 +445,11 @@ func (h *handler) processUserLogin(ctx context.Context, event streaming.Event) e
 	if payload.Name != nil {
 		user.Name = *payload.Name
 	}
-	if payload.Role != nil {
-		user.Role = *payload.Role
-	} else {
-		user.Role = string(models.UserRoleStudent) // Default
+	// The role is the token's, never the client's. A user first seen in an
+	// event written on their behalf is a student until they log in.
+	user.Role = string(models.UserRoleStudent)
+	if uc, ok := sharedcontext.GetUserContext(ctx); ok && uc.UserID == event.UserID && uc.Role != "" {
+		user.Role = uc.Role
 	}
 
 	// Try to get existing user
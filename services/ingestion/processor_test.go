@@ -0,0 +1,258 @@
+package ingestion
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/lavish-gambhir/dashbeam/pkg/apperr"
+	"github.com/lavish-gambhir/dashbeam/services/ingestion/repository"
+	sharedcontext "github.com/lavish-gambhir/dashbeam/shared/context"
+	"github.com/lavish-gambhir/dashbeam/shared/models"
+	"github.com/lavish-gambhir/dashbeam/shared/streaming"
+)
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// fakeTransactor counts commits and rollbacks.
+type fakeTransactor struct {
+	commits, rollbacks int
+}
+
+func (t *fakeTransactor) TransactionContext(ctx context.Context) (context.Context, error) {
+	return ctx, nil
+}
+
+func (t *fakeTransactor) Commit(context.Context) error {
+	t.commits++
+	return nil
+}
+
+func (t *fakeTransactor) Rollback(context.Context) error {
+	t.rollbacks++
+	return nil
+}
+
+type fakeProcessed struct {
+	seen map[uuid.UUID]bool
+}
+
+func (p *fakeProcessed) MarkProcessed(_ context.Context, eventID uuid.UUID, _ string) (bool, error) {
+	if p.seen == nil {
+		p.seen = make(map[uuid.UUID]bool)
+	}
+	if p.seen[eventID] {
+		return false, nil
+	}
+	p.seen[eventID] = true
+	return true, nil
+}
+
+// fakeOutbox records the events added to it. Only Add is implemented.
+type fakeOutbox struct {
+	repository.Outbox
+	added []uuid.UUID
+}
+
+func (o *fakeOutbox) Add(_ context.Context, eventID uuid.UUID, _ string, _ []byte) error {
+	o.added = append(o.added, eventID)
+	return nil
+}
+
+// fakeUserRepo fails UpdateUserLastSeen with err, and keeps created users.
+type fakeUserRepo struct {
+	err     error
+	created []*models.User
+}
+
+func (r *fakeUserRepo) CreateUser(_ context.Context, user *models.User) error {
+	r.created = append(r.created, user)
+	return nil
+}
+
+func (r *fakeUserRepo) UpdateUser(context.Context, *models.User) error { return nil }
+
+func (r *fakeUserRepo) GetUserByID(context.Context, string) (*models.User, error) {
+	return nil, apperr.New(apperr.DBRecordNotFound, "user not found")
+}
+
+func (r *fakeUserRepo) UpdateUserLastSeen(context.Context, string) error { return r.err }
+
+// fakeQuizRepo fails every participant update with err. Only the updates
+// made by processOperationalData are implemented.
+type fakeQuizRepo struct {
+	repository.Quiz
+	err error
+}
+
+func (r *fakeQuizRepo) StartParticipantSession(context.Context, string, string, time.Time) error {
+	return r.err
+}
+
+func (r *fakeQuizRepo) RecordParticipantInteraction(context.Context, string, string, bool) error {
+	return r.err
+}
+
+func (r *fakeQuizRepo) PauseParticipantSession(context.Context, string, string, time.Time) error {
+	return r.err
+}
+
+func (r *fakeQuizRepo) ResumeParticipantSession(context.Context, string, string, int) error {
+	return r.err
+}
+
+func (r *fakeQuizRepo) AbandonParticipantSession(context.Context, string, string, time.Time) error {
+	return r.err
+}
+
+func newTestEvent(eventType streaming.EventType, payload streaming.EventPayload) streaming.Event {
+	id, _ := uuid.NewV7()
+	return streaming.Event{
+		ID:        id,
+		Type:      eventType,
+		Timestamp: time.Now().UTC(),
+		UserID:    uuid.New(),
+		SchoolID:  uuid.New(),
+		AppType:   streaming.AppTypeNote,
+		Metadata:  streaming.Metadata{AppVersion: "1.0.0", DeviceType: "tablet", DeviceID: "device-1"},
+		Payload:   payload,
+	}
+}
+
+func TestApplyAndPublish(t *testing.T) {
+	sessionID := uuid.New()
+	startup := newTestEvent(streaming.SystemStartup, streaming.SystemStartupPayload{ColdStart: true})
+	shown := newTestEvent(streaming.QuizQuestionShown, streaming.QuizQuestionShownPayload{
+		QuizID: uuid.New(), SessionID: sessionID, QuestionID: uuid.New(), QuestionSequence: 1,
+	})
+	paused := newTestEvent(streaming.QuizSessionPaused, streaming.QuizSessionPausedPayload{
+		QuizID: uuid.New(), SessionID: sessionID, Reason: "manual",
+	})
+
+	tests := []struct {
+		name      string
+		event     streaming.Event
+		err       error
+		wantErr   apperr.ErrCode
+		published bool
+	}{
+		{name: "updated", event: startup, published: true},
+		{name: "user missing", event: startup, err: apperr.New(apperr.DBRecordNotFound, "user not found"), published: true},
+		{name: "session not started", event: shown, err: apperr.New(apperr.DBRecordNotFound, "participant not found"), published: true},
+		{name: "already paused", event: paused, err: apperr.New(apperr.Conflict, "participant not active"), published: true},
+		{name: "query failed", event: startup, err: apperr.New(apperr.DBQueryFailed, "connection reset"), wantErr: apperr.DBQueryFailed},
+		{name: "quiz query failed", event: paused, err: apperr.New(apperr.DBQueryFailed, "connection reset"), wantErr: apperr.DBQueryFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := &fakeTransactor{}
+			outbox := &fakeOutbox{}
+			h := &handler{
+				userRepo:      &fakeUserRepo{err: tt.err},
+				quizRepo:      &fakeQuizRepo{err: tt.err},
+				processedRepo: &fakeProcessed{},
+				outboxRepo:    outbox,
+				transactor:    tx,
+				logger:        discardLogger(),
+			}
+
+			duplicate, err := h.applyAndPublish(context.Background(), tt.event)
+			if tt.wantErr != "" {
+				if !apperr.Is(err, tt.wantErr) {
+					t.Fatalf("applyAndPublish() error = %v, want %s", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("applyAndPublish() error = %v", err)
+			}
+			if duplicate {
+				t.Error("fresh event reported as duplicate")
+			}
+
+			if tt.published {
+				if tx.commits != 1 || tx.rollbacks != 0 {
+					t.Errorf("commits = %d, rollbacks = %d, want 1 commit", tx.commits, tx.rollbacks)
+				}
+				if len(outbox.added) != 1 || outbox.added[0] != tt.event.ID {
+					t.Errorf("outbox = %v, want %s", outbox.added, tt.event.ID)
+				}
+			} else {
+				if tx.commits != 0 || tx.rollbacks != 1 {
+					t.Errorf("commits = %d, rollbacks = %d, want 1 rollback", tx.commits, tx.rollbacks)
+				}
+			}
+		})
+	}
+}
+
+func TestAcceptEventReleasesClaimOnFailure(t *testing.T) {
+	ctx := context.Background()
+	users := &fakeUserRepo{err: apperr.New(apperr.DBQueryFailed, "connection reset")}
+	outbox := &fakeOutbox{}
+	h := &handler{
+		userRepo:      users,
+		processedRepo: &fakeProcessed{},
+		outboxRepo:    outbox,
+		transactor:    &fakeTransactor{},
+		dedup:         streaming.NewMemoryDeduplicator(time.Minute),
+		logger:        discardLogger(),
+	}
+	event := newTestEvent(streaming.SystemShutdown, streaming.SystemShutdownPayload{})
+
+	if _, err := h.acceptEvent(ctx, event); err == nil {
+		t.Fatal("acceptEvent() succeeded with a failing update")
+	}
+	// The processed_events record was rolled back with the update.
+	h.processedRepo = &fakeProcessed{}
+
+	users.err = nil
+	duplicate, err := h.acceptEvent(ctx, event)
+	if err != nil || duplicate {
+		t.Fatalf("retry: acceptEvent() = %v, %v, want accepted", duplicate, err)
+	}
+	if len(outbox.added) != 1 {
+		t.Errorf("outbox = %v, want the retried event", outbox.added)
+	}
+}
+
+func TestProcessUserLoginTakesRoleFromToken(t *testing.T) {
+	teacher := "teacher"
+	student := string(models.UserRoleStudent)
+	tests := []struct {
+		name      string
+		tokenRole string
+		onBehalf  bool
+		want      string
+	}{
+		{name: "student claiming teacher", tokenRole: student, want: student},
+		{name: "teacher", tokenRole: teacher, want: teacher},
+		{name: "written on behalf of the user", tokenRole: teacher, onBehalf: true, want: student},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &fakeUserRepo{}
+			h := &handler{userRepo: users, logger: discardLogger()}
+			event := newTestEvent(streaming.UserLogin, streaming.UserLoginPayload{LoginMethod: "password", Role: &teacher})
+
+			token := &models.UserContext{UserID: event.UserID, SchoolID: event.SchoolID, Role: tt.tokenRole}
+			if tt.onBehalf {
+				token.UserID = uuid.New()
+			}
+			ctx := sharedcontext.WithUserContext(context.Background(), token)
+			if err := h.processUserLogin(ctx, event); err != nil {
+				t.Fatal(err)
+			}
+			if len(users.created) != 1 {
+				t.Fatalf("created %d users, want 1", len(users.created))
+			}
+			if got := users.created[0].Role; got != tt.want {
+				t.Errorf("role = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
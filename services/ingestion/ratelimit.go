@@ -0,0 +1,178 @@
+package ingestion
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/lavish-gambhir/dashbeam/pkg/apperr"
+	"github.com/lavish-gambhir/dashbeam/services/ingestion/repository"
+	"github.com/lavish-gambhir/dashbeam/shared/config"
+	sharedcontext "github.com/lavish-gambhir/dashbeam/shared/context"
+	"github.com/lavish-gambhir/dashbeam/shared/ratelimit"
+	"github.com/lavish-gambhir/dashbeam/shared/streaming"
+)
+
+const (
+	rateLimitKeyPrefix  = "ratelimit:"
+	defaultTierCacheTTL = 5 * time.Minute
+)
+
+// RateLimitDetails are the details of a RATE_LIMITED error.
+type RateLimitDetails struct {
+	RetryAfter int `json:"retry_after"` // seconds
+}
+
+// rateLimiter limits the events accepted per device, user and school with
+// token buckets, one event per token. The limits are those of the school's
+// subscription tier, plus those of the event type within the tier. An event
+// takes a token from every bucket it falls in, or from none if one is empty.
+//
+// User and school are those of the token, so that a client can't spread its
+// events over buckets by changing them. If the limiter is unavailable events
+// are let through.
+type rateLimiter struct {
+	limiter     ratelimit.Limiter
+	schools     repository.School
+	tiers       map[string]config.RateLimitTier
+	defaultTier string
+	tierTTL     time.Duration
+	logger      *slog.Logger
+
+	mu          sync.Mutex
+	schoolTiers map[uuid.UUID]cachedTier
+}
+
+type cachedTier struct {
+	tier    string
+	expires time.Time
+}
+
+// newRateLimiter returns nil when rate limits are disabled.
+func newRateLimiter(cfg config.RateLimitConfig, limiter ratelimit.Limiter, schools repository.School, logger *slog.Logger) (*rateLimiter, error) {
+	if !cfg.Enabled || limiter == nil {
+		return nil, nil
+	}
+	if _, ok := cfg.Tiers[cfg.DefaultTier]; !ok {
+		return nil, fmt.Errorf("rate limit default tier %q has no limits", cfg.DefaultTier)
+	}
+	l := &rateLimiter{
+		limiter:     limiter,
+		schools:     schools,
+		tiers:       cfg.Tiers,
+		defaultTier: cfg.DefaultTier,
+		tierTTL:     cfg.TierCacheTTL,
+		logger:      logger,
+		schoolTiers: make(map[uuid.UUID]cachedTier),
+	}
+	if l.tierTTL <= 0 {
+		l.tierTTL = defaultTierCacheTTL
+	}
+	return l, nil
+}
+
+// take takes a token for event, or returns a RATE_LIMITED error telling when
+// to retry. It runs on validated events, which always have a device ID.
+func (l *rateLimiter) take(ctx context.Context, event *streaming.Event) error {
+	if l == nil {
+		return nil
+	}
+
+	userID, schoolID := event.UserID, event.SchoolID
+	if user, ok := sharedcontext.GetUserContext(ctx); ok {
+		userID, schoolID = user.UserID, user.SchoolID
+	}
+
+	tier := l.tierLimits(ctx, schoolID)
+	// Keys share the school as hash tag, so all buckets of an event are in
+	// the same cluster slot.
+	prefix := fmt.Sprintf("%s{%s}:", rateLimitKeyPrefix, schoolID)
+	deviceKey := prefix + "device:" + event.Metadata.DeviceID
+	userKey := prefix + "user:" + userID.String()
+	schoolKey := prefix + "school"
+
+	buckets := []ratelimit.Bucket{
+		{Key: deviceKey, Limit: bucketLimit(tier.Device)},
+		{Key: userKey, Limit: bucketLimit(tier.User)},
+		{Key: schoolKey, Limit: bucketLimit(tier.School)},
+	}
+	for _, limits := range tier.EventTypes {
+		if limits.EventType != event.Type.String() {
+			continue
+		}
+		suffix := ":" + limits.EventType
+		buckets = append(buckets,
+			ratelimit.Bucket{Key: deviceKey + suffix, Limit: bucketLimit(limits.Device)},
+			ratelimit.Bucket{Key: userKey + suffix, Limit: bucketLimit(limits.User)},
+			ratelimit.Bucket{Key: schoolKey + suffix, Limit: bucketLimit(limits.School)},
+		)
+	}
+
+	wait, err := l.limiter.Take(ctx, buckets, 1)
+	if err != nil {
+		l.logger.Warn("rate limiter unavailable, letting event through", "event_id", event.ID.String(), "error", err)
+		return nil
+	}
+	if wait <= 0 {
+		return nil
+	}
+	eventsRateLimited.Add(event.Type.String(), 1)
+	retryAfter := int(math.Ceil(wait.Seconds()))
+	return apperr.Newf(apperr.RateLimited, "rate limit exceeded; retry after %ds", retryAfter).
+		WithDetails(RateLimitDetails{RetryAfter: retryAfter})
+}
+
+// tierLimits returns the limits of the school's subscription tier.
+func (l *rateLimiter) tierLimits(ctx context.Context, schoolID uuid.UUID) config.RateLimitTier {
+	now := time.Now()
+	l.mu.Lock()
+	cached, ok := l.schoolTiers[schoolID]
+	l.mu.Unlock()
+
+	if !ok || now.After(cached.expires) {
+		tier, err := l.schools.SubscriptionTier(ctx, schoolID)
+		if err != nil {
+			l.logger.Warn("failed to look up school subscription tier", "school_id", schoolID.String(), "error", err)
+			tier = l.defaultTier
+		}
+		cached = cachedTier{tier: tier, expires: now.Add(l.tierTTL)}
+		l.mu.Lock()
+		l.schoolTiers[schoolID] = cached
+		l.mu.Unlock()
+	}
+
+	if limits, ok := l.tiers[cached.tier]; ok {
+		return limits
+	}
+	return l.tiers[l.defaultTier]
+}
+
+func bucketLimit(limit config.RateLimit) ratelimit.Limit {
+	burst := limit.Burst
+	if burst <= 0 {
+		burst = max(1, int(math.Ceil(limit.Rate)))
+	}
+	return ratelimit.Limit{Rate: limit.Rate, Burst: burst}
+}
+
+// retryAfter returns the seconds to wait before retrying after err, if it
+// is a RATE_LIMITED error.
+func retryAfter(err error) (int, bool) {
+	appErr, ok := err.(*apperr.Error)
+	if !ok || appErr.Code != apperr.RateLimited {
+		return 0, false
+	}
+	details, ok := appErr.Details.(RateLimitDetails)
+	return details.RetryAfter, ok
+}
+
+func setRetryAfter(w http.ResponseWriter, seconds int) {
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+}
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type Classroom interface {
+	// IsActiveMember reports whether the user is an active member of the classroom
+	IsActiveMember(ctx context.Context, classroomID, userID uuid.UUID) (bool, error)
+
+	// QuizSessionClassroom returns the classroom a quiz session is held in, and its school
+	QuizSessionClassroom(ctx context.Context, sessionID uuid.UUID) (classroomID, schoolID uuid.UUID, err error)
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/lavish-gambhir/dashbeam/shared/models"
+)
+
+type Outbox interface {
+	// Add puts an event, encoded as JSON, in the outbox
+	Add(ctx context.Context, eventID uuid.UUID, topic string, event []byte) error
+
+	// ClaimPending locks and returns up to limit unpublished entries, oldest first
+	ClaimPending(ctx context.Context, limit int) ([]models.OutboxEntry, error)
+
+	// MarkPublished marks entries as published
+	MarkPublished(ctx context.Context, ids []int64) error
+
+	// MarkFailed takes an entry that can never be published out of the pending set
+	MarkFailed(ctx context.Context, id int64, reason string) error
+
+	// DeletePublished deletes entries published before the given time
+	DeletePublished(ctx context.Context, before time.Time) (int64, error)
+}
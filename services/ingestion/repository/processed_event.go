@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type ProcessedEvent interface {
+	// MarkProcessed records an event as processed, reporting false if it already was
+	MarkProcessed(ctx context.Context, eventID uuid.UUID, eventType string) (bool, error)
+}
+
+// Transactor runs repository calls in a transaction carried by the context
+type Transactor interface {
+	TransactionContext(ctx context.Context) (context.Context, error)
+	Commit(ctx context.Context) error
+	Rollback(ctx context.Context) error
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/lavish-gambhir/dashbeam/shared/models"
 )
@@ -18,4 +19,25 @@ type Quiz interface {
 
 	// CompleteParticipantSession marks a participant's session as completed
 	CompleteParticipantSession(ctx context.Context, sessionID, userID string, finalScore, maxScore float64, completionTimeSeconds int) error
+
+	// StartParticipantSession marks a participant as started, creating it if needed
+	StartParticipantSession(ctx context.Context, sessionID, userID string, startedAt time.Time) error
+
+	// RecordParticipantInteraction counts an interaction, optionally a focus loss
+	RecordParticipantInteraction(ctx context.Context, sessionID, userID string, focusLost bool) error
+
+	// PauseParticipantSession marks an active participant as paused
+	PauseParticipantSession(ctx context.Context, sessionID, userID string, pausedAt time.Time) error
+
+	// ResumeParticipantSession marks a paused participant as active again
+	ResumeParticipantSession(ctx context.Context, sessionID, userID string, pausedSeconds int) error
+
+	// AbandonParticipantSession marks a participant as having left without submitting
+	AbandonParticipantSession(ctx context.Context, sessionID, userID string, abandonedAt time.Time) error
+
+	// MarkParticipantConnected records a participant as connected, e.g. on a heartbeat
+	MarkParticipantConnected(ctx context.Context, sessionID, userID string, seenAt time.Time) error
+
+	// MarkParticipantDisconnected marks a participant taking the session as disconnected
+	MarkParticipantDisconnected(ctx context.Context, sessionID, userID string, disconnectedAt time.Time) error
 }
@@ -0,0 +1,12 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type School interface {
+	// SubscriptionTier returns the subscription tier of the school
+	SubscriptionTier(ctx context.Context, schoolID uuid.UUID) (string, error)
+}
@@ -3,47 +3,163 @@ package ingestion
 import (
 	"log/slog"
 	"net/http"
+	"time"
 
+	"google.golang.org/grpc"
+
+	"github.com/lavish-gambhir/dashbeam/services/ingestion/ingestionpb"
 	"github.com/lavish-gambhir/dashbeam/services/ingestion/repository"
+	"github.com/lavish-gambhir/dashbeam/shared/config"
+	"github.com/lavish-gambhir/dashbeam/shared/ratelimit"
 	"github.com/lavish-gambhir/dashbeam/shared/streaming"
 )
 
+const (
+	defaultMaxBatchSize       = 500
+	defaultStreamMaxBytes     = 64 << 20
+	defaultStreamMaxEvents    = 20000
+	defaultStreamMaxLineBytes = 1 << 20
+	defaultStreamReadTimeout  = 2 * time.Minute
+	defaultOutboxPollInterval = time.Second
+	defaultOutboxBatchSize    = 200
+	defaultOutboxRetention    = 24 * time.Hour
+	defaultMaxClockSkew       = 12 * time.Hour
+	defaultLivePingInterval   = 15 * time.Second
+)
+
 type Service interface {
 	RegisterRoutes(mux *http.ServeMux, prefix string)
+	RegisterGRPC(server *grpc.Server)
 }
 
 type service struct {
-	userRepo     repository.User
-	quizRepo     repository.Quiz
-	messageQueue streaming.MessageQueue
-	logger       *slog.Logger
-	maxBatchSize int
+	userRepo      repository.User
+	quizRepo      repository.Quiz
+	processedRepo repository.ProcessedEvent
+	classroomRepo repository.Classroom
+	outboxRepo    repository.Outbox
+	transactor    repository.Transactor
+	dedup         streaming.Deduplicator
+	messageQueue  streaming.MessageQueue
+	enrichers     *enricherChain
+	limits        *rateLimiter
+	logger        *slog.Logger
+	config        config.IngestionConfig
 }
 
-func New(userRepo repository.User, quizRepo repository.Quiz, messageQueue streaming.MessageQueue, logger *slog.Logger, maxBatchSize int) Service {
+func New(
+	userRepo repository.User,
+	quizRepo repository.Quiz,
+	processedRepo repository.ProcessedEvent,
+	classroomRepo repository.Classroom,
+	outboxRepo repository.Outbox,
+	schoolRepo repository.School,
+	transactor repository.Transactor,
+	dedup streaming.Deduplicator,
+	limiter ratelimit.Limiter,
+	messageQueue streaming.MessageQueue,
+	config config.IngestionConfig,
+	logger *slog.Logger,
+) (Service, error) {
+	config = withDefaults(config)
+	enrichers, err := newEnricherChain(config, logger.With("component", "enrichers"))
+	if err != nil {
+		return nil, err
+	}
+	limits, err := newRateLimiter(config.RateLimits, limiter, schoolRepo, logger.With("component", "rate_limiter"))
+	if err != nil {
+		return nil, err
+	}
 	return &service{
-		userRepo:     userRepo,
-		quizRepo:     quizRepo,
-		messageQueue: messageQueue,
-		logger:       logger,
-		maxBatchSize: maxBatchSize,
+		userRepo:      userRepo,
+		quizRepo:      quizRepo,
+		processedRepo: processedRepo,
+		classroomRepo: classroomRepo,
+		outboxRepo:    outboxRepo,
+		transactor:    transactor,
+		dedup:         dedup,
+		messageQueue:  messageQueue,
+		enrichers:     enrichers,
+		limits:        limits,
+		logger:        logger,
+		config:        config,
+	}, nil
+}
+
+// withDefaults fills in unset ingestion limits.
+func withDefaults(cfg config.IngestionConfig) config.IngestionConfig {
+	if cfg.MaxBatchSize <= 0 {
+		cfg.MaxBatchSize = defaultMaxBatchSize
+	}
+	if cfg.StreamMaxBytes <= 0 {
+		cfg.StreamMaxBytes = defaultStreamMaxBytes
+	}
+	if cfg.StreamMaxEvents <= 0 {
+		cfg.StreamMaxEvents = defaultStreamMaxEvents
+	}
+	if cfg.StreamMaxLineBytes <= 0 {
+		cfg.StreamMaxLineBytes = defaultStreamMaxLineBytes
+	}
+	if cfg.StreamReadTimeout <= 0 {
+		cfg.StreamReadTimeout = defaultStreamReadTimeout
+	}
+	if cfg.LivePingInterval <= 0 {
+		cfg.LivePingInterval = defaultLivePingInterval
 	}
+	if cfg.OutboxPollInterval <= 0 {
+		cfg.OutboxPollInterval = defaultOutboxPollInterval
+	}
+	if cfg.OutboxBatchSize <= 0 {
+		cfg.OutboxBatchSize = defaultOutboxBatchSize
+	}
+	if cfg.OutboxRetention <= 0 {
+		cfg.OutboxRetention = defaultOutboxRetention
+	}
+	if cfg.MaxClockSkew <= 0 {
+		cfg.MaxClockSkew = defaultMaxClockSkew
+	}
+	if len(cfg.Enrichers) == 0 {
+		cfg.Enrichers = defaultEnrichers
+	}
+	if cfg.IdentityMode == "" {
+		cfg.IdentityMode = IdentityReject
+	}
+	return cfg
+}
+
+func (s *service) newHandler() *handler {
+	return NewHandler(
+		s.userRepo,
+		s.quizRepo,
+		s.processedRepo,
+		s.classroomRepo,
+		s.outboxRepo,
+		s.transactor,
+		s.dedup,
+		s.messageQueue,
+		s.enrichers,
+		s.limits,
+		s.config,
+		s.logger,
+	)
 }
 
 // RegisterRoutes registers all ingestion service routes
 func (s *service) RegisterRoutes(parentmux *http.ServeMux, prefix string) {
-	h := &handler{
-		userRepo:     s.userRepo,
-		quizRepo:     s.quizRepo,
-		messageQueue: s.messageQueue,
-		logger:       s.logger,
-		maxBatchSize: s.maxBatchSize,
-	}
+	h := s.newHandler()
 
 	mux := http.NewServeMux()
-	mux.HandleFunc("/batch", h.handleBatchEvents)
-	mux.HandleFunc("/quiz", h.handleQuizEvent)
-	mux.HandleFunc("/user", h.handleUserEvent)
-	mux.HandleFunc("/system", h.handleSystemEvent)
+	mux.HandleFunc("/batch", withRequestInfo(h.handleBatchEvents))
+	mux.HandleFunc("/stream", withRequestInfo(h.handleStreamEvents))
+	mux.HandleFunc("/live", withRequestInfo(h.handleLiveEvents))
+	mux.HandleFunc("/quiz", withRequestInfo(h.handleQuizEvent))
+	mux.HandleFunc("/user", withRequestInfo(h.handleUserEvent))
+	mux.HandleFunc("/system", withRequestInfo(h.handleSystemEvent))
 	parentmux.Handle(prefix+"/", http.StripPrefix(prefix, mux))
 }
+
+// RegisterGRPC registers the ingestion gRPC service. The server must
+// authenticate calls like the HTTP routes are.
+func (s *service) RegisterGRPC(server *grpc.Server) {
+	ingestionpb.RegisterIngestionServiceServer(server, &grpcServer{h: s.newHandler()})
+}
@@ -0,0 +1,181 @@
+package ingestion
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/lavish-gambhir/dashbeam/pkg/apperr"
+	"github.com/lavish-gambhir/dashbeam/pkg/utils"
+	sharedcontext "github.com/lavish-gambhir/dashbeam/shared/context"
+)
+
+const ndjsonContentType = "application/x-ndjson"
+
+// handleStreamEvents accepts newline-delimited JSON events, optionally gzip or
+// zstd compressed, and processes each line as soon as it is decoded, so that
+// devices can upload a large offline backlog without building one JSON array.
+//
+// Limits are enforced while reading. When one is hit, the remaining lines are
+// not read and a final rejected result tells the client to resend them; the
+// lines before it were processed as reported.
+func (h *handler) handleStreamEvents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID, _ := sharedcontext.GetRequestID(ctx)
+	logger := h.logger.With("fn", "handleStreamEvents").With("requestID", reqID)
+
+	if r.Method != http.MethodPost {
+		utils.WriteJSONError(w, apperr.New(apperr.BadRequest, "method not allowed"), http.StatusMethodNotAllowed)
+		return
+	}
+
+	userContext, ok := sharedcontext.GetUserContext(ctx)
+	if !ok {
+		logger.Error("user context not found - middleware not applied correctly")
+		utils.WriteJSONError(w, apperr.New(apperr.Unauthorized, "authentication context missing"), http.StatusUnauthorized)
+		return
+	}
+	logger = logger.With("userID", userContext.UserID.String()).With("schoolID", userContext.SchoolID.String())
+
+	if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != ndjsonContentType {
+		utils.WriteJSONError(w, apperr.Newf(apperr.BadRequest, "content type must be %s", ndjsonContentType), http.StatusUnsupportedMediaType)
+		return
+	}
+
+	// The compressed body can't be larger than what it decompresses to.
+	r.Body = http.MaxBytesReader(w, r.Body, h.config.StreamMaxBytes)
+	body, err := decompressedBody(r)
+	if err != nil {
+		status := http.StatusBadRequest
+		if apperr.Is(err, apperr.BadRequest) {
+			status = http.StatusUnsupportedMediaType
+		}
+		utils.WriteJSONError(w, err, status)
+		return
+	}
+	defer body.Close()
+
+	// Uploads from slow connections may outlive the server's read timeout.
+	rc := http.NewResponseController(w)
+	deadline := time.Now().Add(h.config.StreamReadTimeout)
+	if err := rc.SetReadDeadline(deadline); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		logger.Warn("failed to extend read deadline", slog.Any("error", err))
+	}
+	if err := rc.SetWriteDeadline(deadline.Add(30 * time.Second)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		logger.Warn("failed to extend write deadline", slog.Any("error", err))
+	}
+
+	reader := &streamReader{r: body, n: h.config.StreamMaxBytes}
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, min(64*1024, h.config.StreamMaxLineBytes)), h.config.StreamMaxLineBytes)
+	scanner.Split(reader.scanLines)
+
+	var results []EventResult
+	line := 0
+	for scanner.Scan() {
+		line++
+		raw := scanner.Bytes()
+		if len(raw) == 0 {
+			continue
+		}
+		if len(results) == h.config.StreamMaxEvents {
+			results = append(results, rejectedResult(EventResult{Index: len(results), Line: line},
+				apperr.Newf(apperr.BadRequest, "stream exceeds %d events; resend from this line", h.config.StreamMaxEvents)))
+			break
+		}
+		results = append(results, h.processRawEvent(ctx, EventResult{Index: len(results), Line: line}, raw))
+	}
+	if err := scanner.Err(); err != nil {
+		var limitErr *streamLimitError
+		var maxBytesErr *http.MaxBytesError
+		switch {
+		case errors.Is(err, bufio.ErrTooLong):
+			err = apperr.Newf(apperr.BadRequest, "line exceeds %d bytes; resend from this line", h.config.StreamMaxLineBytes)
+		case errors.As(err, &limitErr), errors.As(err, &maxBytesErr):
+			err = apperr.Newf(apperr.BadRequest, "stream exceeds %d bytes; resend from this line", h.config.StreamMaxBytes)
+		default:
+			logger.Warn("failed to read event stream", slog.Any("error", err))
+			err = apperr.Wrap(err, apperr.BadRequest, "failed to read event stream; resend from this line")
+		}
+		results = append(results, rejectedResult(EventResult{Index: len(results), Line: line + 1}, err))
+	}
+
+	if len(results) == 0 {
+		utils.WriteJSONError(w, apperr.New(apperr.BadRequest, "no events provided"), http.StatusBadRequest)
+		return
+	}
+
+	logger.Info("processed event stream", slog.Int("count", len(results)), slog.Int("lines", line))
+	writeEventResults(w, logger, results)
+}
+
+// decompressedBody returns the request body decoded according to its
+// Content-Encoding.
+func decompressedBody(r *http.Request) (io.ReadCloser, error) {
+	switch encoding := r.Header.Get("Content-Encoding"); encoding {
+	case "", "identity":
+		return r.Body, nil
+	case "gzip":
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			return nil, apperr.Wrap(err, apperr.InvalidFormat, "invalid gzip stream")
+		}
+		return zr, nil
+	case "zstd":
+		zr, err := zstd.NewReader(r.Body, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, apperr.Wrap(err, apperr.InvalidFormat, "invalid zstd stream")
+		}
+		return zr.IOReadCloser(), nil
+	default:
+		return nil, apperr.Newf(apperr.BadRequest, "unsupported content encoding %q", encoding)
+	}
+}
+
+type streamLimitError struct{}
+
+func (*streamLimitError) Error() string { return "stream byte limit exceeded" }
+
+// streamReader fails once more than n bytes have been read, unlike
+// io.LimitReader which ends the stream silently.
+type streamReader struct {
+	r      io.Reader
+	n      int64
+	failed bool
+}
+
+func (s *streamReader) Read(p []byte) (int, error) {
+	if s.n < 0 {
+		return 0, &streamLimitError{}
+	}
+	if int64(len(p)) > s.n+1 {
+		p = p[:s.n+1]
+	}
+	n, err := s.r.Read(p)
+	s.n -= int64(n)
+	if s.n < 0 {
+		err = &streamLimitError{}
+	}
+	if err != nil && err != io.EOF {
+		s.failed = true
+	}
+	return n, err
+}
+
+// scanLines is bufio.ScanLines, except that an unterminated last line is
+// dropped when the stream ended with an error: it was cut off, not sent
+// that way.
+func (s *streamReader) scanLines(data []byte, atEOF bool) (int, []byte, error) {
+	if atEOF && s.failed && bytes.IndexByte(data, '\n') < 0 {
+		return 0, nil, nil
+	}
+	return bufio.ScanLines(data, atEOF)
+}
@@ -1,22 +1,58 @@
 package ingestion
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/lavish-gambhir/dashbeam/shared/streaming"
 )
 
+// BatchEventsRequest keeps events raw so that one malformed event is
+// rejected on its own instead of failing the whole batch.
 type BatchEventsRequest struct {
-	Events []streaming.Event `json:"events"`
+	Events []json.RawMessage `json:"events"`
 }
 
 type SingleEventRequest struct {
 	Event streaming.Event `json:"event"`
 }
 
+// StatusAlreadyAccepted is reported for an event that was accepted before,
+// typically resent by a client that did not get the first response.
+const StatusAlreadyAccepted = "already_accepted"
+
+// StatusPartialSuccess is reported for a batch where some events were
+// rejected; see EventResponse.Results for which.
+const StatusPartialSuccess = "partial_success"
+
+// Per-event outcomes reported in EventResult.Status.
+const (
+	EventAccepted  = "accepted"
+	EventDuplicate = "duplicate" // accepted earlier, not processed again
+	EventRejected  = "rejected"
+)
+
 type EventResponse struct {
-	Status    string    `json:"status"`
-	EventIDs  []string  `json:"event_ids,omitempty"`
-	Processed int       `json:"processed"`
-	Timestamp time.Time `json:"timestamp"`
+	Status       string        `json:"status"`
+	EventIDs     []string      `json:"event_ids,omitempty"`
+	DuplicateIDs []string      `json:"duplicate_event_ids,omitempty"` // already accepted earlier, not processed again
+	Processed    int           `json:"processed"`
+	Rejected     int           `json:"rejected,omitempty"`
+	Results      []EventResult `json:"results,omitempty"` // one per event, in request order
+	Timestamp    time.Time     `json:"timestamp"`
+}
+
+// EventResult is the outcome for one event of a batch or stream. Accepted and
+// duplicate events can be dropped from the client's offline buffer; rejected
+// ones with a validation or FORBIDDEN error code will never be accepted as
+// sent, others (e.g. INTERNAL) may be retried.
+type EventResult struct {
+	Index      int      `json:"index"`
+	Line       int      `json:"line,omitempty"` // 1-based line of a streamed event
+	EventID    string   `json:"event_id,omitempty"`
+	Status     string   `json:"status"`
+	Rewritten  []string `json:"rewritten_fields,omitempty"` // identity fields replaced with the token's
+	ErrorCode  string   `json:"error_code,omitempty"`
+	Error      string   `json:"error,omitempty"`
+	RetryAfter int      `json:"retry_after,omitempty"` // seconds, for RATE_LIMITED
 }
@@ -0,0 +1,120 @@
+// Package authz decides what dashboard users may do. A user's permissions are
+// the defaults of its role, adjusted by the allow and deny lists of its
+// permissions attribute, and it may only see the schools in its school
+// access.
+package authz
+
+import (
+	"encoding/json"
+	"slices"
+
+	"github.com/google/uuid"
+
+	"github.com/lavish-gambhir/dashbeam/pkg/apperr"
+	"github.com/lavish-gambhir/dashbeam/shared/models"
+)
+
+// Permission is an action on a kind of resource.
+type Permission string
+
+const (
+	AnalyticsRead Permission = "analytics:read"
+	ReportsRead   Permission = "reports:read"
+	DLQRead       Permission = "admin:dlq:read"
+	DLQManage     Permission = "admin:dlq:manage" // replay and purge
+	MetricsRead   Permission = "admin:metrics:read"
+)
+
+// Dashboard roles.
+const (
+	RoleViewer  = "viewer"
+	RoleAnalyst = "analyst"
+	RoleAdmin   = "admin"
+)
+
+var rolePermissions = map[string][]Permission{
+	RoleViewer:  {AnalyticsRead},
+	RoleAnalyst: {AnalyticsRead, ReportsRead},
+	RoleAdmin:   {AnalyticsRead, ReportsRead, DLQRead, DLQManage, MetricsRead},
+}
+
+// PermissionOverrides is the permissions attribute of a dashboard user.
+// Deny wins over Allow.
+type PermissionOverrides struct {
+	Allow []Permission `json:"allow"`
+	Deny  []Permission `json:"deny"`
+}
+
+// Principal is a dashboard user as far as authorization goes.
+type Principal struct {
+	UserID   uuid.UUID
+	Username string
+	Role     string
+
+	permissions map[Permission]struct{}
+	schools     map[uuid.UUID]struct{} // nil for all schools
+}
+
+// NewPrincipal returns the principal of user. A user without school access
+// set may see all schools; one with an empty school access none.
+func NewPrincipal(user *models.DashboardUser) (*Principal, error) {
+	var overrides PermissionOverrides
+	if len(user.Permissions) > 0 {
+		if err := json.Unmarshal(user.Permissions, &overrides); err != nil {
+			return nil, apperr.Wrapf(err, apperr.Internal, "invalid permissions of dashboard user %s", user.Username)
+		}
+	}
+
+	p := &Principal{
+		UserID:      user.ID,
+		Username:    user.Username,
+		Role:        user.Role,
+		permissions: make(map[Permission]struct{}),
+	}
+	for _, perm := range rolePermissions[user.Role] {
+		p.permissions[perm] = struct{}{}
+	}
+	for _, perm := range overrides.Allow {
+		p.permissions[perm] = struct{}{}
+	}
+	for _, perm := range overrides.Deny {
+		delete(p.permissions, perm)
+	}
+
+	if user.SchoolAccess != nil {
+		p.schools = make(map[uuid.UUID]struct{}, len(user.SchoolAccess))
+		for _, id := range user.SchoolAccess {
+			p.schools[id] = struct{}{}
+		}
+	}
+	return p, nil
+}
+
+// Can reports whether the principal has perm.
+func (p *Principal) Can(perm Permission) bool {
+	_, ok := p.permissions[perm]
+	return ok
+}
+
+// CanAccessSchool reports whether the principal may see the school.
+func (p *Principal) CanAccessSchool(schoolID uuid.UUID) bool {
+	if p.schools == nil {
+		return true
+	}
+	_, ok := p.schools[schoolID]
+	return ok
+}
+
+// Schools returns the schools the principal may see, for filtering queries
+// over several schools; all is true when it may see every school.
+func (p *Principal) Schools() (ids []uuid.UUID, all bool) {
+	if p.schools == nil {
+		return nil, true
+	}
+	ids = make([]uuid.UUID, 0, len(p.schools))
+	for id := range p.schools {
+		ids = append(ids, id)
+	}
+	slices.SortFunc(ids, func(a, b uuid.UUID) int { return slices.Compare(a[:], b[:]) })
+	return ids, false
+}
@@ -0,0 +1,53 @@
+package authz
+
+import (
+	"context"
+	"net/http"
+)
+
+// SchoolIDWildcard is the path wildcard naming the school a route is about.
+const SchoolIDWildcard = "schoolID"
+
+// Routes maps request patterns, in the syntax of http.ServeMux, to the
+// permission they require. A route whose pattern has a {schoolID} wildcard
+// also requires access to that school. Requests matching no pattern are
+// denied.
+type Routes struct {
+	mux *http.ServeMux
+}
+
+// Rule is what a request needs to be let through.
+type Rule struct {
+	Permission Permission
+	SchoolID   string // the {schoolID} path value, if the pattern has one
+}
+
+type ruleKey struct{}
+
+func NewRoutes(patterns map[string]Permission) *Routes {
+	mux := http.NewServeMux()
+	for pattern, perm := range patterns {
+		mux.HandleFunc(pattern, func(_ http.ResponseWriter, r *http.Request) {
+			if rule, ok := r.Context().Value(ruleKey{}).(*Rule); ok {
+				rule.Permission = perm
+				rule.SchoolID = r.PathValue(SchoolIDWildcard)
+			}
+		})
+	}
+	return &Routes{mux: mux}
+}
+
+// Match returns the rule of the route r matches.
+func (rt *Routes) Match(r *http.Request) (Rule, bool) {
+	var rule Rule
+	rt.mux.ServeHTTP(discardResponse{}, r.WithContext(context.WithValue(r.Context(), ruleKey{}, &rule)))
+	return rule, rule.Permission != ""
+}
+
+// discardResponse swallows what the mux writes for requests matching no
+// pattern.
+type discardResponse struct{}
+
+func (discardResponse) Header() http.Header         { return http.Header{} }
+func (discardResponse) Write(b []byte) (int, error) { return len(b), nil }
+func (discardResponse) WriteHeader(int)             {}
@@ -12,6 +12,8 @@ type AppConfig struct {
 	Database  DBConfig        `mapstructure:"database"`
 	Auth      AuthConfig      `mapstructure:"auth"`
 	Redis     RedisConfig     `mapstructure:"redis"`
+	Queue     QueueConfig     `mapstructure:"queue"`
+	Ingestion IngestionConfig `mapstructure:"ingestion"`
 	Quiz      QuizConfig      `mapstructure:"quiz"`
 	Analytics AnalyticsConfig `mapstructure:"analytics"`
 	Reporting ReportingConfig `mapstructure:"reporting"`
@@ -20,6 +22,7 @@ type AppConfig struct {
 type ServerConfig struct {
 	Host         string        `mapstructure:"host"`
 	Port         string        `mapstructure:"port"`
+	GRPCPort     string        `mapstructure:"grpc_port"` // empty disables the gRPC server
 	ReadTimeout  time.Duration `mapstructure:"read_timeout"`
 	WriteTimeout time.Duration `mapstructure:"write_timeout"`
 	IdleTimeout  time.Duration `mapstructure:"idle_timeout"`
@@ -52,6 +55,82 @@ type RedisConfig struct {
 	Timeout    time.Duration `mapstructure:"timeout"`
 }
 
+// QueueConfig selects the MessageQueue implementation and tunes the
+// Valkey Streams consumer groups used by the "streams" driver.
+type QueueConfig struct {
+	Driver        string        `mapstructure:"driver"` // streams, pubsub, memory
+	Codec         string        `mapstructure:"codec"`  // json (default) or msgpack, for publishing; consumers read either
+	ConsumerGroup string        `mapstructure:"consumer_group"`
+	ConsumerName  string        `mapstructure:"consumer_name"` // defaults to hostname-pid
+	MaxLen        int64         `mapstructure:"max_len"`       // approximate per-stream trim length, 0 disables trimming
+	ReadCount     int64         `mapstructure:"read_count"`
+	BlockTimeout  time.Duration `mapstructure:"block_timeout"`
+	ClaimMinIdle  time.Duration `mapstructure:"claim_min_idle"`
+	ClaimInterval time.Duration `mapstructure:"claim_interval"`
+	DLQRetention  time.Duration `mapstructure:"dlq_retention"` // how long failed events are kept, quarantined ones never expire
+	MaxReplays    int           `mapstructure:"max_replays"`   // replays before a failing event is quarantined
+}
+
+type IngestionConfig struct {
+	MaxBatchSize int           `mapstructure:"max_batch_size"`
+	DedupeWindow time.Duration `mapstructure:"dedupe_window"`  // how long event IDs are remembered to drop resent events
+	IdentityMode string        `mapstructure:"identity_mode"`  // "reject" (default) or "rewrite" events whose identity doesn't match the token
+	MaxClockSkew time.Duration `mapstructure:"max_clock_skew"` // device clock skew above which events are flagged as suspect
+
+	// Enrichers run on every event, in order; empty runs the built-in ones.
+	// Requests from TrustedProxies (CIDRs or addresses) have their
+	// X-Forwarded-For header honored.
+	Enrichers      []string `mapstructure:"enrichers"`
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
+
+	// Limits for the NDJSON streaming endpoint. Bytes are counted after
+	// decompression.
+	StreamMaxBytes     int64         `mapstructure:"stream_max_bytes"`
+	StreamMaxEvents    int           `mapstructure:"stream_max_events"`
+	StreamMaxLineBytes int           `mapstructure:"stream_max_line_bytes"`
+	StreamReadTimeout  time.Duration `mapstructure:"stream_read_timeout"`
+
+	// Live WebSocket endpoint. Messages are limited to StreamMaxLineBytes.
+	LivePingInterval time.Duration `mapstructure:"live_ping_interval"` // heartbeat interval; a socket silent for two is closed
+
+	// Outbox relay publishing events committed with operational updates.
+	OutboxPollInterval time.Duration `mapstructure:"outbox_poll_interval"`
+	OutboxBatchSize    int           `mapstructure:"outbox_batch_size"`
+	OutboxRetention    time.Duration `mapstructure:"outbox_retention"` // how long published entries are kept
+
+	RateLimits RateLimitConfig `mapstructure:"rate_limits"`
+}
+
+// RateLimitConfig limits the events accepted per device, user and school.
+// Limits are picked by the school's subscription tier.
+type RateLimitConfig struct {
+	Enabled      bool                     `mapstructure:"enabled"`
+	DefaultTier  string                   `mapstructure:"default_tier"`   // for schools whose tier has no limits
+	TierCacheTTL time.Duration            `mapstructure:"tier_cache_ttl"` // how long a school's tier is cached
+	Tiers        map[string]RateLimitTier `mapstructure:"tiers"`
+}
+
+// RateLimitTier has limits on all events and, in EventTypes, extra limits on
+// events of one type.
+type RateLimitTier struct {
+	Device     RateLimit            `mapstructure:"device"`
+	User       RateLimit            `mapstructure:"user"`
+	School     RateLimit            `mapstructure:"school"`
+	EventTypes []EventTypeRateLimit `mapstructure:"event_types"`
+}
+
+type EventTypeRateLimit struct {
+	EventType string    `mapstructure:"event_type"`
+	Device    RateLimit `mapstructure:"device"`
+	User      RateLimit `mapstructure:"user"`
+	School    RateLimit `mapstructure:"school"`
+}
+
+type RateLimit struct {
+	Rate  float64 `mapstructure:"rate"`  // events per second; 0 is unlimited
+	Burst int     `mapstructure:"burst"` // defaults to one second's worth
+}
+
 type QuizConfig struct {
 }
 
@@ -61,6 +140,25 @@ type AnalyticsConfig struct {
 	MetricsInterval    time.Duration `mapstructure:"metrics_interval"`
 	BatchSize          uint          `mapstructure:"batch_size"`
 	MaxRetries         uint          `mapstructure:"max_retries"`
+	ReorderWindow      time.Duration `mapstructure:"reorder_window"`     // how long quiz session events are held to be put in timestamp order
+	TimezoneCacheTTL   time.Duration `mapstructure:"timezone_cache_ttl"` // how long a school's timezone is cached
+	PII                PIIConfig     `mapstructure:"pii"`
+}
+
+// PIIConfig is the policy applied to event metadata before it is stored for
+// analytics. Without rules, the built-in policy is used.
+type PIIConfig struct {
+	PolicyVersion string        `mapstructure:"policy_version"` // recorded on every stored row; bump when the rules change
+	HashKey       string        `mapstructure:"hash_key"`       // TODO: fetch from secrets manager
+	SaltRotation  time.Duration `mapstructure:"salt_rotation"`  // how often the salt of hashed fields changes
+	Rules         []PIIRule     `mapstructure:"rules"`
+}
+
+type PIIRule struct {
+	EventType string `mapstructure:"event_type"` // empty applies to every event type
+	Field     string `mapstructure:"field"`
+	Action    string `mapstructure:"action"`     // drop, hash, truncate or keep
+	MaxLength int    `mapstructure:"max_length"` // truncate only
 }
 
 type ReportingConfig struct {
@@ -4,16 +4,19 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	"github.com/lavish-gambhir/dashbeam/shared/authz"
 	"github.com/lavish-gambhir/dashbeam/shared/models"
 )
 
 type contextKey string
 
 const (
-	userIDKey      contextKey = "user_id"
-	requestIDKey   contextKey = "request_id"
-	traceIDKey     contextKey = "trace_id"
-	userContextKey contextKey = "user_context"
+	userIDKey        contextKey = "user_id"
+	requestIDKey     contextKey = "request_id"
+	traceIDKey       contextKey = "trace_id"
+	userContextKey   contextKey = "user_context"
+	dashboardUserKey contextKey = "dashboard_user"
+	principalKey     contextKey = "principal"
 )
 
 func WithUserID(ctx context.Context, userID string) context.Context {
@@ -65,3 +68,25 @@ func GetAuthenticatedUserID(ctx context.Context) (uuid.UUID, bool) {
 	}
 	return userContext.UserID, true
 }
+
+// WithDashboardUser adds the authenticated dashboard user to the request context
+func WithDashboardUser(ctx context.Context, user *models.DashboardUser) context.Context {
+	return context.WithValue(ctx, dashboardUserKey, user)
+}
+
+// GetDashboardUser retrieves the authenticated dashboard user from request context
+func GetDashboardUser(ctx context.Context) (*models.DashboardUser, bool) {
+	user, ok := ctx.Value(dashboardUserKey).(*models.DashboardUser)
+	return user, ok
+}
+
+// WithPrincipal adds the authorization principal of the dashboard user to the request context
+func WithPrincipal(ctx context.Context, principal *authz.Principal) context.Context {
+	return context.WithValue(ctx, principalKey, principal)
+}
+
+// GetPrincipal retrieves the authorization principal of the dashboard user from request context
+func GetPrincipal(ctx context.Context) (*authz.Principal, bool) {
+	principal, ok := ctx.Value(principalKey).(*authz.Principal)
+	return principal, ok
+}
@@ -82,55 +82,102 @@ func (db *DB) createTables(ctx context.Context) error {
 			action String,
 			user_id UUID,
 			school_id UUID,
+			classroom_id Nullable(UUID),
 			session_id Nullable(UUID),
 			quiz_id Nullable(UUID),
 			question_id Nullable(UUID),
 			value Nullable(Float64),
 			metadata String,
 			timestamp DateTime64(3),
-			processed_at DateTime64(3)
+			processed_at DateTime64(3),
+			client_timestamp Nullable(DateTime64(3)),
+			clock_skew_ms Int64 DEFAULT 0,
+			clock_skew_suspect Bool DEFAULT false,
+			pii_policy_version LowCardinality(String) DEFAULT ''
 		) ENGINE = MergeTree()
 		PARTITION BY toYYYYMM(timestamp)
 		ORDER BY (school_id, user_id, timestamp)`,
 
-		// User activity metrics table
-		`CREATE TABLE IF NOT EXISTS user_activity_metrics (
+		// Clock skew columns, for tables created before they were added
+		`ALTER TABLE events ADD COLUMN IF NOT EXISTS client_timestamp Nullable(DateTime64(3))`,
+		`ALTER TABLE events ADD COLUMN IF NOT EXISTS clock_skew_ms Int64 DEFAULT 0`,
+		`ALTER TABLE events ADD COLUMN IF NOT EXISTS clock_skew_suspect Bool DEFAULT false`,
+
+		// PII policy audit column, for tables created before it was added
+		`ALTER TABLE events ADD COLUMN IF NOT EXISTS pii_policy_version LowCardinality(String) DEFAULT ''`,
+
+		// Classroom column, for tables created before it was added
+		`ALTER TABLE events ADD COLUMN IF NOT EXISTS classroom_id Nullable(UUID) AFTER school_id`,
+
+		// Lets the sink check for already stored events before inserting
+		`ALTER TABLE events ADD INDEX IF NOT EXISTS idx_events_event_id event_id TYPE bloom_filter GRANULARITY 4`,
+
+		// Earlier metric tables, superseded by the ones below
+		`DROP TABLE IF EXISTS user_activity_metrics`,
+		`DROP TABLE IF EXISTS quiz_metrics`,
+		`DROP TABLE IF EXISTS school_metrics`,
+		`DROP VIEW IF EXISTS user_activity_daily_mv`,
+		`DROP VIEW IF EXISTS quiz_daily_mv`,
+		`DROP VIEW IF EXISTS school_daily_mv`,
+		`DROP TABLE IF EXISTS user_activity_daily`,
+		`DROP TABLE IF EXISTS quiz_daily`,
+		`DROP TABLE IF EXISTS school_daily`,
+
+		// Metric tables. They hold mergeable aggregate state per UTC quarter
+		// hour, filled by the views in metricViews, and are read with GROUP BY.
+		// Every timezone offset is a whole number of quarter hours, so rows
+		// can be grouped into days of any school's timezone when read.
+		`CREATE TABLE IF NOT EXISTS user_activity_15m (
 			user_id UUID,
 			school_id UUID,
-			date Date,
-			login_count UInt32,
-			session_time_minutes UInt32,
-			quiz_count UInt32,
-			updated_at DateTime64(3)
-		) ENGINE = ReplacingMergeTree(updated_at)
-		PARTITION BY toYYYYMM(date)
-		ORDER BY (school_id, user_id, date)`,
-
-		// Quiz metrics table
-		`CREATE TABLE IF NOT EXISTS quiz_metrics (
+			bucket DateTime('UTC'),
+			login_count SimpleAggregateFunction(sum, UInt64),
+			session_time_ms SimpleAggregateFunction(sum, Float64),
+			quiz_count SimpleAggregateFunction(sum, UInt64),
+			updated_at SimpleAggregateFunction(max, DateTime64(3))
+		) ENGINE = AggregatingMergeTree()
+		PARTITION BY toYYYYMM(bucket)
+		ORDER BY (school_id, user_id, bucket)`,
+
+		`CREATE TABLE IF NOT EXISTS quiz_15m (
 			quiz_id UUID,
 			school_id UUID,
-			date Date,
-			participant_count UInt32,
-			completion_rate Float64,
-			average_score Float64,
-			average_response_time_ms UInt32,
-			updated_at DateTime64(3)
-		) ENGINE = ReplacingMergeTree(updated_at)
-		PARTITION BY toYYYYMM(date)
-		ORDER BY (school_id, quiz_id, date)`,
-
-		// School metrics table
-		`CREATE TABLE IF NOT EXISTS school_metrics (
+			bucket DateTime('UTC'),
+			participants AggregateFunction(uniqExact, UUID),
+			sessions_started SimpleAggregateFunction(sum, UInt64),
+			sessions_completed SimpleAggregateFunction(sum, UInt64),
+			score_sum SimpleAggregateFunction(sum, Float64),
+			answers SimpleAggregateFunction(sum, UInt64),
+			response_time_ms_sum SimpleAggregateFunction(sum, Float64),
+			updated_at SimpleAggregateFunction(max, DateTime64(3))
+		) ENGINE = AggregatingMergeTree()
+		PARTITION BY toYYYYMM(bucket)
+		ORDER BY (school_id, quiz_id, bucket)`,
+
+		`CREATE TABLE IF NOT EXISTS classroom_15m (
+			classroom_id UUID,
+			school_id UUID,
+			bucket DateTime('UTC'),
+			active_users AggregateFunction(uniqExact, UUID),
+			sessions_started SimpleAggregateFunction(sum, UInt64),
+			sessions_completed SimpleAggregateFunction(sum, UInt64),
+			score_sum SimpleAggregateFunction(sum, Float64),
+			total_events SimpleAggregateFunction(sum, UInt64),
+			updated_at SimpleAggregateFunction(max, DateTime64(3))
+		) ENGINE = AggregatingMergeTree()
+		PARTITION BY toYYYYMM(bucket)
+		ORDER BY (school_id, classroom_id, bucket)`,
+
+		`CREATE TABLE IF NOT EXISTS school_15m (
 			school_id UUID,
-			date Date,
-			active_users UInt32,
-			total_quizzes UInt32,
-			total_events UInt32,
-			updated_at DateTime64(3)
-		) ENGINE = ReplacingMergeTree(updated_at)
-		PARTITION BY toYYYYMM(date)
-		ORDER BY (school_id, date)`,
+			bucket DateTime('UTC'),
+			active_users AggregateFunction(uniqExact, UUID),
+			total_quizzes SimpleAggregateFunction(sum, UInt64),
+			total_events SimpleAggregateFunction(sum, UInt64),
+			updated_at SimpleAggregateFunction(max, DateTime64(3))
+		) ENGINE = AggregatingMergeTree()
+		PARTITION BY toYYYYMM(bucket)
+		ORDER BY (school_id, bucket)`,
 	}
 
 	for _, query := range queries {
@@ -140,5 +187,129 @@ func (db *DB) createTables(ctx context.Context) error {
 	}
 
 	db.logger.Info("ClickHouse tables created successfully")
+
+	for _, view := range metricViews {
+		var exists uint8
+		if err := db.conn.QueryRow(ctx, "EXISTS TABLE "+view.name).Scan(&exists); err != nil {
+			return fmt.Errorf("failed to check view %s: %w", view.name, err)
+		}
+		if exists == 0 {
+			db.logger.Warn("metrics view missing, run `migrator clickhouse`", slog.String("view", view.name))
+		}
+	}
+	return nil
+}
+
+// metricViews aggregate each insert into events into the metric tables. A
+// quarter hour comes out the same however its events were split into
+// inserts, since the engine merges the rows of all of them. Each query has a
+// %s placeholder for a condition on processed_at.
+var metricViews = []struct {
+	name  string
+	table string
+	query string
+}{
+	{
+		name:  "user_activity_15m_mv",
+		table: "user_activity_15m",
+		query: `SELECT
+			user_id,
+			school_id,
+			toStartOfFifteenMinutes(timestamp) AS bucket,
+			countIf(action = 'login') AS login_count,
+			sumIf(ifNull(value, 0), action = 'logout') AS session_time_ms,
+			countIf(action = 'quiz_started') AS quiz_count,
+			max(processed_at) AS updated_at
+		FROM events
+		WHERE category IN ('user_activity', 'quiz_activity') AND %s
+		GROUP BY user_id, school_id, bucket`,
+	},
+	{
+		name:  "quiz_15m_mv",
+		table: "quiz_15m",
+		query: `SELECT
+			quiz_id,
+			school_id,
+			toStartOfFifteenMinutes(timestamp) AS bucket,
+			uniqExactStateIf(user_id, action = 'quiz_started') AS participants,
+			countIf(action = 'quiz_started') AS sessions_started,
+			countIf(action = 'quiz_completed') AS sessions_completed,
+			sumIf(ifNull(value, 0), action = 'quiz_completed') AS score_sum,
+			countIf(action = 'answer_submitted' AND value IS NOT NULL) AS answers,
+			sumIf(ifNull(value, 0), action = 'answer_submitted') AS response_time_ms_sum,
+			max(processed_at) AS updated_at
+		FROM events
+		WHERE category = 'quiz_activity' AND quiz_id IS NOT NULL AND %s
+		GROUP BY quiz_id, school_id, bucket`,
+	},
+	{
+		name:  "classroom_15m_mv",
+		table: "classroom_15m",
+		query: `SELECT
+			classroom_id,
+			school_id,
+			toStartOfFifteenMinutes(timestamp) AS bucket,
+			uniqExactState(user_id) AS active_users,
+			countIf(action = 'quiz_started') AS sessions_started,
+			countIf(action = 'quiz_completed') AS sessions_completed,
+			sumIf(ifNull(value, 0), action = 'quiz_completed') AS score_sum,
+			count() AS total_events,
+			max(processed_at) AS updated_at
+		FROM events
+		WHERE classroom_id IS NOT NULL AND %s
+		GROUP BY classroom_id, school_id, bucket`,
+	},
+	{
+		name:  "school_15m_mv",
+		table: "school_15m",
+		query: `SELECT
+			school_id,
+			toStartOfFifteenMinutes(timestamp) AS bucket,
+			uniqExactState(user_id) AS active_users,
+			countIf(category = 'quiz_activity' AND action = 'quiz_started') AS total_quizzes,
+			count() AS total_events,
+			max(processed_at) AS updated_at
+		FROM events
+		WHERE %s
+		GROUP BY school_id, bucket`,
+	},
+}
+
+// CreateMetricViews creates the views in metricViews that don't exist yet. A
+// new view only sees events processed from now on; those processed before are
+// aggregated into its table once, here. The cutoff between the two is taken
+// from the ClickHouse clock, not that of the host running it.
+//
+// It is run once per deployment by `migrator clickhouse`, not by every
+// instance on startup, since two runs at once would backfill twice.
+func (db *DB) CreateMetricViews(ctx context.Context) error {
+	var now time.Time
+	if err := db.conn.QueryRow(ctx, "SELECT now64(3)").Scan(&now); err != nil {
+		return fmt.Errorf("failed to read the ClickHouse clock: %w", err)
+	}
+	cutoff := fmt.Sprintf("fromUnixTimestamp64Milli(%d)", now.UnixMilli())
+
+	for _, view := range metricViews {
+		var exists uint8
+		if err := db.conn.QueryRow(ctx, "EXISTS TABLE "+view.name).Scan(&exists); err != nil {
+			return fmt.Errorf("failed to check view %s: %w", view.name, err)
+		}
+		if exists == 1 {
+			continue
+		}
+
+		create := fmt.Sprintf("CREATE MATERIALIZED VIEW IF NOT EXISTS %s TO %s AS %s",
+			view.name, view.table, fmt.Sprintf(view.query, "processed_at >= "+cutoff))
+		if err := db.conn.Exec(ctx, create); err != nil {
+			return fmt.Errorf("failed to create view %s: %w", view.name, err)
+		}
+
+		backfill := fmt.Sprintf("INSERT INTO %s %s", view.table, fmt.Sprintf(view.query, "processed_at < "+cutoff))
+		if err := db.conn.Exec(ctx, backfill); err != nil {
+			return fmt.Errorf("failed to backfill %s: %w", view.table, err)
+		}
+		db.logger.Info("created metrics view", slog.String("view", view.name))
+	}
+
 	return nil
 }
@@ -0,0 +1,10 @@
+-- Events whose operational side effects (quiz_participants, users) have been
+-- applied. Inserted in the same transaction as the updates themselves, so a
+-- resent event is never applied twice.
+CREATE TABLE processed_events (
+    event_id UUID PRIMARY KEY,
+    event_type VARCHAR(100) NOT NULL,
+    processed_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_processed_events_processed_at ON processed_events(processed_at);
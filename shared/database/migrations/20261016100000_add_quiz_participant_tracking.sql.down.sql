@@ -0,0 +1,12 @@
+UPDATE quiz_participants SET status = 'active' WHERE status = 'paused';
+ALTER TABLE quiz_participants DROP CONSTRAINT IF EXISTS quiz_participants_status_check;
+ALTER TABLE quiz_participants ADD CONSTRAINT quiz_participants_status_check CHECK (
+    status IN ('joined', 'active', 'completed', 'disconnected', 'abandoned')
+);
+
+ALTER TABLE quiz_participants
+    DROP COLUMN IF EXISTS total_paused_seconds,
+    DROP COLUMN IF EXISTS paused_at,
+    DROP COLUMN IF EXISTS focus_loss_count,
+    DROP COLUMN IF EXISTS answer_changes_count,
+    DROP COLUMN IF EXISTS total_interactions;
@@ -0,0 +1,13 @@
+-- Columns the quiz repository writes but the original table never had, plus
+-- pause tracking for quiz.session.paused/resumed events.
+ALTER TABLE quiz_participants
+    ADD COLUMN IF NOT EXISTS total_interactions INTEGER DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS answer_changes_count INTEGER DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS focus_loss_count INTEGER DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS paused_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS total_paused_seconds INTEGER DEFAULT 0;
+
+ALTER TABLE quiz_participants DROP CONSTRAINT IF EXISTS quiz_participants_status_check;
+ALTER TABLE quiz_participants ADD CONSTRAINT quiz_participants_status_check CHECK (
+    status IN ('joined', 'active', 'paused', 'completed', 'disconnected', 'abandoned')
+);
@@ -0,0 +1,17 @@
+-- Events waiting to be published to the message queue. Rows are inserted in
+-- the same transaction as the operational updates of the event, and the
+-- outbox relay publishes them once committed, so a committed update is never
+-- left without its event.
+CREATE TABLE event_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    event_id UUID NOT NULL,
+    topic VARCHAR(255) NOT NULL,
+    event JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    published_at TIMESTAMP WITH TIME ZONE,
+    failed_at TIMESTAMP WITH TIME ZONE, -- set for rows the relay cannot decode; they are never retried
+    last_error TEXT
+);
+
+CREATE INDEX idx_event_outbox_pending ON event_outbox(id) WHERE published_at IS NULL AND failed_at IS NULL;
+CREATE INDEX idx_event_outbox_published_at ON event_outbox(published_at) WHERE published_at IS NOT NULL;
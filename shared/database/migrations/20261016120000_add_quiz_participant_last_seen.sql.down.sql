@@ -0,0 +1 @@
+ALTER TABLE quiz_participants DROP COLUMN IF EXISTS last_seen_at;
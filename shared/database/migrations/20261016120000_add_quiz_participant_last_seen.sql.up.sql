@@ -0,0 +1,3 @@
+-- Last heartbeat of a participant connected over the live ingestion socket.
+ALTER TABLE quiz_participants
+    ADD COLUMN IF NOT EXISTS last_seen_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,19 @@
+-- Attempts by dashboard users worth keeping a record of, such as requests
+-- denied by authorization.
+CREATE TABLE dashboard_audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL,
+    username VARCHAR(100) NOT NULL,
+    action VARCHAR(50) NOT NULL, -- e.g. access_denied
+    reason VARCHAR(50) NOT NULL, -- e.g. missing_permission, school_not_accessible
+    permission VARCHAR(100),
+    school_id UUID,
+    method VARCHAR(10) NOT NULL,
+    path TEXT NOT NULL,
+    request_id VARCHAR(64),
+    remote_addr VARCHAR(100),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_dashboard_audit_log_user ON dashboard_audit_log(user_id, created_at);
+CREATE INDEX idx_dashboard_audit_log_created_at ON dashboard_audit_log(created_at);
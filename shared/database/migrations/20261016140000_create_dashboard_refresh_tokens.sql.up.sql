@@ -0,0 +1,18 @@
+-- Refresh tokens of dashboard sessions. Only the SHA-256 of a token is kept.
+-- The tokens of a session form a family: each refresh uses up the token it
+-- presents and issues its replacement, and presenting a used token again
+-- revokes the whole family.
+CREATE TABLE dashboard_refresh_tokens (
+    id UUID PRIMARY KEY,
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    session_id UUID NOT NULL,
+    user_id UUID NOT NULL REFERENCES dashboard_users(id) ON DELETE CASCADE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    replaced_by UUID,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_dashboard_refresh_tokens_session ON dashboard_refresh_tokens(session_id);
+CREATE INDEX idx_dashboard_refresh_tokens_expires_at ON dashboard_refresh_tokens(expires_at);
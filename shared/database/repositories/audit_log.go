@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/lavish-gambhir/dashbeam/pkg/apperr"
+	"github.com/lavish-gambhir/dashbeam/shared/database/postgres"
+	"github.com/lavish-gambhir/dashbeam/shared/models"
+)
+
+type AuditLogRepository struct {
+	db *postgres.DB
+}
+
+func NewAuditLogRepository(db *postgres.DB) *AuditLogRepository {
+	return &AuditLogRepository{
+		db: db,
+	}
+}
+
+// Record adds an entry to the dashboard audit log.
+func (r *AuditLogRepository) Record(ctx context.Context, entry *models.AuditLogEntry) error {
+	query := `
+		INSERT INTO dashboard_audit_log (
+			user_id, username, action, reason, permission, school_id, method, path,
+			request_id, remote_addr, created_at
+		) VALUES (
+			$1, $2, $3, $4, NULLIF($5, ''), $6, $7, $8, $9, $10, $11
+		)
+		RETURNING id`
+
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now().UTC()
+	}
+	err := r.db.Conn(ctx).QueryRow(ctx, query,
+		entry.UserID,
+		entry.Username,
+		entry.Action,
+		entry.Reason,
+		entry.Permission,
+		entry.SchoolID,
+		entry.Method,
+		entry.Path,
+		entry.RequestID,
+		entry.RemoteAddr,
+		entry.CreatedAt,
+	).Scan(&entry.ID)
+	if err != nil {
+		return apperr.Wrapf(err, apperr.DBQueryFailed, "failed to record %s of dashboard user %s", entry.Action, entry.Username)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/lavish-gambhir/dashbeam/pkg/apperr"
+	"github.com/lavish-gambhir/dashbeam/shared/database/postgres"
+)
+
+type ClassroomRepository struct {
+	db *postgres.DB
+}
+
+func NewClassroomRepository(db *postgres.DB) *ClassroomRepository {
+	return &ClassroomRepository{
+		db: db,
+	}
+}
+
+// IsActiveMember reports whether the user has an active membership in the
+// classroom.
+func (r *ClassroomRepository) IsActiveMember(ctx context.Context, classroomID, userID uuid.UUID) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM user_classroom_memberships
+			WHERE classroom_id = $1 AND user_id = $2 AND status = 'active'
+		)`
+
+	var member bool
+	if err := r.db.Conn(ctx).QueryRow(ctx, query, classroomID, userID).Scan(&member); err != nil {
+		return false, apperr.Wrapf(err, apperr.DBQueryFailed, "failed to check membership of user %s in classroom %s", userID, classroomID)
+	}
+	return member, nil
+}
+
+// QuizSessionClassroom returns the classroom the quiz session is held in and
+// the school of that classroom.
+func (r *ClassroomRepository) QuizSessionClassroom(ctx context.Context, sessionID uuid.UUID) (uuid.UUID, uuid.UUID, error) {
+	query := `
+		SELECT qs.classroom_id, c.school_id
+		FROM quiz_sessions qs
+		JOIN classrooms c ON c.id = qs.classroom_id
+		WHERE qs.id = $1`
+
+	var classroomID, schoolID uuid.UUID
+	if err := r.db.Conn(ctx).QueryRow(ctx, query, sessionID).Scan(&classroomID, &schoolID); err != nil {
+		if err == pgx.ErrNoRows {
+			return uuid.Nil, uuid.Nil, apperr.Newf(apperr.DBRecordNotFound, "quiz session %s not found", sessionID)
+		}
+		return uuid.Nil, uuid.Nil, apperr.Wrapf(err, apperr.DBQueryFailed, "failed to get classroom of quiz session %s", sessionID)
+	}
+	return classroomID, schoolID, nil
+}
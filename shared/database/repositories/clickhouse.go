@@ -3,6 +3,9 @@ package repositories
 import (
 	"context"
 	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
 
 	"github.com/lavish-gambhir/dashbeam/pkg/apperr"
 	"github.com/lavish-gambhir/dashbeam/shared/database/clickhouse"
@@ -24,7 +27,11 @@ func (r *ClickHouseRepository) InsertEvents(ctx context.Context, records []model
 		return nil
 	}
 
-	batch, err := r.db.PrepareBatch(ctx, "INSERT INTO events")
+	batch, err := r.db.PrepareBatch(ctx, `INSERT INTO events (
+		event_id, event_type, category, action, user_id, school_id, classroom_id, session_id, quiz_id,
+		question_id, value, metadata, timestamp, processed_at, client_timestamp, clock_skew_ms, clock_skew_suspect,
+		pii_policy_version
+	)`)
 	if err != nil {
 		return apperr.Wrap(err, apperr.Internal, "failed to prepare batch")
 	}
@@ -39,6 +46,7 @@ func (r *ClickHouseRepository) InsertEvents(ctx context.Context, records []model
 			record.Action,
 			record.UserID,
 			record.SchoolID,
+			record.ClassroomID,
 			record.SessionID,
 			record.QuizID,
 			record.QuestionID,
@@ -46,6 +54,10 @@ func (r *ClickHouseRepository) InsertEvents(ctx context.Context, records []model
 			string(metadataJSON),
 			record.Timestamp,
 			record.ProcessedAt,
+			record.ClientTimestamp,
+			record.ClockSkewMS,
+			record.ClockSkewSuspect,
+			record.PIIPolicyVersion,
 		)
 		if err != nil {
 			return apperr.Wrap(err, apperr.Internal, "failed to append to batch")
@@ -55,99 +67,97 @@ func (r *ClickHouseRepository) InsertEvents(ctx context.Context, records []model
 	return batch.Send()
 }
 
-func (r *ClickHouseRepository) UpsertUserActivityMetrics(ctx context.Context, metrics []models.UserActivityMetric) error {
-	if len(metrics) == 0 {
-		return nil
+// ExistingEventIDs returns which of ids are already stored in events. Lookups
+// are limited to the given schools and time range, which are the leading
+// sort and partition keys of the table.
+func (r *ClickHouseRepository) ExistingEventIDs(ctx context.Context, ids []string, schoolIDs []uuid.UUID, from, to time.Time) (map[string]struct{}, error) {
+	existing := make(map[string]struct{})
+	if len(ids) == 0 {
+		return existing, nil
 	}
 
-	batch, err := r.db.PrepareBatch(ctx, "INSERT INTO user_activity_metrics")
+	query := `
+		SELECT DISTINCT event_id
+		FROM events
+		WHERE school_id IN ? AND timestamp >= ? AND timestamp <= ? AND event_id IN ?
+	`
+
+	rows, err := r.db.Query(ctx, query, schoolIDs, from, to, ids)
 	if err != nil {
-		return apperr.Wrap(err, apperr.Internal, "failed to prepare batch")
+		return nil, apperr.Wrap(err, apperr.Internal, "failed to query existing event ids")
 	}
+	defer rows.Close()
 
-	for _, metric := range metrics {
-		err := batch.Append(
-			metric.UserID,
-			metric.SchoolID,
-			metric.Date,
-			metric.LoginCount,
-			metric.SessionTime,
-			metric.QuizCount,
-			metric.UpdatedAt,
-		)
-		if err != nil {
-			return apperr.Wrap(err, apperr.Internal, "failed to append to batch")
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, apperr.Wrap(err, apperr.Internal, "failed to scan event id")
 		}
+		existing[id] = struct{}{}
 	}
 
-	return batch.Send()
+	return existing, rows.Err()
 }
 
-func (r *ClickHouseRepository) UpsertQuizMetrics(ctx context.Context, metrics []models.QuizMetric) error {
-	if len(metrics) == 0 {
-		return nil
+// The metric tables are kept by materialized views over events (see
+// clickhouse.DB). Their rows are partial aggregates per UTC quarter hour,
+// merged here by grouping them into periods of the requested timezone.
+
+// periodRange returns the UTC bounds of dates, and the expression of the
+// period of a bucket, with a placeholder for the timezone.
+func periodRange(dates models.DateRange) (from, to time.Time, period string, err error) {
+	switch dates.Granularity {
+	case models.GranularityDay, "":
+		period = "toDate(bucket, ?)"
+	case models.GranularityWeek:
+		period = "toMonday(toDate(bucket, ?))"
+	case models.GranularityMonth:
+		period = "toStartOfMonth(toDate(bucket, ?))"
+	default:
+		return from, to, "", apperr.Newf(apperr.BadRequest, "unsupported granularity: %s", dates.Granularity)
 	}
 
-	batch, err := r.db.PrepareBatch(ctx, "INSERT INTO quiz_metrics")
+	loc, err := time.LoadLocation(dates.Timezone)
 	if err != nil {
-		return apperr.Wrap(err, apperr.Internal, "failed to prepare batch")
-	}
-
-	for _, metric := range metrics {
-		err := batch.Append(
-			metric.QuizID,
-			metric.SchoolID,
-			metric.Date,
-			metric.ParticipantCount,
-			metric.CompletionRate,
-			metric.AverageScore,
-			metric.AverageResponseTime,
-			metric.UpdatedAt,
-		)
-		if err != nil {
-			return apperr.Wrap(err, apperr.Internal, "failed to append to batch")
-		}
+		return from, to, "", apperr.Wrapf(err, apperr.BadRequest, "invalid timezone: %s", dates.Timezone)
 	}
-
-	return batch.Send()
-}
-
-func (r *ClickHouseRepository) UpsertSchoolMetrics(ctx context.Context, metrics []models.SchoolMetric) error {
-	if len(metrics) == 0 {
-		return nil
+	end, err := time.ParseInLocation(time.DateOnly, dates.EndDate, loc)
+	if err != nil {
+		return from, to, "", apperr.Wrapf(err, apperr.BadRequest, "invalid end date: %s", dates.EndDate)
 	}
-
-	batch, err := r.db.PrepareBatch(ctx, "INSERT INTO school_metrics")
+	start, err := time.ParseInLocation(time.DateOnly, dates.StartDate, loc)
 	if err != nil {
-		return apperr.Wrap(err, apperr.Internal, "failed to prepare batch")
+		return from, to, "", apperr.Wrapf(err, apperr.BadRequest, "invalid start date: %s", dates.StartDate)
 	}
-
-	for _, metric := range metrics {
-		err := batch.Append(
-			metric.SchoolID,
-			metric.Date,
-			metric.ActiveUsers,
-			metric.TotalQuizzes,
-			metric.TotalEvents,
-			metric.UpdatedAt,
-		)
-		if err != nil {
-			return apperr.Wrap(err, apperr.Internal, "failed to append to batch")
-		}
+	if end.Before(start) {
+		return from, to, "", apperr.New(apperr.BadRequest, "end date is before start date")
 	}
-
-	return batch.Send()
+	return start.UTC(), end.AddDate(0, 0, 1).UTC(), period, nil
 }
 
-func (r *ClickHouseRepository) GetUserActivityByDateRange(ctx context.Context, userID string, startDate, endDate string) ([]models.UserActivityMetric, error) {
+func (r *ClickHouseRepository) GetUserActivityByDateRange(ctx context.Context, schoolID, userID string, dates models.DateRange, page models.Page) ([]models.UserActivityMetric, error) {
+	from, to, period, err := periodRange(dates)
+	if err != nil {
+		return nil, err
+	}
+
 	query := `
-		SELECT user_id, school_id, date, login_count, session_time_minutes, quiz_count, updated_at
-		FROM user_activity_metrics
-		WHERE user_id = ? AND date >= ? AND date <= ?
-		ORDER BY date DESC
+		SELECT
+			user_id,
+			school_id,
+			` + period + ` AS period,
+			toInt64(sum(login_count)),
+			toInt64(sum(session_time_ms) / 60000),
+			toInt64(sum(quiz_count)),
+			max(updated_at)
+		FROM user_activity_15m
+		WHERE school_id = ? AND user_id = ? AND bucket >= ? AND bucket < ?
+		GROUP BY user_id, school_id, period
+		ORDER BY period DESC
+		LIMIT ? OFFSET ?
 	`
 
-	rows, err := r.db.Query(ctx, query, userID, startDate, endDate)
+	rows, err := r.db.Query(ctx, query, dates.Timezone, schoolID, userID, from, to, page.Limit, page.Offset)
 	if err != nil {
 		return nil, apperr.Wrap(err, apperr.Internal, "failed to query user activity metrics")
 	}
@@ -156,33 +166,52 @@ func (r *ClickHouseRepository) GetUserActivityByDateRange(ctx context.Context, u
 	var metrics []models.UserActivityMetric
 	for rows.Next() {
 		var metric models.UserActivityMetric
+		var loginCount, sessionTime, quizCount int64
 		err := rows.Scan(
 			&metric.UserID,
 			&metric.SchoolID,
 			&metric.Date,
-			&metric.LoginCount,
-			&metric.SessionTime,
-			&metric.QuizCount,
+			&loginCount,
+			&sessionTime,
+			&quizCount,
 			&metric.UpdatedAt,
 		)
 		if err != nil {
 			return nil, apperr.Wrap(err, apperr.Internal, "failed to scan user activity metric")
 		}
+		metric.LoginCount = int(loginCount)
+		metric.SessionTime = int(sessionTime)
+		metric.QuizCount = int(quizCount)
 		metrics = append(metrics, metric)
 	}
 
-	return metrics, nil
+	return metrics, rows.Err()
 }
 
-func (r *ClickHouseRepository) GetQuizMetricsByDateRange(ctx context.Context, quizID string, startDate, endDate string) ([]models.QuizMetric, error) {
+func (r *ClickHouseRepository) GetQuizMetricsByDateRange(ctx context.Context, schoolID, quizID string, dates models.DateRange, page models.Page) ([]models.QuizMetric, error) {
+	from, to, period, err := periodRange(dates)
+	if err != nil {
+		return nil, err
+	}
+
 	query := `
-		SELECT quiz_id, school_id, date, participant_count, completion_rate, average_score, average_response_time_ms, updated_at
-		FROM quiz_metrics
-		WHERE quiz_id = ? AND date >= ? AND date <= ?
-		ORDER BY date DESC
+		SELECT
+			quiz_id,
+			school_id,
+			` + period + ` AS period,
+			toInt64(uniqExactMerge(participants)),
+			if(sum(sessions_started) = 0, 0, least(sum(sessions_completed) / sum(sessions_started), 1)),
+			if(sum(sessions_completed) = 0, 0, sum(score_sum) / sum(sessions_completed)),
+			toInt64(if(sum(answers) = 0, 0, sum(response_time_ms_sum) / sum(answers))),
+			max(updated_at)
+		FROM quiz_15m
+		WHERE school_id = ? AND quiz_id = ? AND bucket >= ? AND bucket < ?
+		GROUP BY quiz_id, school_id, period
+		ORDER BY period DESC
+		LIMIT ? OFFSET ?
 	`
 
-	rows, err := r.db.Query(ctx, query, quizID, startDate, endDate)
+	rows, err := r.db.Query(ctx, query, dates.Timezone, schoolID, quizID, from, to, page.Limit, page.Offset)
 	if err != nil {
 		return nil, apperr.Wrap(err, apperr.Internal, "failed to query quiz metrics")
 	}
@@ -191,34 +220,107 @@ func (r *ClickHouseRepository) GetQuizMetricsByDateRange(ctx context.Context, qu
 	var metrics []models.QuizMetric
 	for rows.Next() {
 		var metric models.QuizMetric
+		var participantCount, averageResponseTime int64
 		err := rows.Scan(
 			&metric.QuizID,
 			&metric.SchoolID,
 			&metric.Date,
-			&metric.ParticipantCount,
+			&participantCount,
 			&metric.CompletionRate,
 			&metric.AverageScore,
-			&metric.AverageResponseTime,
+			&averageResponseTime,
 			&metric.UpdatedAt,
 		)
 		if err != nil {
 			return nil, apperr.Wrap(err, apperr.Internal, "failed to scan quiz metric")
 		}
+		metric.ParticipantCount = int(participantCount)
+		metric.AverageResponseTime = int(averageResponseTime)
+		metrics = append(metrics, metric)
+	}
+
+	return metrics, rows.Err()
+}
+
+func (r *ClickHouseRepository) GetClassroomMetricsByDateRange(ctx context.Context, schoolID, classroomID string, dates models.DateRange, page models.Page) ([]models.ClassroomMetric, error) {
+	from, to, period, err := periodRange(dates)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT
+			classroom_id,
+			school_id,
+			` + period + ` AS period,
+			toInt64(uniqExactMerge(active_users)),
+			toInt64(sum(sessions_started)),
+			if(sum(sessions_started) = 0, 0, least(sum(sessions_completed) / sum(sessions_started), 1)),
+			if(sum(sessions_completed) = 0, 0, sum(score_sum) / sum(sessions_completed)),
+			toInt64(sum(total_events)),
+			max(updated_at)
+		FROM classroom_15m
+		WHERE school_id = ? AND classroom_id = ? AND bucket >= ? AND bucket < ?
+		GROUP BY classroom_id, school_id, period
+		ORDER BY period DESC
+		LIMIT ? OFFSET ?
+	`
+
+	rows, err := r.db.Query(ctx, query, dates.Timezone, schoolID, classroomID, from, to, page.Limit, page.Offset)
+	if err != nil {
+		return nil, apperr.Wrap(err, apperr.Internal, "failed to query classroom metrics")
+	}
+	defer rows.Close()
+
+	var metrics []models.ClassroomMetric
+	for rows.Next() {
+		var metric models.ClassroomMetric
+		var activeUsers, totalQuizzes, totalEvents int64
+		err := rows.Scan(
+			&metric.ClassroomID,
+			&metric.SchoolID,
+			&metric.Date,
+			&activeUsers,
+			&totalQuizzes,
+			&metric.CompletionRate,
+			&metric.AverageScore,
+			&totalEvents,
+			&metric.UpdatedAt,
+		)
+		if err != nil {
+			return nil, apperr.Wrap(err, apperr.Internal, "failed to scan classroom metric")
+		}
+		metric.ActiveUsers = int(activeUsers)
+		metric.TotalQuizzes = int(totalQuizzes)
+		metric.TotalEvents = int(totalEvents)
 		metrics = append(metrics, metric)
 	}
 
-	return metrics, nil
+	return metrics, rows.Err()
 }
 
-func (r *ClickHouseRepository) GetSchoolMetricsByDateRange(ctx context.Context, schoolID string, startDate, endDate string) ([]models.SchoolMetric, error) {
+func (r *ClickHouseRepository) GetSchoolMetricsByDateRange(ctx context.Context, schoolID string, dates models.DateRange, page models.Page) ([]models.SchoolMetric, error) {
+	from, to, period, err := periodRange(dates)
+	if err != nil {
+		return nil, err
+	}
+
 	query := `
-		SELECT school_id, date, active_users, total_quizzes, total_events, updated_at
-		FROM school_metrics
-		WHERE school_id = ? AND date >= ? AND date <= ?
-		ORDER BY date DESC
+		SELECT
+			school_id,
+			` + period + ` AS period,
+			toInt64(uniqExactMerge(active_users)),
+			toInt64(sum(total_quizzes)),
+			toInt64(sum(total_events)),
+			max(updated_at)
+		FROM school_15m
+		WHERE school_id = ? AND bucket >= ? AND bucket < ?
+		GROUP BY school_id, period
+		ORDER BY period DESC
+		LIMIT ? OFFSET ?
 	`
 
-	rows, err := r.db.Query(ctx, query, schoolID, startDate, endDate)
+	rows, err := r.db.Query(ctx, query, dates.Timezone, schoolID, from, to, page.Limit, page.Offset)
 	if err != nil {
 		return nil, apperr.Wrap(err, apperr.Internal, "failed to query school metrics")
 	}
@@ -227,19 +329,23 @@ func (r *ClickHouseRepository) GetSchoolMetricsByDateRange(ctx context.Context,
 	var metrics []models.SchoolMetric
 	for rows.Next() {
 		var metric models.SchoolMetric
+		var activeUsers, totalQuizzes, totalEvents int64
 		err := rows.Scan(
 			&metric.SchoolID,
 			&metric.Date,
-			&metric.ActiveUsers,
-			&metric.TotalQuizzes,
-			&metric.TotalEvents,
+			&activeUsers,
+			&totalQuizzes,
+			&totalEvents,
 			&metric.UpdatedAt,
 		)
 		if err != nil {
 			return nil, apperr.Wrap(err, apperr.Internal, "failed to scan school metric")
 		}
+		metric.ActiveUsers = int(activeUsers)
+		metric.TotalQuizzes = int(totalQuizzes)
+		metric.TotalEvents = int(totalEvents)
 		metrics = append(metrics, metric)
 	}
 
-	return metrics, nil
+	return metrics, rows.Err()
 }
@@ -86,10 +86,11 @@ func (r *DashboardUserRepository) CreateUser(ctx context.Context, user *models.D
 
 	query := `
 		INSERT INTO dashboard_users (
-			id, username, password_hash, full_name, email, is_active,
-			last_login_at, created_at, updated_at
+			id, username, password_hash, full_name, email, role, school_access, permissions,
+			is_active, last_login_at, created_at, updated_at
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9
+			$1, $2, $3, $4, $5, COALESCE(NULLIF($6, ''), 'analyst'), $7, COALESCE($8::jsonb, '{}'::jsonb),
+			$9, $10, $11, $12
 		)`
 
 	_, err := r.db.Conn(ctx).Exec(ctx, query,
@@ -98,6 +99,9 @@ func (r *DashboardUserRepository) CreateUser(ctx context.Context, user *models.D
 		user.PasswordHash,
 		user.FullName,
 		user.Email,
+		user.Role,
+		user.SchoolAccess,
+		user.Permissions,
 		user.IsActive,
 		user.LastLoginAt,
 		user.CreatedAt,
@@ -114,8 +118,8 @@ func (r *DashboardUserRepository) CreateUser(ctx context.Context, user *models.D
 func (r *DashboardUserRepository) GetUserByID(ctx context.Context, userID string) (*models.DashboardUser, error) {
 	query := `
 		SELECT
-			id, username, password_hash, full_name, email, is_active,
-			last_login_at, created_at, updated_at
+			id, username, password_hash, full_name, email, role, school_access, permissions,
+			is_active, last_login_at, created_at, updated_at
 		FROM dashboard_users
 		WHERE id = $1`
 
@@ -126,6 +130,9 @@ func (r *DashboardUserRepository) GetUserByID(ctx context.Context, userID string
 		&user.PasswordHash,
 		&user.FullName,
 		&user.Email,
+		&user.Role,
+		&user.SchoolAccess,
+		&user.Permissions,
 		&user.IsActive,
 		&user.LastLoginAt,
 		&user.CreatedAt,
@@ -189,8 +196,8 @@ func (r *DashboardUserRepository) SetUserActiveStatus(ctx context.Context, userI
 func (r *DashboardUserRepository) ListUsers(ctx context.Context, limit, offset int) ([]*models.DashboardUser, error) {
 	query := `
 		SELECT
-			id, username, password_hash, full_name, email, is_active,
-			last_login_at, created_at, updated_at
+			id, username, password_hash, full_name, email, role, school_access, permissions,
+			is_active, last_login_at, created_at, updated_at
 		FROM dashboard_users
 		ORDER BY created_at DESC
 		LIMIT $1 OFFSET $2`
@@ -210,6 +217,9 @@ func (r *DashboardUserRepository) ListUsers(ctx context.Context, limit, offset i
 			&user.PasswordHash,
 			&user.FullName,
 			&user.Email,
+			&user.Role,
+			&user.SchoolAccess,
+			&user.Permissions,
 			&user.IsActive,
 			&user.LastLoginAt,
 			&user.CreatedAt,
@@ -0,0 +1,104 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/lavish-gambhir/dashbeam/pkg/apperr"
+	"github.com/lavish-gambhir/dashbeam/shared/database/postgres"
+	"github.com/lavish-gambhir/dashbeam/shared/models"
+)
+
+type OutboxRepository struct {
+	db *postgres.DB
+}
+
+func NewOutboxRepository(db *postgres.DB) *OutboxRepository {
+	return &OutboxRepository{
+		db: db,
+	}
+}
+
+// Add puts an event, encoded as JSON, in the outbox. Call it inside the
+// transaction applying the event's effects.
+func (r *OutboxRepository) Add(ctx context.Context, eventID uuid.UUID, topic string, event []byte) error {
+	query := `
+		INSERT INTO event_outbox (event_id, topic, event, created_at)
+		VALUES ($1, $2, $3, $4)`
+
+	if _, err := r.db.Conn(ctx).Exec(ctx, query, eventID, topic, event, time.Now().UTC()); err != nil {
+		return apperr.Wrapf(err, apperr.DBQueryFailed, "failed to add event %s to outbox", eventID)
+	}
+	return nil
+}
+
+// ClaimPending locks and returns up to limit unpublished entries, oldest
+// first. Entries locked by another transaction are skipped, so relays on
+// several instances share the work. Call it inside a transaction and mark the
+// entries in the same one.
+func (r *OutboxRepository) ClaimPending(ctx context.Context, limit int) ([]models.OutboxEntry, error) {
+	query := `
+		SELECT id, event_id, topic, event, created_at
+		FROM event_outbox
+		WHERE published_at IS NULL AND failed_at IS NULL
+		ORDER BY id
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED`
+
+	rows, err := r.db.Conn(ctx).Query(ctx, query, limit)
+	if err != nil {
+		return nil, apperr.Wrap(err, apperr.DBQueryFailed, "failed to query pending outbox entries")
+	}
+	defer rows.Close()
+
+	var entries []models.OutboxEntry
+	for rows.Next() {
+		var entry models.OutboxEntry
+		if err := rows.Scan(&entry.ID, &entry.EventID, &entry.Topic, &entry.Event, &entry.CreatedAt); err != nil {
+			return nil, apperr.Wrap(err, apperr.DBQueryFailed, "failed to scan outbox entry")
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperr.Wrap(err, apperr.DBQueryFailed, "failed to read pending outbox entries")
+	}
+	return entries, nil
+}
+
+// MarkPublished marks the entries as published.
+func (r *OutboxRepository) MarkPublished(ctx context.Context, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	query := `UPDATE event_outbox SET published_at = $2 WHERE id = ANY($1)`
+
+	if _, err := r.db.Conn(ctx).Exec(ctx, query, ids, time.Now().UTC()); err != nil {
+		return apperr.Wrap(err, apperr.DBQueryFailed, "failed to mark outbox entries published")
+	}
+	return nil
+}
+
+// MarkFailed takes an entry that can never be published out of the pending
+// set, keeping the reason.
+func (r *OutboxRepository) MarkFailed(ctx context.Context, id int64, reason string) error {
+	query := `UPDATE event_outbox SET failed_at = $2, last_error = $3 WHERE id = $1`
+
+	if _, err := r.db.Conn(ctx).Exec(ctx, query, id, time.Now().UTC(), reason); err != nil {
+		return apperr.Wrapf(err, apperr.DBQueryFailed, "failed to mark outbox entry %d failed", id)
+	}
+	return nil
+}
+
+// DeletePublished deletes entries published before the given time and
+// returns how many were deleted.
+func (r *OutboxRepository) DeletePublished(ctx context.Context, before time.Time) (int64, error) {
+	query := `DELETE FROM event_outbox WHERE published_at < $1`
+
+	result, err := r.db.Conn(ctx).Exec(ctx, query, before)
+	if err != nil {
+		return 0, apperr.Wrap(err, apperr.DBQueryFailed, "failed to delete published outbox entries")
+	}
+	return result.RowsAffected(), nil
+}
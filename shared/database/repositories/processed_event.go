@@ -0,0 +1,36 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/lavish-gambhir/dashbeam/pkg/apperr"
+	"github.com/lavish-gambhir/dashbeam/shared/database/postgres"
+)
+
+type ProcessedEventRepository struct {
+	db *postgres.DB
+}
+
+func NewProcessedEventRepository(db *postgres.DB) *ProcessedEventRepository {
+	return &ProcessedEventRepository{
+		db: db,
+	}
+}
+
+// MarkProcessed records the event as processed and reports false if it
+// already was. Call it inside the transaction applying the event's effects.
+func (r *ProcessedEventRepository) MarkProcessed(ctx context.Context, eventID uuid.UUID, eventType string) (bool, error) {
+	query := `
+		INSERT INTO processed_events (event_id, event_type, processed_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (event_id) DO NOTHING`
+
+	result, err := r.db.Conn(ctx).Exec(ctx, query, eventID, eventType, time.Now().UTC())
+	if err != nil {
+		return false, apperr.Wrapf(err, apperr.DBQueryFailed, "failed to mark event processed: %s", eventID)
+	}
+	return result.RowsAffected() == 1, nil
+}
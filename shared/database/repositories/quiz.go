@@ -301,15 +301,18 @@ func (r *QuizRepository) GetParticipantsBySession(ctx context.Context, sessionID
 	return participants, nil
 }
 
-func (r *QuizRepository) MarkParticipantDisconnected(ctx context.Context, sessionID, userID string) error {
+// MarkParticipantDisconnected marks a participant still taking the session
+// as disconnected. Participants that completed or abandoned it are left as
+// they are.
+func (r *QuizRepository) MarkParticipantDisconnected(ctx context.Context, sessionID, userID string, disconnectedAt time.Time) error {
 	query := `
 		UPDATE quiz_participants SET
 			disconnected_at = $3,
 			status = $4
-		WHERE session_id = $1 AND user_id = $2`
+		WHERE session_id = $1 AND user_id = $2 AND status IN ($5, $6, $7)`
 
-	now := time.Now().UTC()
-	result, err := r.db.Conn(ctx).Exec(ctx, query, sessionID, userID, now, string(models.ParticipantStatusDisconnected))
+	result, err := r.db.Conn(ctx).Exec(ctx, query, sessionID, userID, disconnectedAt, string(models.ParticipantStatusDisconnected),
+		string(models.ParticipantStatusJoined), string(models.ParticipantStatusActive), string(models.ParticipantStatusPaused))
 
 	if err != nil {
 		return apperr.Wrapf(err, apperr.DBQueryFailed, "failed to mark participant disconnected: session=%s, user=%s", sessionID, userID)
@@ -317,8 +320,128 @@ func (r *QuizRepository) MarkParticipantDisconnected(ctx context.Context, sessio
 
 	rowsAffected := result.RowsAffected()
 	if rowsAffected == 0 {
+		return apperr.Newf(apperr.DBRecordNotFound, "no connected participant: session=%s, user=%s", sessionID, userID)
+	}
+
+	return nil
+}
+
+// MarkParticipantConnected records that the participant was seen connected
+// at seenAt, and makes a disconnected participant active again.
+func (r *QuizRepository) MarkParticipantConnected(ctx context.Context, sessionID, userID string, seenAt time.Time) error {
+	query := `
+		UPDATE quiz_participants SET
+			last_seen_at = GREATEST(COALESCE(last_seen_at, $3), $3),
+			disconnected_at = CASE WHEN status = $4 THEN NULL ELSE disconnected_at END,
+			status = CASE WHEN status = $4 THEN $5 ELSE status END
+		WHERE session_id = $1 AND user_id = $2`
+
+	result, err := r.db.Conn(ctx).Exec(ctx, query, sessionID, userID, seenAt,
+		string(models.ParticipantStatusDisconnected), string(models.ParticipantStatusActive))
+	if err != nil {
+		return apperr.Wrapf(err, apperr.DBQueryFailed, "failed to mark participant connected: session=%s, user=%s", sessionID, userID)
+	}
+	if result.RowsAffected() == 0 {
+		return apperr.Newf(apperr.DBRecordNotFound, "participant not found: session=%s, user=%s", sessionID, userID)
+	}
+	return nil
+}
+
+// StartParticipantSession records that the user started the session, creating
+// the participant if the start event is the first one seen for it. A start
+// does not reopen a participant that already completed or abandoned it, and
+// one arriving out of order keeps the earliest started_at.
+func (r *QuizRepository) StartParticipantSession(ctx context.Context, sessionID, userID string, startedAt time.Time) error {
+	query := `
+		INSERT INTO quiz_participants (id, session_id, user_id, joined_at, started_at, status)
+		VALUES ($1, $2, $3, $4, $4, $5)
+		ON CONFLICT (session_id, user_id) DO UPDATE SET
+			started_at = LEAST(COALESCE(quiz_participants.started_at, EXCLUDED.started_at), EXCLUDED.started_at),
+			status = CASE WHEN quiz_participants.status IN ($6, $7) THEN quiz_participants.status ELSE EXCLUDED.status END`
+
+	_, err := r.db.Conn(ctx).Exec(ctx, query, uuid.New(), sessionID, userID, startedAt, string(models.ParticipantStatusActive),
+		string(models.ParticipantStatusCompleted), string(models.ParticipantStatusAbandoned))
+	if err != nil {
+		return apperr.Wrapf(err, apperr.DBQueryFailed, "failed to start participant session: session=%s, user=%s", sessionID, userID)
+	}
+	return nil
+}
+
+// RecordParticipantInteraction counts an interaction of the participant with
+// the session, e.g. a question being shown. A focus loss is also counted as
+// such.
+func (r *QuizRepository) RecordParticipantInteraction(ctx context.Context, sessionID, userID string, focusLost bool) error {
+	query := `
+		UPDATE quiz_participants SET
+			total_interactions = COALESCE(total_interactions, 0) + 1,
+			focus_loss_count = COALESCE(focus_loss_count, 0) + CASE WHEN $3 THEN 1 ELSE 0 END
+		WHERE session_id = $1 AND user_id = $2`
+
+	result, err := r.db.Conn(ctx).Exec(ctx, query, sessionID, userID, focusLost)
+	if err != nil {
+		return apperr.Wrapf(err, apperr.DBQueryFailed, "failed to record participant interaction: session=%s, user=%s", sessionID, userID)
+	}
+	if result.RowsAffected() == 0 {
 		return apperr.Newf(apperr.DBRecordNotFound, "participant not found: session=%s, user=%s", sessionID, userID)
 	}
+	return nil
+}
+
+func (r *QuizRepository) PauseParticipantSession(ctx context.Context, sessionID, userID string, pausedAt time.Time) error {
+	query := `
+		UPDATE quiz_participants SET
+			paused_at = $3,
+			status = $4
+		WHERE session_id = $1 AND user_id = $2 AND status IN ('joined', 'active')`
+
+	result, err := r.db.Conn(ctx).Exec(ctx, query, sessionID, userID, pausedAt, string(models.ParticipantStatusPaused))
+	if err != nil {
+		return apperr.Wrapf(err, apperr.DBQueryFailed, "failed to pause participant session: session=%s, user=%s", sessionID, userID)
+	}
+	if result.RowsAffected() == 0 {
+		return apperr.Newf(apperr.DBRecordNotFound, "no active participant: session=%s, user=%s", sessionID, userID)
+	}
+	return nil
+}
+
+// ResumeParticipantSession marks a paused participant active again, adding
+// the pause to its total paused time.
+func (r *QuizRepository) ResumeParticipantSession(ctx context.Context, sessionID, userID string, pausedSeconds int) error {
+	query := `
+		UPDATE quiz_participants SET
+			paused_at = NULL,
+			total_paused_seconds = COALESCE(total_paused_seconds, 0) + $3,
+			status = $4
+		WHERE session_id = $1 AND user_id = $2 AND status = $5`
+
+	result, err := r.db.Conn(ctx).Exec(ctx, query, sessionID, userID, pausedSeconds,
+		string(models.ParticipantStatusActive), string(models.ParticipantStatusPaused))
+	if err != nil {
+		return apperr.Wrapf(err, apperr.DBQueryFailed, "failed to resume participant session: session=%s, user=%s", sessionID, userID)
+	}
+	if result.RowsAffected() == 0 {
+		return apperr.Newf(apperr.DBRecordNotFound, "no paused participant: session=%s, user=%s", sessionID, userID)
+	}
+	return nil
+}
 
+// AbandonParticipantSession marks the participant as having left the session
+// without submitting. Completed participants are left as they are.
+func (r *QuizRepository) AbandonParticipantSession(ctx context.Context, sessionID, userID string, abandonedAt time.Time) error {
+	query := `
+		UPDATE quiz_participants SET
+			disconnected_at = $3,
+			paused_at = NULL,
+			status = $4
+		WHERE session_id = $1 AND user_id = $2 AND status <> $5`
+
+	result, err := r.db.Conn(ctx).Exec(ctx, query, sessionID, userID, abandonedAt,
+		string(models.ParticipantStatusAbandoned), string(models.ParticipantStatusCompleted))
+	if err != nil {
+		return apperr.Wrapf(err, apperr.DBQueryFailed, "failed to abandon participant session: session=%s, user=%s", sessionID, userID)
+	}
+	if result.RowsAffected() == 0 {
+		return apperr.Newf(apperr.DBRecordNotFound, "participant not found or already completed: session=%s, user=%s", sessionID, userID)
+	}
 	return nil
 }
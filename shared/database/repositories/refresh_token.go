@@ -0,0 +1,188 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/lavish-gambhir/dashbeam/pkg/apperr"
+	"github.com/lavish-gambhir/dashbeam/shared/database/postgres"
+	"github.com/lavish-gambhir/dashbeam/shared/models"
+)
+
+type RefreshTokenRepository struct {
+	db *postgres.DB
+}
+
+func NewRefreshTokenRepository(db *postgres.DB) *RefreshTokenRepository {
+	return &RefreshTokenRepository{
+		db: db,
+	}
+}
+
+// Create stores a new refresh token.
+func (r *RefreshTokenRepository) Create(ctx context.Context, token *models.RefreshToken) error {
+	query := `
+		INSERT INTO dashboard_refresh_tokens (
+			id, token_hash, session_id, user_id, expires_at, created_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6
+		)`
+
+	if token.CreatedAt.IsZero() {
+		token.CreatedAt = time.Now().UTC()
+	}
+	_, err := r.db.Conn(ctx).Exec(ctx, query,
+		token.ID,
+		token.TokenHash,
+		token.SessionID,
+		token.UserID,
+		token.ExpiresAt,
+		token.CreatedAt,
+	)
+	if err != nil {
+		return apperr.Wrapf(err, apperr.DBQueryFailed, "failed to create refresh token for session %s", token.SessionID)
+	}
+	return nil
+}
+
+// Rotate uses up the refresh token with tokenHash and stores next, of the
+// same session and user, in its place. next expires with the token it
+// replaces, so rotating never extends a session. It returns the used token.
+//
+// A token that was already used means it leaked, or its replacement did, so
+// the whole session is revoked and a TokenReused error returned. Revoked and
+// expired tokens are rejected.
+func (r *RefreshTokenRepository) Rotate(ctx context.Context, tokenHash string, next *models.RefreshToken) (*models.RefreshToken, error) {
+	txCtx, err := r.db.TransactionContext(ctx)
+	if err != nil {
+		return nil, apperr.Wrap(err, apperr.DBQueryFailed, "failed to begin transaction")
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			_ = r.db.Rollback(txCtx)
+		}
+	}()
+
+	query := `
+		SELECT
+			id, token_hash, session_id, user_id, expires_at, used_at, replaced_by,
+			revoked_at, created_at
+		FROM dashboard_refresh_tokens
+		WHERE token_hash = $1
+		FOR UPDATE`
+
+	var current models.RefreshToken
+	err = r.db.Conn(txCtx).QueryRow(txCtx, query, tokenHash).Scan(
+		&current.ID,
+		&current.TokenHash,
+		&current.SessionID,
+		&current.UserID,
+		&current.ExpiresAt,
+		&current.UsedAt,
+		&current.ReplacedBy,
+		&current.RevokedAt,
+		&current.CreatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, apperr.New(apperr.DBRecordNotFound, "refresh token not found")
+		}
+		return nil, apperr.Wrap(err, apperr.DBQueryFailed, "failed to get refresh token")
+	}
+
+	now := time.Now().UTC()
+	if err := checkRotatable(&current, now); err != nil {
+		if apperr.Is(err, apperr.TokenReused) {
+			if err := r.revokeSession(txCtx, current.SessionID, now); err != nil {
+				return nil, err
+			}
+			if err := r.db.Commit(txCtx); err != nil {
+				return nil, apperr.Wrapf(err, apperr.DBQueryFailed, "failed to commit revocation of session %s", current.SessionID)
+			}
+			committed = true
+		}
+		return nil, err
+	}
+
+	replaceRefreshToken(&current, next, now)
+	if err := r.Create(txCtx, next); err != nil {
+		return nil, err
+	}
+
+	_, err = r.db.Conn(txCtx).Exec(txCtx, `
+		UPDATE dashboard_refresh_tokens SET
+			used_at = $2,
+			replaced_by = $3
+		WHERE id = $1`, current.ID, now, next.ID)
+	if err != nil {
+		return nil, apperr.Wrapf(err, apperr.DBQueryFailed, "failed to mark refresh token %s used", current.ID)
+	}
+
+	if err := r.db.Commit(txCtx); err != nil {
+		return nil, apperr.Wrapf(err, apperr.DBQueryFailed, "failed to commit rotation of refresh token %s", current.ID)
+	}
+	committed = true
+	current.UsedAt = &now
+	current.ReplacedBy = &next.ID
+	return &current, nil
+}
+
+// RevokeSession revokes every refresh token of the session.
+func (r *RefreshTokenRepository) RevokeSession(ctx context.Context, sessionID uuid.UUID) error {
+	return r.revokeSession(ctx, sessionID, time.Now().UTC())
+}
+
+// RevokeTokenSession revokes every refresh token of the session the token
+// with tokenHash belongs to, whether or not that token is still usable.
+func (r *RefreshTokenRepository) RevokeTokenSession(ctx context.Context, tokenHash string) error {
+	query := `
+		UPDATE dashboard_refresh_tokens SET
+			revoked_at = $2
+		WHERE session_id = (
+			SELECT session_id FROM dashboard_refresh_tokens WHERE token_hash = $1
+		) AND revoked_at IS NULL`
+
+	if _, err := r.db.Conn(ctx).Exec(ctx, query, tokenHash, time.Now().UTC()); err != nil {
+		return apperr.Wrap(err, apperr.DBQueryFailed, "failed to revoke refresh tokens")
+	}
+	return nil
+}
+
+func (r *RefreshTokenRepository) revokeSession(ctx context.Context, sessionID uuid.UUID, at time.Time) error {
+	query := `
+		UPDATE dashboard_refresh_tokens SET
+			revoked_at = $2
+		WHERE session_id = $1 AND revoked_at IS NULL`
+
+	if _, err := r.db.Conn(ctx).Exec(ctx, query, sessionID, at); err != nil {
+		return apperr.Wrapf(err, apperr.DBQueryFailed, "failed to revoke refresh tokens of session %s", sessionID)
+	}
+	return nil
+}
+
+// checkRotatable returns why current can't be rotated at now, if it can't.
+// A TokenReused error means the session has to be revoked.
+func checkRotatable(current *models.RefreshToken, now time.Time) error {
+	switch {
+	case current.RevokedAt != nil:
+		return apperr.Newf(apperr.InvalidToken, "refresh token of session %s is revoked", current.SessionID)
+	case current.UsedAt != nil:
+		return apperr.Newf(apperr.TokenReused, "refresh token of session %s was already used", current.SessionID)
+	case !now.Before(current.ExpiresAt):
+		return apperr.Newf(apperr.TokenExpired, "refresh token of session %s has expired", current.SessionID)
+	}
+	return nil
+}
+
+// replaceRefreshToken makes next, created at now, the replacement of
+// current: of the same session and user, and expiring with it.
+func replaceRefreshToken(current, next *models.RefreshToken, now time.Time) {
+	next.SessionID = current.SessionID
+	next.UserID = current.UserID
+	next.ExpiresAt = current.ExpiresAt
+	next.CreatedAt = now
+}
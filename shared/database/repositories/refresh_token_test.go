@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/lavish-gambhir/dashbeam/pkg/apperr"
+	"github.com/lavish-gambhir/dashbeam/shared/models"
+)
+
+func TestCheckRotatable(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	earlier := now.Add(-time.Minute)
+
+	tests := []struct {
+		name    string
+		token   models.RefreshToken
+		wantErr apperr.ErrCode
+	}{
+		{name: "fresh", token: models.RefreshToken{ExpiresAt: now.Add(time.Hour)}},
+		{name: "expired", token: models.RefreshToken{ExpiresAt: now}, wantErr: apperr.TokenExpired},
+		{name: "used", token: models.RefreshToken{ExpiresAt: now.Add(time.Hour), UsedAt: &earlier}, wantErr: apperr.TokenReused},
+		{name: "used after expiry", token: models.RefreshToken{ExpiresAt: earlier, UsedAt: &earlier}, wantErr: apperr.TokenReused},
+		{name: "revoked", token: models.RefreshToken{ExpiresAt: now.Add(time.Hour), RevokedAt: &earlier}, wantErr: apperr.InvalidToken},
+		{
+			// The session was already revoked when the reuse was detected.
+			name:    "used and revoked",
+			token:   models.RefreshToken{ExpiresAt: now.Add(time.Hour), UsedAt: &earlier, RevokedAt: &earlier},
+			wantErr: apperr.InvalidToken,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkRotatable(&tt.token, now)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("checkRotatable() error = %v", err)
+				}
+				return
+			}
+			if !apperr.Is(err, tt.wantErr) {
+				t.Fatalf("checkRotatable() error = %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRefreshTokenRotationKeepsExpiry(t *testing.T) {
+	login := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	current := &models.RefreshToken{
+		ID:        uuid.New(),
+		SessionID: uuid.New(),
+		UserID:    uuid.New(),
+		ExpiresAt: login.Add(30 * 24 * time.Hour),
+		CreatedAt: login,
+	}
+	first := *current
+	original := current
+
+	// Rotate daily; the session still ends 30 days after login.
+	for day := 1; day <= 29; day++ {
+		now := login.Add(time.Duration(day) * 24 * time.Hour)
+		if err := checkRotatable(current, now); err != nil {
+			t.Fatalf("day %d: %v", day, err)
+		}
+		next := &models.RefreshToken{ID: uuid.New()}
+		replaceRefreshToken(current, next, now)
+		if next.SessionID != first.SessionID || next.UserID != first.UserID {
+			t.Fatalf("day %d: replacement of session %s, user %s, want %s, %s", day, next.SessionID, next.UserID, first.SessionID, first.UserID)
+		}
+		if !next.ExpiresAt.Equal(first.ExpiresAt) || !next.CreatedAt.Equal(now) {
+			t.Fatalf("day %d: replacement expires %v, created %v, want %v, %v", day, next.ExpiresAt, next.CreatedAt, first.ExpiresAt, now)
+		}
+		current.UsedAt, current.ReplacedBy = &now, &next.ID
+		current = next
+	}
+
+	// Presenting a token of the family again is detected as reuse.
+	if err := checkRotatable(original, login.Add(29*24*time.Hour)); !apperr.Is(err, apperr.TokenReused) {
+		t.Errorf("reusing the first token: error = %v, want %s", err, apperr.TokenReused)
+	}
+	if err := checkRotatable(current, login.Add(30*24*time.Hour)); !apperr.Is(err, apperr.TokenExpired) {
+		t.Errorf("rotating after 30 days: error = %v, want %s", err, apperr.TokenExpired)
+	}
+}
@@ -0,0 +1,49 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/lavish-gambhir/dashbeam/pkg/apperr"
+	"github.com/lavish-gambhir/dashbeam/shared/database/postgres"
+)
+
+type SchoolRepository struct {
+	db *postgres.DB
+}
+
+func NewSchoolRepository(db *postgres.DB) *SchoolRepository {
+	return &SchoolRepository{
+		db: db,
+	}
+}
+
+// SubscriptionTier returns the subscription tier of the school.
+func (r *SchoolRepository) SubscriptionTier(ctx context.Context, schoolID uuid.UUID) (string, error) {
+	query := `SELECT COALESCE(subscription_tier, '') FROM schools WHERE id = $1`
+
+	var tier string
+	if err := r.db.Conn(ctx).QueryRow(ctx, query, schoolID).Scan(&tier); err != nil {
+		if err == pgx.ErrNoRows {
+			return "", apperr.Newf(apperr.DBRecordNotFound, "school not found with ID: %s", schoolID)
+		}
+		return "", apperr.Wrapf(err, apperr.DBQueryFailed, "failed to get subscription tier of school %s", schoolID)
+	}
+	return tier, nil
+}
+
+// Timezone returns the IANA timezone of the school.
+func (r *SchoolRepository) Timezone(ctx context.Context, schoolID uuid.UUID) (string, error) {
+	query := `SELECT timezone FROM schools WHERE id = $1`
+
+	var timezone string
+	if err := r.db.Conn(ctx).QueryRow(ctx, query, schoolID).Scan(&timezone); err != nil {
+		if err == pgx.ErrNoRows {
+			return "", apperr.Newf(apperr.DBRecordNotFound, "school not found with ID: %s", schoolID)
+		}
+		return "", apperr.Wrapf(err, apperr.DBQueryFailed, "failed to get timezone of school %s", schoolID)
+	}
+	return timezone, nil
+}
@@ -0,0 +1,186 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/lavish-gambhir/dashbeam/pkg/apperr"
+	"github.com/lavish-gambhir/dashbeam/pkg/utils"
+	"github.com/lavish-gambhir/dashbeam/shared/authz"
+	"github.com/lavish-gambhir/dashbeam/shared/config"
+	sharedcontext "github.com/lavish-gambhir/dashbeam/shared/context"
+	"github.com/lavish-gambhir/dashbeam/shared/models"
+)
+
+const auditTimeout = 5 * time.Second
+
+// DashboardUserGetter loads dashboard users by username.
+type DashboardUserGetter interface {
+	GetUserByUsername(ctx context.Context, username string) (*models.DashboardUser, error)
+}
+
+// AuditLog records denied attempts of dashboard users.
+type AuditLog interface {
+	Record(ctx context.Context, entry *models.AuditLogEntry) error
+}
+
+// DashboardAuthMiddleware validates dashboard JWTs issued by the auth service
+// and authorizes the requests of their users. The user's attributes are read
+// from its record rather than the token, so role, permission and school
+// access changes and deactivations apply immediately.
+type DashboardAuthMiddleware struct {
+	authConfig config.AuthConfig
+	users      DashboardUserGetter
+	audit      AuditLog
+	logger     *slog.Logger
+}
+
+func NewDashboardAuthMiddleware(authConfig config.AuthConfig, users DashboardUserGetter, audit AuditLog, logger *slog.Logger) *DashboardAuthMiddleware {
+	return &DashboardAuthMiddleware{
+		authConfig: authConfig,
+		users:      users,
+		audit:      audit,
+		logger:     logger.With("middleware", "dashboard_auth"),
+	}
+}
+
+// Authorize only lets through active dashboard users allowed the route they
+// request by routes. The user and its principal are put in the request
+// context. Denials of authenticated users are recorded in the audit log.
+func (dm *DashboardAuthMiddleware) Authorize(routes *authz.Routes) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			reqID, _ := sharedcontext.GetRequestID(ctx)
+			logger := dm.logger.With("fn", "Authorize").With("requestID", reqID)
+
+			authHeader := r.Header.Get("Authorization")
+			if authHeader == "" {
+				utils.WriteJSONError(w, apperr.New(apperr.Unauthorized, "authorization header required"), http.StatusUnauthorized)
+				return
+			}
+			tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+			if tokenString == authHeader {
+				utils.WriteJSONError(w, apperr.New(apperr.Unauthorized, "bearer token required"), http.StatusUnauthorized)
+				return
+			}
+
+			username, err := dm.validateDashboardJWT(tokenString)
+			if err != nil {
+				logger.Warn("dashboard JWT validation failed",
+					slog.Any("error", err),
+					slog.String("method", r.Method),
+					slog.String("path", r.URL.Path))
+				utils.WriteJSONError(w, apperr.New(apperr.InvalidToken, "invalid or expired token"), http.StatusUnauthorized)
+				return
+			}
+
+			user, err := dm.users.GetUserByUsername(ctx, username)
+			if err != nil {
+				logger.Warn("failed to load dashboard user", slog.Any("error", err), slog.String("username", username))
+				utils.WriteJSONError(w, apperr.New(apperr.InvalidToken, "invalid or expired token"), http.StatusUnauthorized)
+				return
+			}
+			logger = logger.With("username", username)
+			if !user.IsActive {
+				dm.deny(r, logger, user, models.AuditReasonInactive, authz.Rule{})
+				utils.WriteJSONError(w, apperr.New(apperr.Unauthorized, "account is inactive"), http.StatusUnauthorized)
+				return
+			}
+
+			principal, err := authz.NewPrincipal(user)
+			if err != nil {
+				logger.Error("failed to load dashboard user permissions", slog.Any("error", err))
+				utils.WriteJSONError(w, apperr.New(apperr.Internal, "failed to load permissions"), http.StatusInternalServerError)
+				return
+			}
+
+			rule, ok := routes.Match(r)
+			if !ok || !principal.Can(rule.Permission) {
+				dm.deny(r, logger, user, models.AuditReasonPermission, rule)
+				utils.WriteJSONError(w, apperr.New(apperr.Forbidden, "insufficient permissions"), http.StatusForbidden)
+				return
+			}
+			if rule.SchoolID != "" {
+				schoolID, err := uuid.Parse(rule.SchoolID)
+				if err != nil {
+					utils.WriteJSONError(w, apperr.Wrap(err, apperr.InvalidFormat, "invalid school id"), http.StatusBadRequest)
+					return
+				}
+				if !principal.CanAccessSchool(schoolID) {
+					dm.deny(r, logger, user, models.AuditReasonSchool, rule)
+					utils.WriteJSONError(w, apperr.New(apperr.Forbidden, "no access to school"), http.StatusForbidden)
+					return
+				}
+			}
+
+			ctx = sharedcontext.WithDashboardUser(ctx, user)
+			ctx = sharedcontext.WithPrincipal(ctx, principal)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// deny logs and audits a denied request of user. The audit log is written
+// even if the client went away.
+func (dm *DashboardAuthMiddleware) deny(r *http.Request, logger *slog.Logger, user *models.DashboardUser, reason string, rule authz.Rule) {
+	logger.Warn("dashboard request denied",
+		slog.String("reason", reason),
+		slog.String("role", user.Role),
+		slog.String("permission", string(rule.Permission)),
+		slog.String("schoolID", rule.SchoolID),
+		slog.String("method", r.Method),
+		slog.String("path", r.URL.Path))
+
+	reqID, _ := sharedcontext.GetRequestID(r.Context())
+	entry := &models.AuditLogEntry{
+		UserID:     user.ID,
+		Username:   user.Username,
+		Action:     models.AuditAccessDenied,
+		Reason:     reason,
+		Permission: string(rule.Permission),
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RequestID:  reqID,
+		RemoteAddr: r.RemoteAddr,
+	}
+	if schoolID, err := uuid.Parse(rule.SchoolID); err == nil {
+		entry.SchoolID = &schoolID
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), auditTimeout)
+	defer cancel()
+	if err := dm.audit.Record(ctx, entry); err != nil {
+		logger.Error("failed to audit denied request", slog.Any("error", err))
+	}
+}
+
+func (dm *DashboardAuthMiddleware) validateDashboardJWT(tokenString string) (string, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, apperr.Newf(apperr.InvalidToken, "unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(dm.authConfig.JWTSecretKey), nil
+	})
+	if err != nil {
+		return "", apperr.Wrap(err, apperr.InvalidToken, "failed to parse token")
+	}
+	if !token.Valid {
+		return "", apperr.New(apperr.InvalidToken, "token is invalid")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return "", apperr.New(apperr.InvalidToken, "invalid token claims")
+	}
+	username, ok := claims["username"].(string)
+	if !ok || username == "" {
+		return "", apperr.New(apperr.InvalidToken, "token has no username")
+	}
+	return username, nil
+}
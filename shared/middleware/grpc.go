@@ -0,0 +1,134 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"log/slog"
+	"strings"
+	"time"
+
+	gonanoid "github.com/matoous/go-nanoid/v2"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	sharedcontext "github.com/lavish-gambhir/dashbeam/shared/context"
+	"github.com/lavish-gambhir/dashbeam/shared/models"
+)
+
+// wrappedStream overrides the context of a server stream.
+type wrappedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *wrappedStream) Context() context.Context {
+	return s.ctx
+}
+
+// UnaryLogging is Logging for unary gRPC calls.
+func UnaryLogging(logger *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, done := startCall(ctx, logger, info.FullMethod)
+		resp, err := handler(ctx, req)
+		done(err)
+		return resp, err
+	}
+}
+
+// StreamLogging is Logging for streaming gRPC calls.
+func StreamLogging(logger *slog.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, done := startCall(ss.Context(), logger, info.FullMethod)
+		err := handler(srv, &wrappedStream{ServerStream: ss, ctx: ctx})
+		done(err)
+		return err
+	}
+}
+
+// startCall adds request and trace IDs to ctx and logs the call; done logs
+// its completion.
+func startCall(ctx context.Context, logger *slog.Logger, method string) (context.Context, func(error)) {
+	requestID, err := gonanoid.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	traceID := trace.SpanContextFromContext(ctx).TraceID().String()
+
+	ctx = sharedcontext.WithRequestID(ctx, requestID)
+	ctx = sharedcontext.WithTraceID(ctx, traceID)
+
+	logger.LogAttrs(ctx, slog.LevelInfo, "Call started",
+		slog.String("method", method),
+		slog.String("requestID", requestID),
+		slog.String("traceID", traceID),
+	)
+
+	start := time.Now()
+	return ctx, func(err error) {
+		logger.LogAttrs(ctx, slog.LevelInfo, "Call completed",
+			slog.String("method", method),
+			slog.String("requestID", requestID),
+			slog.String("traceID", traceID),
+			slog.String("code", status.Code(err).String()),
+			slog.Duration("duration", time.Since(start)),
+		)
+	}
+}
+
+// UnaryAuth is RequireAuth for unary gRPC calls: the token is taken from the
+// authorization metadata.
+func (am *AuthMiddleware) UnaryAuth() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		userContext, err := am.authenticateCall(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(sharedcontext.WithUserContext(ctx, userContext), req)
+	}
+}
+
+// StreamAuth is RequireAuth for streaming gRPC calls.
+func (am *AuthMiddleware) StreamAuth() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := ss.Context()
+		userContext, err := am.authenticateCall(ctx, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &wrappedStream{ServerStream: ss, ctx: sharedcontext.WithUserContext(ctx, userContext)})
+	}
+}
+
+func (am *AuthMiddleware) authenticateCall(ctx context.Context, method string) (*models.UserContext, error) {
+	reqID, _ := sharedcontext.GetRequestID(ctx)
+	logger := am.logger.With("fn", "authenticateCall").With("requestID", reqID)
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		logger.Warn("missing authorization metadata", slog.String("method", method))
+		return nil, status.Error(codes.Unauthenticated, "authorization metadata required")
+	}
+
+	tokenString, ok := strings.CutPrefix(values[0], "Bearer ")
+	if !ok {
+		logger.Warn("invalid authorization metadata format", slog.String("method", method))
+		return nil, status.Error(codes.Unauthenticated, "bearer token required")
+	}
+
+	userContext, err := am.validateMobileJWT(tokenString)
+	if err != nil {
+		logger.Warn("JWT validation failed", slog.Any("error", err), slog.String("method", method))
+		return nil, status.Error(codes.Unauthenticated, "invalid or expired token")
+	}
+
+	logger.Debug("authenticated call",
+		slog.String("userID", userContext.UserID.String()),
+		slog.String("role", userContext.Role),
+		slog.String("schoolID", userContext.SchoolID.String()),
+		slog.String("method", method))
+	return userContext, nil
+}
@@ -32,6 +32,12 @@ func (rw *responseWriter) Write(b []byte) (int, error) {
 	return n, err
 }
 
+// Unwrap lets http.ResponseController reach the underlying writer, e.g. to
+// extend deadlines for streaming handlers.
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 func Logging(logger *slog.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -46,10 +52,10 @@ func Logging(logger *slog.Logger) func(http.Handler) http.Handler {
 
 			// TODO: Set up `TraceProvider` for tracing.
 
-			// Add request ID to the context
-			r = r.WithContext(sharedcontext.WithRequestID(ctx, requestID))
-			// Add trace ID to the context
-			r = r.WithContext(sharedcontext.WithTraceID(ctx, traceID))
+			// Add request and trace IDs to the context
+			ctx = sharedcontext.WithRequestID(ctx, requestID)
+			ctx = sharedcontext.WithTraceID(ctx, traceID)
+			r = r.WithContext(ctx)
 
 			// Create a custom response writer to capture status code
 			rw := &responseWriter{ResponseWriter: w}
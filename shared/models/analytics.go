@@ -14,6 +14,7 @@ type AnalyticsRecord struct {
 	Action      string         `json:"action" ch:"action"`
 	UserID      uuid.UUID      `json:"user_id" ch:"user_id"`
 	SchoolID    uuid.UUID      `json:"school_id" ch:"school_id"`
+	ClassroomID *uuid.UUID     `json:"classroom_id,omitempty" ch:"classroom_id"`
 	SessionID   *uuid.UUID     `json:"session_id,omitempty" ch:"session_id"`
 	QuizID      *uuid.UUID     `json:"quiz_id,omitempty" ch:"quiz_id"`
 	QuestionID  *uuid.UUID     `json:"question_id,omitempty" ch:"question_id"`
@@ -21,9 +22,43 @@ type AnalyticsRecord struct {
 	Metadata    map[string]any `json:"metadata" ch:"metadata"`
 	Timestamp   time.Time      `json:"timestamp" ch:"timestamp"`
 	ProcessedAt time.Time      `json:"processed_at" ch:"processed_at"`
+
+	// Clock skew correction applied at ingestion; Timestamp is corrected
+	ClientTimestamp  *time.Time `json:"client_timestamp,omitempty" ch:"client_timestamp"`
+	ClockSkewMS      int64      `json:"clock_skew_ms" ch:"clock_skew_ms"`
+	ClockSkewSuspect bool       `json:"clock_skew_suspect" ch:"clock_skew_suspect"`
+
+	// Version of the PII policy Metadata was redacted under
+	PIIPolicyVersion string `json:"pii_policy_version" ch:"pii_policy_version"`
 }
 
-// UserActivityMetric represents aggregated user activity data
+// Granularity is the period aggregated metrics are grouped by.
+type Granularity string
+
+const (
+	GranularityDay   Granularity = "day"
+	GranularityWeek  Granularity = "week" // starting on Monday
+	GranularityMonth Granularity = "month"
+)
+
+// DateRange selects aggregated metrics from StartDate to EndDate, both
+// "2006-01-02" and inclusive, grouped by Granularity. Dates and periods are
+// those of Timezone, normally the school's.
+type DateRange struct {
+	StartDate   string
+	EndDate     string
+	Timezone    string
+	Granularity Granularity // day when empty
+}
+
+// Page selects up to Limit rows, after skipping Offset.
+type Page struct {
+	Limit  int
+	Offset int
+}
+
+// UserActivityMetric represents aggregated user activity data. Date, here and
+// in the other metrics, is the first day of the period.
 type UserActivityMetric struct {
 	UserID      uuid.UUID `json:"user_id" ch:"user_id"`
 	SchoolID    uuid.UUID `json:"school_id" ch:"school_id"`
@@ -46,6 +81,20 @@ type QuizMetric struct {
 	UpdatedAt           time.Time `json:"updated_at" ch:"updated_at"`
 }
 
+// ClassroomMetric represents aggregated classroom data, from the events sent
+// from the classroom
+type ClassroomMetric struct {
+	ClassroomID    uuid.UUID `json:"classroom_id" ch:"classroom_id"`
+	SchoolID       uuid.UUID `json:"school_id" ch:"school_id"`
+	Date           time.Time `json:"date" ch:"date"`
+	ActiveUsers    int       `json:"active_users" ch:"active_users"`
+	TotalQuizzes   int       `json:"total_quizzes" ch:"total_quizzes"`
+	CompletionRate float64   `json:"completion_rate" ch:"completion_rate"`
+	AverageScore   float64   `json:"average_score" ch:"average_score"`
+	TotalEvents    int       `json:"total_events" ch:"total_events"`
+	UpdatedAt      time.Time `json:"updated_at" ch:"updated_at"`
+}
+
 // SchoolMetric represents aggregated school-level data
 type SchoolMetric struct {
 	SchoolID     uuid.UUID `json:"school_id" ch:"school_id"`
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Audit log actions and reasons.
+const (
+	AuditAccessDenied = "access_denied"
+
+	AuditReasonInactive   = "inactive_account"
+	AuditReasonPermission = "missing_permission"
+	AuditReasonSchool     = "school_not_accessible"
+)
+
+// AuditLogEntry records a dashboard user's attempt at something, such as a
+// request that was denied.
+type AuditLogEntry struct {
+	ID         int64      `json:"id" db:"id"`
+	UserID     uuid.UUID  `json:"user_id" db:"user_id"`
+	Username   string     `json:"username" db:"username"`
+	Action     string     `json:"action" db:"action"`
+	Reason     string     `json:"reason" db:"reason"`
+	Permission string     `json:"permission,omitempty" db:"permission"`
+	SchoolID   *uuid.UUID `json:"school_id,omitempty" db:"school_id"`
+	Method     string     `json:"method" db:"method"`
+	Path       string     `json:"path" db:"path"`
+	RequestID  string     `json:"request_id" db:"request_id"`
+	RemoteAddr string     `json:"remote_addr" db:"remote_addr"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
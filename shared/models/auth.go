@@ -80,3 +80,17 @@ func (uc *UserContext) IsTeacher() bool {
 func (uc *UserContext) IsStudent() bool {
 	return uc.Role == "student"
 }
+
+// RefreshToken is a dashboard refresh token as stored, by the hash of its
+// value. The tokens of a session are one family.
+type RefreshToken struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	TokenHash  string     `json:"-" db:"token_hash"`
+	SessionID  uuid.UUID  `json:"session_id" db:"session_id"`
+	UserID     uuid.UUID  `json:"user_id" db:"user_id"`
+	ExpiresAt  time.Time  `json:"expires_at" db:"expires_at"`
+	UsedAt     *time.Time `json:"used_at" db:"used_at"`
+	ReplacedBy *uuid.UUID `json:"replaced_by" db:"replaced_by"`
+	RevokedAt  *time.Time `json:"revoked_at" db:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OutboxEntry is an event waiting in the outbox to be published.
+type OutboxEntry struct {
+	ID        int64     `json:"id" db:"id"`
+	EventID   uuid.UUID `json:"event_id" db:"event_id"`
+	Topic     string    `json:"topic" db:"topic"`
+	Event     []byte    `json:"event" db:"event"` // the event as JSON
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
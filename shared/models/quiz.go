@@ -33,6 +33,7 @@ type ParticipantStatus string
 const (
 	ParticipantStatusJoined       ParticipantStatus = "joined"
 	ParticipantStatusActive       ParticipantStatus = "active"
+	ParticipantStatusPaused       ParticipantStatus = "paused"
 	ParticipantStatusCompleted    ParticipantStatus = "completed"
 	ParticipantStatusDisconnected ParticipantStatus = "disconnected"
 	ParticipantStatusAbandoned    ParticipantStatus = "abandoned"
@@ -0,0 +1,174 @@
+// Package ratelimit implements token bucket rate limits, kept in Valkey so
+// that they hold across instances.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/lavish-gambhir/dashbeam/pkg/apperr"
+)
+
+// Limit is a token bucket refilled at Rate tokens per second up to Burst.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Bucket is a limit applied to a key.
+type Bucket struct {
+	Key   string
+	Limit Limit
+}
+
+// Limiter takes tokens from buckets.
+type Limiter interface {
+	// Take takes n tokens from every bucket, or none of them if one of the
+	// buckets has fewer than n. It returns zero when the tokens were taken,
+	// or else how long until they can be.
+	Take(ctx context.Context, buckets []Bucket, n int) (time.Duration, error)
+}
+
+// takeScript takes ARGV[1] tokens from every bucket in KEYS, or none. Bucket
+// i is refilled at ARGV[2i] tokens per second up to ARGV[2i+1]. It returns 0
+// when the tokens were taken, or else the milliseconds until they can be.
+//
+// Time is taken from the server so instances with drifting clocks agree.
+var takeScript = redis.NewScript(`
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local n = tonumber(ARGV[1])
+local levels = {}
+local wait = 0
+for i, key in ipairs(KEYS) do
+  local rate = tonumber(ARGV[2 * i])
+  local burst = tonumber(ARGV[2 * i + 1])
+  local state = redis.call('HMGET', key, 'tokens', 'ts')
+  local level = tonumber(state[1]) or burst
+  local ts = tonumber(state[2]) or now
+  level = math.min(burst, level + math.max(0, now - ts) * rate / 1000)
+  levels[i] = level
+  if level < n then
+    wait = math.max(wait, math.ceil((n - level) * 1000 / rate))
+  end
+end
+if wait > 0 then
+  return wait
+end
+for i, key in ipairs(KEYS) do
+  local rate = tonumber(ARGV[2 * i])
+  local burst = tonumber(ARGV[2 * i + 1])
+  redis.call('HSET', key, 'tokens', levels[i] - n, 'ts', now)
+  redis.call('PEXPIRE', key, math.ceil(burst * 1000 / rate) + 1000)
+end
+return 0
+`)
+
+// RedisLimiter keeps buckets in Valkey as hashes that expire once full. All
+// buckets of one Take must hash to the same slot on a cluster; give their
+// keys a common {hash tag}.
+type RedisLimiter struct {
+	client redis.Scripter
+}
+
+func NewRedisLimiter(client redis.Scripter) *RedisLimiter {
+	return &RedisLimiter{client: client}
+}
+
+func (l *RedisLimiter) Take(ctx context.Context, buckets []Bucket, n int) (time.Duration, error) {
+	buckets = limited(buckets)
+	if len(buckets) == 0 {
+		return 0, nil
+	}
+
+	keys := make([]string, len(buckets))
+	args := make([]any, 0, 1+2*len(buckets))
+	args = append(args, n)
+	for i, b := range buckets {
+		keys[i] = b.Key
+		args = append(args, strconv.FormatFloat(b.Limit.Rate, 'f', -1, 64), b.Limit.Burst)
+	}
+
+	waitMS, err := takeScript.Run(ctx, l.client, keys, args...).Int64()
+	if err != nil {
+		return 0, apperr.Wrap(err, apperr.RedisUnknown, "failed to take rate limit tokens")
+	}
+	return time.Duration(waitMS) * time.Millisecond, nil
+}
+
+// MemoryLimiter is an in-process Limiter for single-instance deployments
+// and tests.
+type MemoryLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*memoryBucket
+	lastSweep time.Time
+}
+
+type memoryBucket struct {
+	tokens float64
+	ts     time.Time
+	full   time.Time // when the bucket is full again
+}
+
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{buckets: make(map[string]*memoryBucket)}
+}
+
+func (l *MemoryLimiter) Take(_ context.Context, buckets []Bucket, n int) (time.Duration, error) {
+	buckets = limited(buckets)
+	if len(buckets) == 0 {
+		return 0, nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.lastSweep) > time.Minute {
+		for key, b := range l.buckets {
+			if now.After(b.full) {
+				delete(l.buckets, key)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	levels := make([]float64, len(buckets))
+	var wait time.Duration
+	for i, b := range buckets {
+		level := float64(b.Limit.Burst)
+		if state, ok := l.buckets[b.Key]; ok {
+			level = math.Min(level, state.tokens+now.Sub(state.ts).Seconds()*b.Limit.Rate)
+		}
+		levels[i] = level
+		if level < float64(n) {
+			wait = max(wait, time.Duration(math.Ceil((float64(n)-level)*1000/b.Limit.Rate))*time.Millisecond)
+		}
+	}
+	if wait > 0 {
+		return wait, nil
+	}
+
+	for i, b := range buckets {
+		tokens := levels[i] - float64(n)
+		refill := time.Duration((float64(b.Limit.Burst) - tokens) / b.Limit.Rate * float64(time.Second))
+		l.buckets[b.Key] = &memoryBucket{tokens: tokens, ts: now, full: now.Add(refill)}
+	}
+	return 0, nil
+}
+
+// limited drops the buckets without a limit.
+func limited(buckets []Bucket) []Bucket {
+	out := buckets[:0:0]
+	for _, b := range buckets {
+		if b.Limit.Rate > 0 && b.Limit.Burst > 0 {
+			out = append(out, b)
+		}
+	}
+	return out
+}
@@ -0,0 +1,153 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+type take struct {
+	keys     []string
+	n        int
+	wantWait time.Duration // zero when the tokens are taken
+}
+
+var limiterTests = []struct {
+	name   string
+	limits map[string]Limit
+	takes  []take
+}{
+	{
+		name:   "burst then wait",
+		limits: map[string]Limit{"a": {Rate: 1, Burst: 3}},
+		takes: []take{
+			{keys: []string{"a"}, n: 1},
+			{keys: []string{"a"}, n: 2},
+			{keys: []string{"a"}, n: 1, wantWait: time.Second},
+		},
+	},
+	{
+		name:   "several tokens at once",
+		limits: map[string]Limit{"a": {Rate: 2, Burst: 4}},
+		takes: []take{
+			{keys: []string{"a"}, n: 3},
+			{keys: []string{"a"}, n: 3, wantWait: time.Second},
+			{keys: []string{"a"}, n: 1},
+		},
+	},
+	{
+		name:   "all or nothing",
+		limits: map[string]Limit{"a": {Rate: 1, Burst: 5}, "b": {Rate: 1, Burst: 1}},
+		takes: []take{
+			{keys: []string{"a", "b"}, n: 1},
+			{keys: []string{"a", "b"}, n: 1, wantWait: time.Second},
+			// The denied take left a untouched.
+			{keys: []string{"a"}, n: 4},
+			{keys: []string{"a"}, n: 1, wantWait: time.Second},
+		},
+	},
+	{
+		name:   "longest wait wins",
+		limits: map[string]Limit{"a": {Rate: 1, Burst: 1}, "b": {Rate: 0.5, Burst: 1}},
+		takes: []take{
+			{keys: []string{"a", "b"}, n: 1},
+			{keys: []string{"a", "b"}, n: 1, wantWait: 2 * time.Second},
+		},
+	},
+	{
+		name:   "unlimited buckets ignored",
+		limits: map[string]Limit{"a": {Rate: 0, Burst: 10}, "b": {Rate: 10, Burst: 0}, "c": {Rate: 1, Burst: 1}},
+		takes: []take{
+			{keys: []string{"a", "b"}, n: 100},
+			{keys: []string{"a", "b", "c"}, n: 1},
+			{keys: []string{"a", "b", "c"}, n: 1, wantWait: time.Second},
+		},
+	},
+}
+
+func runLimiterTests(t *testing.T, newLimiter func(t *testing.T) Limiter) {
+	ctx := context.Background()
+	for _, tt := range limiterTests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newLimiter(t)
+			for i, step := range tt.takes {
+				buckets := make([]Bucket, len(step.keys))
+				for j, key := range step.keys {
+					buckets[j] = Bucket{Key: key, Limit: tt.limits[key]}
+				}
+				wait, err := l.Take(ctx, buckets, step.n)
+				if err != nil {
+					t.Fatalf("take %d: %v", i, err)
+				}
+				// The memory limiter's clock moves on between takes, so its
+				// waits can come out slightly shorter.
+				if step.wantWait == 0 && wait != 0 {
+					t.Fatalf("take %d: wait = %v, want the tokens taken", i, wait)
+				}
+				if step.wantWait > 0 && (wait <= step.wantWait-10*time.Millisecond || wait > step.wantWait) {
+					t.Fatalf("take %d: wait = %v, want %v", i, wait, step.wantWait)
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryLimiter(t *testing.T) {
+	runLimiterTests(t, func(*testing.T) Limiter { return NewMemoryLimiter() })
+
+	t.Run("refill", func(t *testing.T) {
+		l := NewMemoryLimiter()
+		buckets := []Bucket{{Key: "a", Limit: Limit{Rate: 1000, Burst: 1}}}
+		if wait, _ := l.Take(context.Background(), buckets, 1); wait != 0 {
+			t.Fatalf("wait = %v, want the token taken", wait)
+		}
+		time.Sleep(2 * time.Millisecond)
+		if wait, _ := l.Take(context.Background(), buckets, 1); wait != 0 {
+			t.Fatalf("wait = %v after a refill, want the token taken", wait)
+		}
+	})
+}
+
+var serverTime = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// newRedisLimiter runs the take script on miniredis, with the server time
+// frozen.
+func newRedisLimiter(t *testing.T) (*RedisLimiter, *miniredis.Miniredis) {
+	s := miniredis.RunT(t)
+	s.SetTime(serverTime)
+	client := redis.NewClient(&redis.Options{Addr: s.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return NewRedisLimiter(client), s
+}
+
+func TestRedisLimiter(t *testing.T) {
+	runLimiterTests(t, func(t *testing.T) Limiter {
+		l, _ := newRedisLimiter(t)
+		return l
+	})
+
+	t.Run("refill and expiry", func(t *testing.T) {
+		ctx := context.Background()
+		l, s := newRedisLimiter(t)
+		buckets := []Bucket{{Key: "a", Limit: Limit{Rate: 2, Burst: 4}}}
+		if wait, err := l.Take(ctx, buckets, 4); err != nil || wait != 0 {
+			t.Fatalf("Take() = %v, %v, want the tokens taken", wait, err)
+		}
+		// The bucket expires once it would be full again, plus a second.
+		if ttl := s.TTL("a"); ttl != 3*time.Second {
+			t.Errorf("TTL = %v, want 3s", ttl)
+		}
+
+		s.SetTime(serverTime.Add(500 * time.Millisecond))
+		if wait, _ := l.Take(ctx, buckets, 2); wait != 500*time.Millisecond {
+			t.Errorf("wait = %v after half a second, want 500ms", wait)
+		}
+		s.SetTime(serverTime.Add(time.Second))
+		if wait, _ := l.Take(ctx, buckets, 2); wait != 0 {
+			t.Errorf("wait = %v after a second, want the tokens taken", wait)
+		}
+	})
+}
@@ -0,0 +1,311 @@
+package streaming
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+const (
+	ContentTypeJSON    = "application/json"
+	ContentTypeMsgpack = "application/msgpack"
+)
+
+// Codec encodes events for the queue. Producers publish with the configured
+// codec and record its content type on every message; consumers decode with
+// whichever codec the message names, so producers and consumers configured
+// with different codecs can run side by side.
+type Codec interface {
+	ContentType() string
+	Marshal(event Event) ([]byte, error)
+	Unmarshal(data []byte, event *Event) error
+}
+
+var (
+	JSONCodec    Codec = jsonCodec{}
+	MsgpackCodec Codec = msgpackCodec{}
+)
+
+// NewCodec returns the codec configured by name: "json" (the default when
+// empty) or "msgpack".
+func NewCodec(name string) (Codec, error) {
+	switch name {
+	case "", "json":
+		return JSONCodec, nil
+	case "msgpack":
+		return MsgpackCodec, nil
+	}
+	return nil, fmt.Errorf("unknown queue codec: %s", name)
+}
+
+// codecFor returns the codec for a message's content type. Messages without
+// one were published before codecs existed and are JSON.
+func codecFor(contentType string) (Codec, error) {
+	switch contentType {
+	case "", ContentTypeJSON:
+		return JSONCodec, nil
+	case ContentTypeMsgpack:
+		return MsgpackCodec, nil
+	}
+	return nil, fmt.Errorf("unsupported content type: %s", contentType)
+}
+
+// encodeMessage encodes event for a message that has no room for a separate
+// content-type field, such as a pub/sub message. Bodies of codecs other than
+// JSON are preceded by a "<content-type>\n" header line; JSON is sent bare
+// so that consumers from before codecs keep reading it.
+func encodeMessage(codec Codec, event Event) ([]byte, error) {
+	body, err := codec.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	if codec.ContentType() == ContentTypeJSON {
+		return body, nil
+	}
+	msg := make([]byte, 0, len(codec.ContentType())+1+len(body))
+	msg = append(msg, codec.ContentType()...)
+	msg = append(msg, '\n')
+	return append(msg, body...), nil
+}
+
+// decodeMessage decodes a message written by encodeMessage.
+func decodeMessage(data []byte, event *Event) error {
+	if len(data) == 0 || data[0] == '{' {
+		return JSONCodec.Unmarshal(data, event)
+	}
+	contentType, body, ok := bytes.Cut(data, []byte{'\n'})
+	if !ok {
+		return fmt.Errorf("message has no content-type header")
+	}
+	codec, err := codecFor(string(contentType))
+	if err != nil {
+		return err
+	}
+	return codec.Unmarshal(body, event)
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) ContentType() string { return ContentTypeJSON }
+
+func (jsonCodec) Marshal(event Event) ([]byte, error) {
+	return json.Marshal(event)
+}
+
+func (jsonCodec) Unmarshal(data []byte, event *Event) error {
+	return json.Unmarshal(data, event)
+}
+
+// msgpackCodec encodes events as MessagePack. The envelope uses short keys
+// and raw 16-byte IDs; payloads and metadata are keyed by their JSON field
+// names, and payload values keep the shape they have in JSON so that older
+// payloads can be upcast the same way as JSON ones.
+type msgpackCodec struct{}
+
+type msgpackEvent struct {
+	ID            []byte             `msgpack:"id"`
+	Type          EventType          `msgpack:"t"`
+	SchemaVersion int                `msgpack:"v"`
+	Timestamp     time.Time          `msgpack:"ts"`
+	UserID        []byte             `msgpack:"u"`
+	SchoolID      []byte             `msgpack:"s"`
+	ClassroomID   []byte             `msgpack:"c,omitempty"`
+	AppType       AppType            `msgpack:"a"`
+	Payload       msgpack.RawMessage `msgpack:"p"`
+	Metadata      Metadata           `msgpack:"m"`
+
+	ClientTimestamp  *time.Time `msgpack:"ct,omitempty"`
+	ClockSkewMS      int64      `msgpack:"sk,omitempty"`
+	ClockSkewSuspect bool       `msgpack:"ss,omitempty"`
+}
+
+func (msgpackCodec) ContentType() string { return ContentTypeMsgpack }
+
+func (msgpackCodec) Marshal(event Event) ([]byte, error) {
+	payload, err := msgpackMarshal(event.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	schemaVersion := event.SchemaVersion
+	if schemaVersion == 0 {
+		schemaVersion = CurrentSchemaVersion(event.Type)
+	}
+
+	data := msgpackEvent{
+		ID:            event.ID[:],
+		Type:          event.Type,
+		SchemaVersion: schemaVersion,
+		Timestamp:     event.Timestamp,
+		UserID:        event.UserID[:],
+		SchoolID:      event.SchoolID[:],
+		AppType:       event.AppType,
+		Payload:       payload,
+		Metadata:      event.Metadata,
+
+		ClientTimestamp:  event.ClientTimestamp,
+		ClockSkewMS:      event.ClockSkewMS,
+		ClockSkewSuspect: event.ClockSkewSuspect,
+	}
+	if event.ClassroomID != nil {
+		data.ClassroomID = event.ClassroomID[:]
+	}
+	return msgpackMarshal(data)
+}
+
+func (msgpackCodec) Unmarshal(data []byte, event *Event) error {
+	var eventData msgpackEvent
+	if err := newMsgpackDecoder(data).Decode(&eventData); err != nil {
+		return fmt.Errorf("failed to unmarshal event data: %w", err)
+	}
+
+	eventID, err := uuid.FromBytes(eventData.ID)
+	if err != nil {
+		return fmt.Errorf("invalid event_id: %w", err)
+	}
+	userID, err := uuid.FromBytes(eventData.UserID)
+	if err != nil {
+		return fmt.Errorf("invalid user_id: %w", err)
+	}
+	schoolID, err := uuid.FromBytes(eventData.SchoolID)
+	if err != nil {
+		return fmt.Errorf("invalid school_id: %w", err)
+	}
+	var classroomID *uuid.UUID
+	if eventData.ClassroomID != nil {
+		cid, err := uuid.FromBytes(eventData.ClassroomID)
+		if err != nil {
+			return fmt.Errorf("invalid classroom_id: %w", err)
+		}
+		classroomID = &cid
+	}
+
+	receivedVersion := eventData.SchemaVersion
+	if receivedVersion == 0 {
+		receivedVersion = BaseSchemaVersion
+	}
+
+	var payload EventPayload
+	if receivedVersion == CurrentSchemaVersion(eventData.Type) {
+		payload, err = decodeMsgpackPayload(eventData.Type, eventData.Payload)
+	} else {
+		// Upcasters work on the JSON form of a payload.
+		payload, err = upcastMsgpackPayload(eventData.Type, receivedVersion, eventData.Payload)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to parse payload for event type %s: %w", eventData.Type, err)
+	}
+
+	event.ID = eventID
+	event.Type = eventData.Type
+	event.SchemaVersion = CurrentSchemaVersion(eventData.Type)
+	event.receivedSchemaVersion = receivedVersion
+	event.Timestamp = eventData.Timestamp
+	event.UserID = userID
+	event.SchoolID = schoolID
+	event.ClassroomID = classroomID
+	event.AppType = eventData.AppType
+	event.Payload = payload
+	event.Metadata = eventData.Metadata
+	event.ClientTimestamp = eventData.ClientTimestamp
+	event.ClockSkewMS = eventData.ClockSkewMS
+	event.ClockSkewSuspect = eventData.ClockSkewSuspect
+	return nil
+}
+
+func decodeMsgpackPayload(eventType EventType, data msgpack.RawMessage) (EventPayload, error) {
+	spec, ok := LookupEventType(eventType)
+	if !ok {
+		return nil, fmt.Errorf("unknown event type: %s", eventType)
+	}
+	return spec.decode(func(v any) error { return newMsgpackDecoder(data).Decode(v) })
+}
+
+func upcastMsgpackPayload(eventType EventType, version int, data msgpack.RawMessage) (EventPayload, error) {
+	var payload map[string]any
+	if err := newMsgpackDecoder(data).Decode(&payload); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s payload: %w", eventType, err)
+	}
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s payload: %w", eventType, err)
+	}
+	raw, _, err = upcastRaw(eventType, version, raw)
+	if err != nil {
+		return nil, err
+	}
+	return DecodePayload(eventType, raw)
+}
+
+func msgpackMarshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetCustomStructTag("json")
+	enc.UseCompactInts(true)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func newMsgpackDecoder(data []byte) *msgpack.Decoder {
+	dec := msgpack.NewDecoder(bytes.NewReader(data))
+	dec.SetCustomStructTag("json")
+	return dec
+}
+
+// Payload UUIDs are written as strings and raw JSON values as the
+// MessagePack values they hold, as they appear in JSON; otherwise both would
+// come out of a decoded payload map as bytes.
+func init() {
+	msgpack.Register(uuid.UUID{},
+		func(enc *msgpack.Encoder, v reflect.Value) error {
+			return enc.EncodeString(v.Interface().(uuid.UUID).String())
+		},
+		func(dec *msgpack.Decoder, v reflect.Value) error {
+			s, err := dec.DecodeString()
+			if err != nil {
+				return err
+			}
+			id, err := uuid.Parse(s)
+			if err != nil {
+				return err
+			}
+			v.Set(reflect.ValueOf(id))
+			return nil
+		})
+
+	msgpack.Register(json.RawMessage{},
+		func(enc *msgpack.Encoder, v reflect.Value) error {
+			raw := v.Interface().(json.RawMessage)
+			if len(raw) == 0 {
+				return enc.EncodeNil()
+			}
+			var value any
+			if err := json.Unmarshal(raw, &value); err != nil {
+				return err
+			}
+			return enc.Encode(value)
+		},
+		func(dec *msgpack.Decoder, v reflect.Value) error {
+			value, err := dec.DecodeInterface()
+			if err != nil {
+				return err
+			}
+			if value == nil {
+				v.SetBytes(nil)
+				return nil
+			}
+			raw, err := json.Marshal(value)
+			if err != nil {
+				return err
+			}
+			v.SetBytes(raw)
+			return nil
+		})
+}
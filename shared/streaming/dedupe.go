@@ -0,0 +1,109 @@
+package streaming
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lavish-gambhir/dashbeam/pkg/apperr"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	dedupeKeyPrefix     = "dedupe:event:"
+	DefaultDedupeWindow = 24 * time.Hour
+)
+
+// Deduplicator remembers event IDs for a time window so that events resent
+// by clients are accepted only once.
+type Deduplicator interface {
+	// Claim records eventID and reports whether it had not been seen within
+	// the window.
+	Claim(ctx context.Context, eventID uuid.UUID) (bool, error)
+	// Release forgets eventID, for events that were claimed but could not be
+	// accepted, so the client's retry goes through.
+	Release(ctx context.Context, eventID uuid.UUID) error
+}
+
+// RedisDeduplicator keeps claimed event IDs in Valkey as keys expiring after
+// the window, shared by all ingestion instances.
+type RedisDeduplicator struct {
+	client redis.Cmdable
+	window time.Duration
+}
+
+func NewRedisDeduplicator(client redis.Cmdable, window time.Duration) *RedisDeduplicator {
+	if window <= 0 {
+		window = DefaultDedupeWindow
+	}
+	return &RedisDeduplicator{client: client, window: window}
+}
+
+func dedupeKey(eventID uuid.UUID) string {
+	return fmt.Sprintf("%s%s", dedupeKeyPrefix, eventID)
+}
+
+func (d *RedisDeduplicator) Claim(ctx context.Context, eventID uuid.UUID) (bool, error) {
+	ok, err := d.client.SetNX(ctx, dedupeKey(eventID), 1, d.window).Result()
+	if err != nil {
+		return false, apperr.Wrapf(err, apperr.RedisUnknown, "failed to claim event %s", eventID)
+	}
+	return ok, nil
+}
+
+func (d *RedisDeduplicator) Release(ctx context.Context, eventID uuid.UUID) error {
+	if err := d.client.Del(ctx, dedupeKey(eventID)).Err(); err != nil {
+		return apperr.Wrapf(err, apperr.RedisUnknown, "failed to release event %s", eventID)
+	}
+	return nil
+}
+
+// MemoryDeduplicator is an in-process Deduplicator for single-instance
+// deployments and tests.
+type MemoryDeduplicator struct {
+	window time.Duration
+
+	mu        sync.Mutex
+	seen      map[uuid.UUID]time.Time // event ID -> expiry
+	lastSweep time.Time
+}
+
+func NewMemoryDeduplicator(window time.Duration) *MemoryDeduplicator {
+	if window <= 0 {
+		window = DefaultDedupeWindow
+	}
+	return &MemoryDeduplicator{
+		window: window,
+		seen:   make(map[uuid.UUID]time.Time),
+	}
+}
+
+func (d *MemoryDeduplicator) Claim(_ context.Context, eventID uuid.UUID) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(d.lastSweep) > d.window {
+		for id, expiry := range d.seen {
+			if now.After(expiry) {
+				delete(d.seen, id)
+			}
+		}
+		d.lastSweep = now
+	}
+
+	if expiry, ok := d.seen[eventID]; ok && now.Before(expiry) {
+		return false, nil
+	}
+	d.seen[eventID] = now.Add(d.window)
+	return true, nil
+}
+
+func (d *MemoryDeduplicator) Release(_ context.Context, eventID uuid.UUID) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.seen, eventID)
+	return nil
+}
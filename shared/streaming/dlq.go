@@ -0,0 +1,326 @@
+package streaming
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lavish-gambhir/dashbeam/pkg/apperr"
+	"github.com/lavish-gambhir/dashbeam/shared/config"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	failedEventsPrefix      = "failed_events"
+	quarantinedEventsPrefix = "quarantined_events"
+
+	defaultDLQRetention = 7 * 24 * time.Hour
+	defaultMaxReplays   = 3
+	dlqScanCount        = 500
+)
+
+// Dead-letter statuses.
+const (
+	// DLQStatusFailed is an event whose handler failed after all retries.
+	DLQStatusFailed = "failed"
+	// DLQStatusReplayed is an event that was republished and has not failed
+	// again (yet).
+	DLQStatusReplayed = "replayed"
+	// DLQStatusQuarantined is an event that kept failing after MaxReplays
+	// replays. It is kept until purged and can no longer be replayed.
+	DLQStatusQuarantined = "quarantined"
+)
+
+// FailedEvent is an event whose handler still failed after all retries.
+type FailedEvent struct {
+	Event          Event      `json:"event"`
+	Topic          string     `json:"topic"`
+	Error          string     `json:"error"`
+	FailedAt       time.Time  `json:"failed_at"`
+	RetryCount     int        `json:"retry_count"` // number of replays so far
+	Status         string     `json:"status"`
+	LastReplayedAt *time.Time `json:"last_replayed_at,omitempty"`
+}
+
+// DLQFilter narrows List results. Zero values match everything, except
+// Status, which defaults to failed and replayed events (not quarantined).
+type DLQFilter struct {
+	Topic         string
+	EventType     EventType
+	ErrorContains string // case-insensitive substring of the handler error
+	Status        string
+	Limit         int
+}
+
+func (f DLQFilter) validate() error {
+	switch f.Status {
+	case "", DLQStatusFailed, DLQStatusReplayed, DLQStatusQuarantined:
+		return nil
+	default:
+		return apperr.Newf(apperr.BadRequest, "unknown dead letter status: %s", f.Status)
+	}
+}
+
+func (f DLQFilter) matches(fe *FailedEvent) bool {
+	if f.Topic != "" && fe.Topic != f.Topic {
+		return false
+	}
+	if f.EventType != "" && fe.Event.Type != f.EventType {
+		return false
+	}
+	if f.ErrorContains != "" && !strings.Contains(strings.ToLower(fe.Error), strings.ToLower(f.ErrorContains)) {
+		return false
+	}
+	if f.Status != "" && fe.Status != f.Status {
+		return false
+	}
+	return true
+}
+
+// DeadLetterQueue keeps events whose handler failed after all retries, so
+// they can be inspected, replayed or purged.
+//
+// Replaying republishes the event and bumps its RetryCount; the entry stays
+// around as "replayed" so that a new failure carries the count over. Once an
+// event has failed after MaxReplays replays it is moved to quarantine.
+type DeadLetterQueue interface {
+	Store(ctx context.Context, topic string, event Event, err error) error
+	List(ctx context.Context, filter DLQFilter) ([]FailedEvent, error)
+	Get(ctx context.Context, topic string, eventID uuid.UUID) (*FailedEvent, error)
+	Replay(ctx context.Context, topic string, eventID uuid.UUID) (*FailedEvent, error)
+	Purge(ctx context.Context, topic string, eventID uuid.UUID) error
+}
+
+// dlqPolicy holds the retention and quarantine settings shared by the
+// DeadLetterQueue implementations.
+type dlqPolicy struct {
+	retention  time.Duration
+	maxReplays int
+}
+
+func newDLQPolicy(cfg config.QueueConfig) dlqPolicy {
+	p := dlqPolicy{retention: cfg.DLQRetention, maxReplays: cfg.MaxReplays}
+	if p.retention <= 0 {
+		p.retention = defaultDLQRetention
+	}
+	if p.maxReplays <= 0 {
+		p.maxReplays = defaultMaxReplays
+	}
+	return p
+}
+
+// failed builds the entry for a new failure, carrying over the replay count
+// of a previous entry for the same event.
+func (p dlqPolicy) failed(prev *FailedEvent, topic string, event Event, err error) FailedEvent {
+	fe := FailedEvent{
+		Event:    event,
+		Topic:    topic,
+		Error:    err.Error(),
+		FailedAt: time.Now().UTC(),
+		Status:   DLQStatusFailed,
+	}
+	if prev != nil {
+		fe.RetryCount = prev.RetryCount
+		fe.LastReplayedAt = prev.LastReplayedAt
+	}
+	if fe.RetryCount >= p.maxReplays {
+		fe.Status = DLQStatusQuarantined
+	}
+	return fe
+}
+
+func sortFailedEvents(events []FailedEvent, limit int) []FailedEvent {
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].FailedAt.After(events[j].FailedAt)
+	})
+	if limit > 0 && len(events) > limit {
+		events = events[:limit]
+	}
+	return events
+}
+
+func failedEventKey(topic string, eventID uuid.UUID) string {
+	return fmt.Sprintf("%s:%s:%s", failedEventsPrefix, topic, eventID)
+}
+
+func quarantinedEventKey(topic string, eventID uuid.UUID) string {
+	return fmt.Sprintf("%s:%s:%s", quarantinedEventsPrefix, topic, eventID)
+}
+
+// RedisDLQ stores failed events in Valkey, one key per event:
+// failed_events:<topic>:<id> (expiring after the retention period) and
+// quarantined_events:<topic>:<id> (kept until purged).
+type RedisDLQ struct {
+	client    redis.Cmdable
+	publisher MessageQueue
+	policy    dlqPolicy
+	logger    *slog.Logger
+}
+
+// NewRedisDLQ returns a DeadLetterQueue on client that replays events through
+// publisher.
+func NewRedisDLQ(client redis.Cmdable, publisher MessageQueue, cfg config.QueueConfig, logger *slog.Logger) *RedisDLQ {
+	return &RedisDLQ{
+		client:    client,
+		publisher: publisher,
+		policy:    newDLQPolicy(cfg),
+		logger:    logger.With("component", "dlq"),
+	}
+}
+
+func (d *RedisDLQ) Store(ctx context.Context, topic string, event Event, err error) error {
+	prev, getErr := d.get(ctx, failedEventKey(topic, event.ID))
+	if getErr != nil && !apperr.Is(getErr, apperr.NotFound) {
+		d.logger.Warn("failed to load previous dead letter, retry count restarts", slog.String("topic", topic), slog.String("event_id", event.ID.String()), slog.Any("err", getErr))
+	}
+	fe := d.policy.failed(prev, topic, event, err)
+
+	data, merr := json.Marshal(fe)
+	if merr != nil {
+		return apperr.Wrapf(merr, apperr.JSONEncodingFailed, "%s,topic=%s,event=%s", "failed to marshal event", topic, event.ID.String())
+	}
+
+	pipe := d.client.TxPipeline()
+	if fe.Status == DLQStatusQuarantined {
+		pipe.Set(ctx, quarantinedEventKey(topic, event.ID), data, 0)
+		pipe.Del(ctx, failedEventKey(topic, event.ID))
+		d.logger.Warn("event quarantined after replays", slog.String("topic", topic), slog.String("event_id", event.ID.String()), slog.Int("retry_count", fe.RetryCount))
+	} else {
+		pipe.Set(ctx, failedEventKey(topic, event.ID), data, d.policy.retention)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return apperr.Wrapf(err, apperr.RedisPipeExecFailed, "failed to store failed event,topic=%s,event=%s", topic, event.ID)
+	}
+	return nil
+}
+
+func (d *RedisDLQ) List(ctx context.Context, filter DLQFilter) ([]FailedEvent, error) {
+	if err := filter.validate(); err != nil {
+		return nil, err
+	}
+	topic := filter.Topic
+	if topic == "" {
+		topic = "*"
+	}
+	prefix := failedEventsPrefix
+	if filter.Status == DLQStatusQuarantined {
+		prefix = quarantinedEventsPrefix
+	}
+	pattern := fmt.Sprintf("%s:%s:*", prefix, topic)
+
+	keys, err := d.scan(ctx, pattern)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	values, err := d.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, apperr.Wrapf(err, apperr.RedisUnknown, "failed to load failed events,pattern=%s", pattern)
+	}
+
+	var events []FailedEvent
+	for i, v := range values {
+		s, ok := v.(string)
+		if !ok {
+			continue // expired between SCAN and MGET
+		}
+		var fe FailedEvent
+		if err := json.Unmarshal([]byte(s), &fe); err != nil {
+			d.logger.Error("failed to unmarshal failed event", slog.String("key", keys[i]), slog.Any("err", err))
+			continue
+		}
+		if filter.matches(&fe) {
+			events = append(events, fe)
+		}
+	}
+	return sortFailedEvents(events, filter.Limit), nil
+}
+
+func (d *RedisDLQ) scan(ctx context.Context, pattern string) ([]string, error) {
+	var keys []string
+	iter := d.client.Scan(ctx, 0, pattern, dlqScanCount).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return nil, apperr.Wrapf(err, apperr.RedisUnknown, "failed to scan failed events,pattern=%s", pattern)
+	}
+	return keys, nil
+}
+
+// Get returns the failed or quarantined entry for the event.
+func (d *RedisDLQ) Get(ctx context.Context, topic string, eventID uuid.UUID) (*FailedEvent, error) {
+	fe, err := d.get(ctx, failedEventKey(topic, eventID))
+	if apperr.Is(err, apperr.NotFound) {
+		fe, err = d.get(ctx, quarantinedEventKey(topic, eventID))
+	}
+	if apperr.Is(err, apperr.NotFound) {
+		return nil, apperr.Newf(apperr.NotFound, "no dead letter for event %s on topic %s", eventID, topic)
+	}
+	return fe, err
+}
+
+func (d *RedisDLQ) get(ctx context.Context, key string) (*FailedEvent, error) {
+	data, err := d.client.Get(ctx, key).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, apperr.Newf(apperr.NotFound, "no dead letter at %s", key)
+		}
+		return nil, apperr.Wrapf(err, apperr.RedisUnknown, "failed to get dead letter,key=%s", key)
+	}
+	var fe FailedEvent
+	if err := json.Unmarshal(data, &fe); err != nil {
+		return nil, apperr.Wrapf(err, apperr.JSONDecodingFailed, "failed to unmarshal dead letter,key=%s", key)
+	}
+	return &fe, nil
+}
+
+// Replay republishes the event to its topic and records the replay.
+// Quarantined events cannot be replayed.
+func (d *RedisDLQ) Replay(ctx context.Context, topic string, eventID uuid.UUID) (*FailedEvent, error) {
+	fe, err := d.Get(ctx, topic, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if fe.Status == DLQStatusQuarantined {
+		return nil, apperr.Newf(apperr.Conflict, "event %s is quarantined after %d replays", eventID, fe.RetryCount)
+	}
+
+	// Record the replay first: the republished event may fail again before
+	// Publish returns, and that failure must see the new count.
+	now := time.Now().UTC()
+	fe.RetryCount++
+	fe.Status = DLQStatusReplayed
+	fe.LastReplayedAt = &now
+	data, err := json.Marshal(fe)
+	if err != nil {
+		return nil, apperr.Wrapf(err, apperr.JSONEncodingFailed, "%s,topic=%s,event=%s", "failed to marshal event", topic, eventID)
+	}
+	if err := d.client.Set(ctx, failedEventKey(topic, eventID), data, d.policy.retention).Err(); err != nil {
+		return nil, apperr.Wrapf(err, apperr.RedisUnknown, "failed to update dead letter,topic=%s,event=%s", topic, eventID)
+	}
+
+	if err := d.publisher.Publish(ctx, topic, fe.Event); err != nil {
+		return nil, apperr.Wrapf(err, apperr.Internal, "failed to replay event %s to topic %s", eventID, topic)
+	}
+	d.logger.Info("replayed event", slog.String("topic", topic), slog.String("event_id", eventID.String()), slog.Int("retry_count", fe.RetryCount))
+	return fe, nil
+}
+
+func (d *RedisDLQ) Purge(ctx context.Context, topic string, eventID uuid.UUID) error {
+	n, err := d.client.Del(ctx, failedEventKey(topic, eventID), quarantinedEventKey(topic, eventID)).Result()
+	if err != nil {
+		return apperr.Wrapf(err, apperr.RedisUnknown, "failed to purge dead letter,topic=%s,event=%s", topic, eventID)
+	}
+	if n == 0 {
+		return apperr.Newf(apperr.NotFound, "no dead letter for event %s on topic %s", eventID, topic)
+	}
+	return nil
+}
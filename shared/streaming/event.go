@@ -60,15 +60,36 @@ func (a AppType) String() string {
 
 // Event - a generic analytics event
 type Event struct {
-	ID          uuid.UUID    `json:"event_id"`
-	Type        EventType    `json:"event_type"`
-	Timestamp   time.Time    `json:"timestamp"`
-	UserID      uuid.UUID    `json:"user_id"`
-	SchoolID    uuid.UUID    `json:"school_id"`
-	ClassroomID *uuid.UUID   `json:"classroom_id,omitempty"`
-	AppType     AppType      `json:"app_type"`
-	Payload     EventPayload `json:"payload"`
-	Metadata    Metadata     `json:"metadata"`
+	ID            uuid.UUID    `json:"event_id"`
+	Type          EventType    `json:"event_type"`
+	SchemaVersion int          `json:"schema_version"` // of Payload; current for its type once decoded
+	Timestamp     time.Time    `json:"timestamp"`
+	UserID        uuid.UUID    `json:"user_id"`
+	SchoolID      uuid.UUID    `json:"school_id"`
+	ClassroomID   *uuid.UUID   `json:"classroom_id,omitempty"`
+	AppType       AppType      `json:"app_type"`
+	Payload       EventPayload `json:"payload"`
+	Metadata      Metadata     `json:"metadata"`
+
+	// Set by ingestion when the device clock was found to be off: Timestamp
+	// is then corrected by ClockSkewMS and ClientTimestamp keeps the one the
+	// device sent. ClockSkewSuspect flags skews too large to trust.
+	ClientTimestamp  *time.Time `json:"client_timestamp,omitempty"`
+	ClockSkewMS      int64      `json:"clock_skew_ms,omitempty"`
+	ClockSkewSuspect bool       `json:"clock_skew_suspect,omitempty"`
+
+	// receivedSchemaVersion is the schema version the payload was decoded
+	// from, before upcasting.
+	receivedSchemaVersion int
+}
+
+// ReceivedSchemaVersion returns the payload schema version the event was
+// sent with, before it was upcast to the current one.
+func (e *Event) ReceivedSchemaVersion() int {
+	if e.receivedSchemaVersion == 0 {
+		return e.SchemaVersion
+	}
+	return e.receivedSchemaVersion
 }
 
 // Metadata - technical metadata about the event
@@ -77,9 +98,16 @@ type Metadata struct {
 	DeviceType  string  `json:"device_type"`
 	DeviceID    string  `json:"device_id"`
 	NetworkType *string `json:"network_type,omitempty"`
-	ClientTime  *string `json:"client_time,omitempty"`
+	ClientTime  *string `json:"client_time,omitempty"` // device clock when the event was sent, RFC 3339
 	SessionID   *string `json:"session_id,omitempty"`
 	IPAddress   *string `json:"ip_address,omitempty"`
+
+	// Filled in by ingestion from the request that carried the event
+	UserAgent *string `json:"user_agent,omitempty"`
+	OS        *string `json:"os,omitempty"`
+	AppBuild  *string `json:"app_build,omitempty"`
+	RequestID *string `json:"request_id,omitempty"`
+	TraceID   *string `json:"trace_id,omitempty"`
 }
 
 func (e *Event) Validate() error {
@@ -110,33 +138,31 @@ func (e *Event) Validate() error {
 	return nil
 }
 
+// Category returns the category the event type is registered with, or ""
+// for an unknown type.
+func (e *Event) Category() EventCategory {
+	spec, _ := LookupEventType(e.Type)
+	return spec.Category
+}
+
 func (e *Event) IsQuizEvent() bool {
-	switch e.Type {
-	case QuizSessionStarted, QuizQuestionShown, QuizAnswerSubmitted,
-		QuizSessionCompleted, QuizSessionAbandoned, QuizSessionPaused, QuizSessionResumed:
-		return true
-	default:
-		return false
-	}
+	return e.Category() == CategoryQuiz
 }
 
 func (e *Event) IsUserEvent() bool {
-	switch e.Type {
-	case UserLogin, UserLogout, AppInteraction, AppNavigation,
-		AppFocusChange, AppBackground, AppForeground:
-		return true
-	default:
-		return false
-	}
+	return e.Category() == CategoryUser
 }
 
 func (e *Event) IsSystemEvent() bool {
-	switch e.Type {
-	case APIRequest, APIResponse, ErrorOccurred, SystemStartup, SystemShutdown:
-		return true
-	default:
-		return false
+	return e.Category() == CategorySystem
+}
+
+// QuizSessionID returns the quiz session the event belongs to, if any.
+func (e *Event) QuizSessionID() (uuid.UUID, bool) {
+	if p, ok := e.Payload.(SessionPayload); ok {
+		return p.QuizSession()
 	}
+	return uuid.Nil, false
 }
 
 func (e *Event) GetTopic() string {
@@ -145,29 +171,28 @@ func (e *Event) GetTopic() string {
 
 // eventJSON is used for custom JSON marshaling/unmarshaling
 type eventJSON struct {
-	ID          string                 `json:"event_id"`
-	Type        EventType              `json:"event_type"`
-	Timestamp   time.Time              `json:"timestamp"`
-	UserID      string                 `json:"user_id"`
-	SchoolID    string                 `json:"school_id"`
-	ClassroomID *string                `json:"classroom_id,omitempty"`
-	AppType     AppType                `json:"app_type"`
-	Payload     map[string]interface{} `json:"payload"`
-	Metadata    Metadata               `json:"metadata"`
+	ID            string          `json:"event_id"`
+	Type          EventType       `json:"event_type"`
+	SchemaVersion int             `json:"schema_version,omitempty"`
+	Timestamp     time.Time       `json:"timestamp"`
+	UserID        string          `json:"user_id"`
+	SchoolID      string          `json:"school_id"`
+	ClassroomID   *string         `json:"classroom_id,omitempty"`
+	AppType       AppType         `json:"app_type"`
+	Payload       json.RawMessage `json:"payload"`
+	Metadata      Metadata        `json:"metadata"`
+
+	ClientTimestamp  *time.Time `json:"client_timestamp,omitempty"`
+	ClockSkewMS      int64      `json:"clock_skew_ms,omitempty"`
+	ClockSkewSuspect bool       `json:"clock_skew_suspect,omitempty"`
 }
 
 func (e Event) MarshalJSON() ([]byte, error) {
-	// Convert payload to map
 	payloadBytes, err := json.Marshal(e.Payload)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal payload: %w", err)
 	}
 
-	var payloadMap map[string]interface{}
-	if err := json.Unmarshal(payloadBytes, &payloadMap); err != nil {
-		return nil, fmt.Errorf("failed to convert payload to map: %w", err)
-	}
-
 	// Convert UUIDs to strings and prepare classroom ID
 	var classroomIDStr *string
 	if e.ClassroomID != nil {
@@ -175,16 +200,26 @@ func (e Event) MarshalJSON() ([]byte, error) {
 		classroomIDStr = &str
 	}
 
+	schemaVersion := e.SchemaVersion
+	if schemaVersion == 0 {
+		schemaVersion = CurrentSchemaVersion(e.Type)
+	}
+
 	eventData := eventJSON{
-		ID:          e.ID.String(),
-		Type:        e.Type,
-		Timestamp:   e.Timestamp,
-		UserID:      e.UserID.String(),
-		SchoolID:    e.SchoolID.String(),
-		ClassroomID: classroomIDStr,
-		AppType:     e.AppType,
-		Payload:     payloadMap,
-		Metadata:    e.Metadata,
+		ID:            e.ID.String(),
+		Type:          e.Type,
+		SchemaVersion: schemaVersion,
+		Timestamp:     e.Timestamp,
+		UserID:        e.UserID.String(),
+		SchoolID:      e.SchoolID.String(),
+		ClassroomID:   classroomIDStr,
+		AppType:       e.AppType,
+		Payload:       payloadBytes,
+		Metadata:      e.Metadata,
+
+		ClientTimestamp:  e.ClientTimestamp,
+		ClockSkewMS:      e.ClockSkewMS,
+		ClockSkewSuspect: e.ClockSkewSuspect,
 	}
 
 	return json.Marshal(eventData)
@@ -221,8 +256,14 @@ func (e *Event) UnmarshalJSON(data []byte) error {
 		classroomID = &cid
 	}
 
+	// Migrate payloads from older app builds to the current shape
+	payloadData, schemaVersion, err := upcastRaw(eventData.Type, eventData.SchemaVersion, eventData.Payload)
+	if err != nil {
+		return err
+	}
+
 	// Parse payload based on event type
-	payload, err := PayloadFromMap(eventData.Type, eventData.Payload)
+	payload, err := DecodePayload(eventData.Type, payloadData)
 	if err != nil {
 		return fmt.Errorf("failed to parse payload for event type %s: %w", eventData.Type, err)
 	}
@@ -230,6 +271,11 @@ func (e *Event) UnmarshalJSON(data []byte) error {
 	// Set all fields
 	e.ID = eventID
 	e.Type = eventData.Type
+	e.SchemaVersion = schemaVersion
+	e.receivedSchemaVersion = eventData.SchemaVersion
+	if e.receivedSchemaVersion == 0 {
+		e.receivedSchemaVersion = BaseSchemaVersion
+	}
 	e.Timestamp = eventData.Timestamp
 	e.UserID = userID
 	e.SchoolID = schoolID
@@ -237,6 +283,9 @@ func (e *Event) UnmarshalJSON(data []byte) error {
 	e.AppType = eventData.AppType
 	e.Payload = payload
 	e.Metadata = eventData.Metadata
+	e.ClientTimestamp = eventData.ClientTimestamp
+	e.ClockSkewMS = eventData.ClockSkewMS
+	e.ClockSkewSuspect = eventData.ClockSkewSuspect
 
 	return nil
 }
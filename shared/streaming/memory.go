@@ -0,0 +1,342 @@
+package streaming
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lavish-gambhir/dashbeam/pkg/apperr"
+	"github.com/lavish-gambhir/dashbeam/shared/config"
+)
+
+const defaultMemoryBuffer = 1024
+
+// MemoryQueue is an in-process MessageQueue for tests and single-binary
+// deployments. It follows the same topic and glob-pattern matching as
+// PSUBSCRIBE, applies SubscribeOptions retries and keeps events whose handler
+// kept failing in an in-memory dead-letter queue. Events go through a codec
+// round-trip on publish, so handlers see exactly what they would receive from
+// Valkey with the same codec.
+//
+// Delivery is asynchronous and ordered per subscription, unless
+// SubscribeOptions.Concurrency lets handlers run at once; Flush waits until
+// every event published so far has been handled.
+type MemoryQueue struct {
+	codec      Codec
+	logger     *slog.Logger
+	bufferSize int
+
+	mu   sync.RWMutex
+	wg   sync.WaitGroup
+	subs map[string]*memorySubscription
+	dlq  *MemoryDLQ
+}
+
+type memorySubscription struct {
+	topic   string
+	pattern bool
+	handler func(Event) error
+	opts    *SubscribeOptions
+	msgs    chan memoryMessage
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+type memoryMessage struct {
+	topic string
+	data  []byte
+	// barrier is closed once the message is reached, used by Flush.
+	barrier chan struct{}
+}
+
+func NewMemoryQueue(codec Codec, logger *slog.Logger) *MemoryQueue {
+	q := &MemoryQueue{
+		codec:      codec,
+		logger:     logger.With("component", "memory_queue"),
+		bufferSize: defaultMemoryBuffer,
+		subs:       make(map[string]*memorySubscription),
+	}
+	q.dlq = NewMemoryDLQ(q, config.QueueConfig{}, logger)
+	return q
+}
+
+func (q *MemoryQueue) Publish(ctx context.Context, topic string, event Event) error {
+	if event.ID == uuid.Nil {
+		id, err := uuid.NewV7()
+		if err != nil {
+			return err
+		}
+		event.ID = id
+	}
+
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now().UTC()
+	}
+	payload, err := q.codec.Marshal(event)
+	if err != nil {
+		return apperr.Wrapf(err, apperr.JSONEncodingFailed, "%s, %v", "failed to marshal event", event.ID)
+	}
+
+	msg := memoryMessage{topic: topic, data: payload}
+	for _, sub := range q.matching(topic) {
+		select {
+		case sub.msgs <- msg:
+		case <-sub.done:
+		case <-ctx.Done():
+			return apperr.Wrapf(ctx.Err(), apperr.Internal, "failed to publish event %v to topic %s", event.ID, topic)
+		}
+	}
+	q.logger.Debug("published message", slog.String("topic", topic), slog.String("event_id", event.ID.String()), slog.String("event_type", event.Type.String()))
+	return nil
+}
+
+func (q *MemoryQueue) matching(topic string) []*memorySubscription {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	var subs []*memorySubscription
+	for _, sub := range q.subs {
+		if sub.topic == topic || (sub.pattern && MatchTopic(sub.topic, topic)) {
+			subs = append(subs, sub)
+		}
+	}
+	return subs
+}
+
+// Subscribe registers handler for topic (a glob pattern when opts.Pattern is
+// set). Subscribing again to the same topic replaces the previous handler.
+func (q *MemoryQueue) Subscribe(ctx context.Context, topic string, handler func(Event) error, opts *SubscribeOptions) {
+	if opts == nil {
+		opts = DefaultSubscriberOptions()
+	}
+
+	subCtx, cancel := context.WithCancel(ctx)
+	sub := &memorySubscription{
+		topic:   topic,
+		pattern: opts.Pattern,
+		handler: handler,
+		opts:    opts,
+		msgs:    make(chan memoryMessage, q.bufferSize),
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+
+	q.mu.Lock()
+	if existing, exists := q.subs[topic]; exists {
+		existing.cancel()
+	}
+	q.subs[topic] = sub
+	q.mu.Unlock()
+
+	q.wg.Add(1)
+	go func() {
+		defer q.wg.Done()
+		q.consume(subCtx, sub)
+	}()
+}
+
+func (q *MemoryQueue) consume(ctx context.Context, sub *memorySubscription) {
+	defer close(sub.done)
+	logger := q.logger.With("subscription", sub.topic)
+	handling := newInflight(sub.opts.Concurrency)
+	defer handling.wait()
+	for {
+		select {
+		case <-ctx.Done():
+			q.mu.Lock()
+			if q.subs[sub.topic] == sub {
+				delete(q.subs, sub.topic)
+			}
+			q.mu.Unlock()
+			if n := len(sub.msgs); n > 0 {
+				logger.Warn("subscription stopped with undelivered events", slog.Int("count", n))
+			}
+			return
+		case msg := <-sub.msgs:
+			if msg.barrier != nil {
+				handling.wait()
+				close(msg.barrier)
+				continue
+			}
+			var ev Event
+			if err := q.codec.Unmarshal(msg.data, &ev); err != nil {
+				logger.Error("failed to unmarshal event", slog.String("topic", msg.topic), slog.Any("err", err))
+				continue
+			}
+			handling.run(func() {
+				if err := handleWithRetry(ctx, logger, sub.handler, ev, sub.opts); err != nil {
+					if ctx.Err() != nil {
+						return
+					}
+					logger.Error("handler failed with retries", slog.String("topic", msg.topic), slog.String("event_id", ev.ID.String()), slog.Any("err", err))
+					if err := q.dlq.Store(ctx, msg.topic, ev, err); err != nil {
+						logger.Error("failed to store failed event", slog.String("event_id", ev.ID.String()), slog.Any("err", err))
+					}
+				}
+			})
+		}
+	}
+}
+
+// DeadLetters returns the store holding events whose handler kept failing.
+func (q *MemoryQueue) DeadLetters() DeadLetterQueue {
+	return q.dlq
+}
+
+// Flush blocks until every subscription has handled the events published
+// before the call, or ctx is done.
+func (q *MemoryQueue) Flush(ctx context.Context) error {
+	q.mu.RLock()
+	subs := make([]*memorySubscription, 0, len(q.subs))
+	for _, sub := range q.subs {
+		subs = append(subs, sub)
+	}
+	q.mu.RUnlock()
+
+	for _, sub := range subs {
+		barrier := make(chan struct{})
+		select {
+		case sub.msgs <- memoryMessage{barrier: barrier}:
+		case <-sub.done:
+			continue
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		select {
+		case <-barrier:
+		case <-sub.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// Close stops all subscriptions and waits for in-flight handlers.
+func (q *MemoryQueue) Close() error {
+	q.mu.RLock()
+	for _, sub := range q.subs {
+		sub.cancel()
+	}
+	q.mu.RUnlock()
+	q.wg.Wait()
+	return nil
+}
+
+// MemoryDLQ is the in-memory DeadLetterQueue used by MemoryQueue. It applies
+// the same replay and quarantine rules as RedisDLQ, without expiry.
+type MemoryDLQ struct {
+	publisher MessageQueue
+	policy    dlqPolicy
+	logger    *slog.Logger
+
+	mu     sync.RWMutex
+	events map[string]FailedEvent // keyed by failed/quarantined event key
+}
+
+func NewMemoryDLQ(publisher MessageQueue, cfg config.QueueConfig, logger *slog.Logger) *MemoryDLQ {
+	return &MemoryDLQ{
+		publisher: publisher,
+		policy:    newDLQPolicy(cfg),
+		logger:    logger.With("component", "dlq"),
+		events:    make(map[string]FailedEvent),
+	}
+}
+
+func (d *MemoryDLQ) Store(_ context.Context, topic string, event Event, err error) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	key := failedEventKey(topic, event.ID)
+	var prev *FailedEvent
+	if fe, ok := d.events[key]; ok {
+		prev = &fe
+	}
+	fe := d.policy.failed(prev, topic, event, err)
+	if fe.Status == DLQStatusQuarantined {
+		delete(d.events, key)
+		key = quarantinedEventKey(topic, event.ID)
+		d.logger.Warn("event quarantined after replays", slog.String("topic", topic), slog.String("event_id", event.ID.String()), slog.Int("retry_count", fe.RetryCount))
+	}
+	d.events[key] = fe
+	return nil
+}
+
+func (d *MemoryDLQ) List(_ context.Context, filter DLQFilter) ([]FailedEvent, error) {
+	if err := filter.validate(); err != nil {
+		return nil, err
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	var events []FailedEvent
+	for key, fe := range d.events {
+		quarantined := strings.HasPrefix(key, quarantinedEventsPrefix)
+		if quarantined != (filter.Status == DLQStatusQuarantined) {
+			continue
+		}
+		if filter.matches(&fe) {
+			events = append(events, fe)
+		}
+	}
+	return sortFailedEvents(events, filter.Limit), nil
+}
+
+func (d *MemoryDLQ) Get(_ context.Context, topic string, eventID uuid.UUID) (*FailedEvent, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.get(topic, eventID)
+}
+
+func (d *MemoryDLQ) get(topic string, eventID uuid.UUID) (*FailedEvent, error) {
+	for _, key := range []string{failedEventKey(topic, eventID), quarantinedEventKey(topic, eventID)} {
+		if fe, ok := d.events[key]; ok {
+			return &fe, nil
+		}
+	}
+	return nil, apperr.Newf(apperr.NotFound, "no dead letter for event %s on topic %s", eventID, topic)
+}
+
+func (d *MemoryDLQ) Replay(ctx context.Context, topic string, eventID uuid.UUID) (*FailedEvent, error) {
+	d.mu.Lock()
+	fe, err := d.get(topic, eventID)
+	if err != nil {
+		d.mu.Unlock()
+		return nil, err
+	}
+	if fe.Status == DLQStatusQuarantined {
+		d.mu.Unlock()
+		return nil, apperr.Newf(apperr.Conflict, "event %s is quarantined after %d replays", eventID, fe.RetryCount)
+	}
+	now := time.Now().UTC()
+	fe.RetryCount++
+	fe.Status = DLQStatusReplayed
+	fe.LastReplayedAt = &now
+	d.events[failedEventKey(topic, eventID)] = *fe
+	d.mu.Unlock()
+
+	if err := d.publisher.Publish(ctx, topic, fe.Event); err != nil {
+		return nil, apperr.Wrapf(err, apperr.Internal, "failed to replay event %s to topic %s", eventID, topic)
+	}
+	return fe, nil
+}
+
+func (d *MemoryDLQ) Purge(_ context.Context, topic string, eventID uuid.UUID) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var found bool
+	for _, key := range []string{failedEventKey(topic, eventID), quarantinedEventKey(topic, eventID)} {
+		if _, ok := d.events[key]; ok {
+			delete(d.events, key)
+			found = true
+		}
+	}
+	if !found {
+		return apperr.Newf(apperr.NotFound, "no dead letter for event %s on topic %s", eventID, topic)
+	}
+	return nil
+}
@@ -0,0 +1,337 @@
+package streaming
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func newTestMemoryQueue(t *testing.T) *MemoryQueue {
+	t.Helper()
+	q := NewMemoryQueue(JSONCodec, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	t.Cleanup(func() { q.Close() })
+	return q
+}
+
+func testEvent(t *testing.T) Event {
+	t.Helper()
+	id, err := uuid.NewV7()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Event{
+		ID:        id,
+		Type:      SystemStartup,
+		Timestamp: time.Now().UTC(),
+		UserID:    uuid.New(),
+		SchoolID:  uuid.New(),
+		Payload:   SystemStartupPayload{ColdStart: true},
+	}
+}
+
+// recorder collects the IDs of the events a handler saw, in order.
+type recorder struct {
+	mu  sync.Mutex
+	ids []uuid.UUID
+}
+
+func (r *recorder) handle(ev Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ids = append(r.ids, ev.ID)
+	return nil
+}
+
+func (r *recorder) seen() []uuid.UUID {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.ids)
+}
+
+func fastRetries(maxRetries int) *SubscribeOptions {
+	return &SubscribeOptions{
+		MaxRetries:   maxRetries,
+		RetryBackoff: []time.Duration{time.Millisecond},
+	}
+}
+
+func TestMemoryQueuePublishSubscribe(t *testing.T) {
+	tests := []struct {
+		name    string
+		topic   string
+		pattern bool
+		publish []string
+		want    []int // indexes into publish of the events delivered, in order
+	}{
+		{
+			name:    "exact topic",
+			topic:   TopicQuizEvents,
+			publish: []string{TopicQuizEvents, TopicUserEvents, TopicQuizEvents},
+			want:    []int{0, 2},
+		},
+		{
+			name:    "pattern",
+			topic:   TopicPatternAll,
+			pattern: true,
+			publish: []string{TopicQuizEvents, TopicUserEvents, "other", TopicSystemEvents},
+			want:    []int{0, 1, 3},
+		},
+		{
+			name:    "pattern matched literally without the option",
+			topic:   TopicPatternAll,
+			publish: []string{TopicQuizEvents, TopicPatternAll},
+			want:    []int{1},
+		},
+		{
+			name:    "no matching topic",
+			topic:   TopicQuizEvents,
+			publish: []string{TopicUserEvents, TopicSystemEvents},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := newTestMemoryQueue(t)
+			ctx := context.Background()
+
+			var rec recorder
+			opts := fastRetries(0)
+			opts.Pattern = tt.pattern
+			q.Subscribe(ctx, tt.topic, rec.handle, opts)
+
+			var published []uuid.UUID
+			for _, topic := range tt.publish {
+				ev := testEvent(t)
+				if err := q.Publish(ctx, topic, ev); err != nil {
+					t.Fatalf("Publish(%s): %v", topic, err)
+				}
+				published = append(published, ev.ID)
+			}
+			if err := q.Flush(ctx); err != nil {
+				t.Fatalf("Flush: %v", err)
+			}
+
+			var want []uuid.UUID
+			for _, i := range tt.want {
+				want = append(want, published[i])
+			}
+			if got := rec.seen(); !slices.Equal(got, want) {
+				t.Errorf("delivered %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestMemoryQueuePublishRoundTrip(t *testing.T) {
+	q := newTestMemoryQueue(t)
+	ctx := context.Background()
+
+	got := make(chan Event, 1)
+	q.Subscribe(ctx, TopicSystemEvents, func(ev Event) error {
+		got <- ev
+		return nil
+	}, fastRetries(0))
+
+	ev := testEvent(t)
+	ev.ID = uuid.Nil
+	ev.Timestamp = time.Time{}
+	if err := q.Publish(ctx, TopicSystemEvents, ev); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	delivered := <-got
+	if delivered.ID == uuid.Nil {
+		t.Error("published event got no ID")
+	}
+	if delivered.Timestamp.IsZero() {
+		t.Error("published event got no timestamp")
+	}
+	if delivered.UserID != ev.UserID || delivered.SchoolID != ev.SchoolID || delivered.Type != ev.Type {
+		t.Errorf("delivered %+v, want the fields of %+v", delivered, ev)
+	}
+}
+
+func TestMemoryQueueFlush(t *testing.T) {
+	q := newTestMemoryQueue(t)
+	ctx := context.Background()
+
+	// Handlers that take a while still finish before Flush returns.
+	var rec recorder
+	slow := func(ev Event) error {
+		time.Sleep(5 * time.Millisecond)
+		return rec.handle(ev)
+	}
+	q.Subscribe(ctx, TopicQuizEvents, slow, fastRetries(0))
+	q.Subscribe(ctx, TopicPatternAll, slow, &SubscribeOptions{Pattern: true})
+
+	const n = 10
+	for range n {
+		if err := q.Publish(ctx, TopicQuizEvents, testEvent(t)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := q.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(rec.seen()); got != 2*n {
+		t.Errorf("handled %d events by Flush, want %d", got, 2*n)
+	}
+
+	// Flush gives up when ctx is done before the events are handled.
+	block := make(chan struct{})
+	defer close(block)
+	q.Subscribe(ctx, TopicUserEvents, func(Event) error {
+		<-block
+		return nil
+	}, fastRetries(0))
+	if err := q.Publish(ctx, TopicUserEvents, testEvent(t)); err != nil {
+		t.Fatal(err)
+	}
+	tctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if err := q.Flush(tctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Flush with a blocked handler = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestMemoryQueueHandlerErrors(t *testing.T) {
+	errHandler := errors.New("handler failed")
+	tests := []struct {
+		name       string
+		maxRetries int
+		failures   int // number of calls failing before the handler succeeds
+		wantCalls  int
+		wantFailed bool
+	}{
+		{name: "succeeds", maxRetries: 2, failures: 0, wantCalls: 1},
+		{name: "succeeds on retry", maxRetries: 2, failures: 2, wantCalls: 3},
+		{name: "retries exhausted", maxRetries: 2, failures: 3, wantCalls: 3, wantFailed: true},
+		{name: "no retries", maxRetries: 0, failures: 1, wantCalls: 1, wantFailed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := newTestMemoryQueue(t)
+			ctx := context.Background()
+
+			var calls int
+			q.Subscribe(ctx, TopicQuizEvents, func(Event) error {
+				calls++
+				if calls <= tt.failures {
+					return errHandler
+				}
+				return nil
+			}, fastRetries(tt.maxRetries))
+
+			ev := testEvent(t)
+			if err := q.Publish(ctx, TopicQuizEvents, ev); err != nil {
+				t.Fatal(err)
+			}
+			if err := q.Flush(ctx); err != nil {
+				t.Fatal(err)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("handler called %d times, want %d", calls, tt.wantCalls)
+			}
+
+			failed, err := q.DeadLetters().List(ctx, DLQFilter{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !tt.wantFailed {
+				if len(failed) != 0 {
+					t.Errorf("dead letters = %+v, want none", failed)
+				}
+				return
+			}
+			if len(failed) != 1 {
+				t.Fatalf("got %d dead letters, want 1", len(failed))
+			}
+			fe := failed[0]
+			if fe.Event.ID != ev.ID || fe.Topic != TopicQuizEvents || fe.Status != DLQStatusFailed || fe.Error != errHandler.Error() {
+				t.Errorf("dead letter = %+v, want event %s on %s failed with %q", fe, ev.ID, TopicQuizEvents, errHandler)
+			}
+		})
+	}
+}
+
+// Dead letters are listed most recent failure first.
+func TestMemoryQueueFailedEventsOrder(t *testing.T) {
+	q := newTestMemoryQueue(t)
+	ctx := context.Background()
+
+	q.Subscribe(ctx, TopicPatternAll, func(Event) error {
+		return errors.New("handler failed")
+	}, &SubscribeOptions{Pattern: true, RetryBackoff: []time.Duration{time.Millisecond}})
+
+	var published []uuid.UUID
+	for _, topic := range []string{TopicQuizEvents, TopicUserEvents, TopicQuizEvents} {
+		ev := testEvent(t)
+		if err := q.Publish(ctx, topic, ev); err != nil {
+			t.Fatal(err)
+		}
+		published = append(published, ev.ID)
+		// Failure times are compared, keep them apart.
+		if err := q.Flush(ctx); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	tests := []struct {
+		name   string
+		filter DLQFilter
+		want   []uuid.UUID
+	}{
+		{name: "all", filter: DLQFilter{}, want: []uuid.UUID{published[2], published[1], published[0]}},
+		{name: "by topic", filter: DLQFilter{Topic: TopicQuizEvents}, want: []uuid.UUID{published[2], published[0]}},
+		{name: "limit", filter: DLQFilter{Limit: 2}, want: []uuid.UUID{published[2], published[1]}},
+		{name: "quarantined", filter: DLQFilter{Status: DLQStatusQuarantined}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failed, err := q.DeadLetters().List(ctx, tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []uuid.UUID
+			for _, fe := range failed {
+				got = append(got, fe.Event.ID)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("List(%+v) = %v, want %v", tt.filter, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryQueueSubscribeReplaces(t *testing.T) {
+	q := newTestMemoryQueue(t)
+	ctx := context.Background()
+
+	var first, second recorder
+	q.Subscribe(ctx, TopicQuizEvents, first.handle, fastRetries(0))
+	q.Subscribe(ctx, TopicQuizEvents, second.handle, fastRetries(0))
+
+	ev := testEvent(t)
+	if err := q.Publish(ctx, TopicQuizEvents, ev); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := first.seen(); len(got) != 0 {
+		t.Errorf("replaced handler saw %v", got)
+	}
+	if got := second.seen(); !slices.Equal(got, []uuid.UUID{ev.ID}) {
+		t.Errorf("handler saw %v, want %v", got, []uuid.UUID{ev.ID})
+	}
+}
@@ -2,7 +2,6 @@ package streaming
 
 import (
 	"encoding/json"
-	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,6 +12,12 @@ type EventPayload interface {
 	Validate() error
 }
 
+// SessionPayload is implemented by payloads of events that can belong to a
+// quiz session. Events of one session are processed in timestamp order.
+type SessionPayload interface {
+	QuizSession() (uuid.UUID, bool)
+}
+
 type QuizSessionStartedPayload struct {
 	QuizID         uuid.UUID `json:"quiz_id"`
 	SessionID      uuid.UUID `json:"session_id"`
@@ -39,6 +44,10 @@ func (p QuizSessionStartedPayload) Validate() error {
 	return nil
 }
 
+func (p QuizSessionStartedPayload) QuizSession() (uuid.UUID, bool) {
+	return p.SessionID, true
+}
+
 type QuizQuestionShownPayload struct {
 	QuizID           uuid.UUID `json:"quiz_id"`
 	SessionID        uuid.UUID `json:"session_id"`
@@ -59,6 +68,10 @@ func (p QuizQuestionShownPayload) Validate() error {
 	return nil
 }
 
+func (p QuizQuestionShownPayload) QuizSession() (uuid.UUID, bool) {
+	return p.SessionID, true
+}
+
 type QuizAnswerSubmittedPayload struct {
 	QuizID           uuid.UUID       `json:"quiz_id"`
 	SessionID        uuid.UUID       `json:"session_id"`
@@ -85,6 +98,10 @@ func (p QuizAnswerSubmittedPayload) Validate() error {
 	return nil
 }
 
+func (p QuizAnswerSubmittedPayload) QuizSession() (uuid.UUID, bool) {
+	return p.SessionID, true
+}
+
 type QuizSessionCompletedPayload struct {
 	QuizID              uuid.UUID `json:"quiz_id"`
 	SessionID           uuid.UUID `json:"session_id"`
@@ -111,6 +128,80 @@ func (p QuizSessionCompletedPayload) Validate() error {
 	return nil
 }
 
+func (p QuizSessionCompletedPayload) QuizSession() (uuid.UUID, bool) {
+	return p.SessionID, true
+}
+
+type QuizSessionAbandonedPayload struct {
+	QuizID            uuid.UUID `json:"quiz_id"`
+	SessionID         uuid.UUID `json:"session_id"`
+	QuestionsAnswered int       `json:"questions_answered"`
+	TimeSpentMS       int       `json:"time_spent_ms"`
+	Reason            string    `json:"reason"` // e.g. closed, timeout, navigated_away
+}
+
+func (p QuizSessionAbandonedPayload) Type() string { return QuizSessionAbandoned.String() }
+func (p QuizSessionAbandonedPayload) Validate() error {
+	if p.QuizID == uuid.Nil || p.SessionID == uuid.Nil {
+		return ErrInvalidPayload
+	}
+	if p.QuestionsAnswered < 0 || p.TimeSpentMS < 0 {
+		return ErrInvalidPayload
+	}
+	return nil
+}
+
+func (p QuizSessionAbandonedPayload) QuizSession() (uuid.UUID, bool) {
+	return p.SessionID, true
+}
+
+type QuizSessionPausedPayload struct {
+	QuizID           uuid.UUID `json:"quiz_id"`
+	SessionID        uuid.UUID `json:"session_id"`
+	QuestionSequence *int      `json:"question_sequence,omitempty"`
+	Reason           string    `json:"reason"` // e.g. manual, teacher, app_background
+}
+
+func (p QuizSessionPausedPayload) Type() string { return QuizSessionPaused.String() }
+func (p QuizSessionPausedPayload) Validate() error {
+	if p.QuizID == uuid.Nil || p.SessionID == uuid.Nil {
+		return ErrInvalidPayload
+	}
+	if p.QuestionSequence != nil && *p.QuestionSequence <= 0 {
+		return ErrInvalidPayload
+	}
+	return nil
+}
+
+func (p QuizSessionPausedPayload) QuizSession() (uuid.UUID, bool) {
+	return p.SessionID, true
+}
+
+type QuizSessionResumedPayload struct {
+	QuizID           uuid.UUID `json:"quiz_id"`
+	SessionID        uuid.UUID `json:"session_id"`
+	QuestionSequence *int      `json:"question_sequence,omitempty"`
+	PausedDurationMS int       `json:"paused_duration_ms"`
+}
+
+func (p QuizSessionResumedPayload) Type() string { return QuizSessionResumed.String() }
+func (p QuizSessionResumedPayload) Validate() error {
+	if p.QuizID == uuid.Nil || p.SessionID == uuid.Nil {
+		return ErrInvalidPayload
+	}
+	if p.QuestionSequence != nil && *p.QuestionSequence <= 0 {
+		return ErrInvalidPayload
+	}
+	if p.PausedDurationMS < 0 {
+		return ErrInvalidPayload
+	}
+	return nil
+}
+
+func (p QuizSessionResumedPayload) QuizSession() (uuid.UUID, bool) {
+	return p.SessionID, true
+}
+
 // User Event Payloads
 
 type UserLoginPayload struct {
@@ -121,7 +212,7 @@ type UserLoginPayload struct {
 	IPAddress     *string    `json:"ip_address,omitempty"`
 	Email         *string    `json:"email,omitempty"`
 	Name          *string    `json:"name,omitempty"`
-	Role          *string    `json:"role,omitempty"`
+	Role          *string    `json:"role,omitempty"` // ignored by ingestion, which takes the role from the token
 }
 
 func (p UserLoginPayload) Type() string { return UserLogin.String() }
@@ -178,6 +269,63 @@ func (p AppNavigationPayload) Validate() error {
 	return nil
 }
 
+type AppFocusChangePayload struct {
+	HasFocus      bool       `json:"has_focus"`
+	ScreenName    string     `json:"screen_name"`
+	QuizSessionID *uuid.UUID `json:"quiz_session_id,omitempty"` // set when focus changed during a quiz
+	DurationMS    *int       `json:"duration_ms,omitempty"`     // time spent in the previous focus state
+}
+
+func (p AppFocusChangePayload) Type() string { return AppFocusChange.String() }
+func (p AppFocusChangePayload) Validate() error {
+	if p.ScreenName == "" {
+		return ErrInvalidPayload
+	}
+	if p.DurationMS != nil && *p.DurationMS < 0 {
+		return ErrInvalidPayload
+	}
+	return nil
+}
+
+func (p AppFocusChangePayload) QuizSession() (uuid.UUID, bool) {
+	if p.QuizSessionID == nil {
+		return uuid.Nil, false
+	}
+	return *p.QuizSessionID, true
+}
+
+type AppBackgroundPayload struct {
+	ScreenName           string `json:"screen_name"`
+	ForegroundDurationMS *int   `json:"foreground_duration_ms,omitempty"`
+}
+
+func (p AppBackgroundPayload) Type() string { return AppBackground.String() }
+func (p AppBackgroundPayload) Validate() error {
+	if p.ScreenName == "" {
+		return ErrInvalidPayload
+	}
+	if p.ForegroundDurationMS != nil && *p.ForegroundDurationMS < 0 {
+		return ErrInvalidPayload
+	}
+	return nil
+}
+
+type AppForegroundPayload struct {
+	ScreenName           string `json:"screen_name"`
+	BackgroundDurationMS *int   `json:"background_duration_ms,omitempty"`
+}
+
+func (p AppForegroundPayload) Type() string { return AppForeground.String() }
+func (p AppForegroundPayload) Validate() error {
+	if p.ScreenName == "" {
+		return ErrInvalidPayload
+	}
+	if p.BackgroundDurationMS != nil && *p.BackgroundDurationMS < 0 {
+		return ErrInvalidPayload
+	}
+	return nil
+}
+
 // System Event Payloads
 
 // APIRequestPayload contains data for API request events
@@ -199,6 +347,27 @@ func (p APIRequestPayload) Validate() error {
 	return nil
 }
 
+// APIResponsePayload contains data for API response events, as seen by the app
+type APIResponsePayload struct {
+	Method       string  `json:"method"`
+	Endpoint     string  `json:"endpoint"`
+	StatusCode   int     `json:"status_code"`
+	ResponseTime *int    `json:"response_time_ms,omitempty"`
+	ErrorCode    *string `json:"error_code,omitempty"`
+	ResponseSize *int    `json:"response_size_bytes,omitempty"`
+}
+
+func (p APIResponsePayload) Type() string { return APIResponse.String() }
+func (p APIResponsePayload) Validate() error {
+	if p.Method == "" || p.Endpoint == "" {
+		return ErrInvalidPayload
+	}
+	if p.StatusCode < 100 || p.StatusCode > 599 {
+		return ErrInvalidPayload
+	}
+	return nil
+}
+
 type ErrorOccurredPayload struct {
 	ErrorType    string  `json:"error_type"`
 	ErrorMessage string  `json:"error_message"`
@@ -216,99 +385,53 @@ func (p ErrorOccurredPayload) Validate() error {
 	return nil
 }
 
-// PayloadFromMap converts a map to a specific payload type based on event type
-func PayloadFromMap(eventType EventType, data map[string]any) (EventPayload, error) {
-	if data == nil {
-		return nil, fmt.Errorf("payload data is empty")
-	}
-
-	jsonData, err := json.Marshal(data)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal payload data: %w", err)
-	}
-
-	switch eventType {
-	case QuizSessionStarted:
-		var payload QuizSessionStartedPayload
-		if err := json.Unmarshal(jsonData, &payload); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal QuizSessionStartedPayload: %w", err)
-		}
-		if err := payload.Validate(); err != nil {
-			return nil, fmt.Errorf("QuizSessionStartedPayload validation failed: %w", err)
-		}
-		return payload, payload.Validate()
-
-	case QuizQuestionShown:
-		var payload QuizQuestionShownPayload
-		if err := json.Unmarshal(jsonData, &payload); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal QuizQuestionShownPayload: %w", err)
-		}
-		if err := payload.Validate(); err != nil {
-			return nil, fmt.Errorf("QuizQuestionShownPayload validation failed: %w", err)
-		}
-		return payload, payload.Validate()
-
-	case QuizAnswerSubmitted:
-		var payload QuizAnswerSubmittedPayload
-		if err := json.Unmarshal(jsonData, &payload); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal QuizAnswerSubmittedPayload: %w", err)
-		}
-		if err := payload.Validate(); err != nil {
-			return nil, fmt.Errorf("QuizAnswerSubmittedPayload validation failed: %w", err)
-		}
-		return payload, payload.Validate()
-
-	case QuizSessionCompleted:
-		var payload QuizSessionCompletedPayload
-		if err := json.Unmarshal(jsonData, &payload); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal QuizSessionCompletedPayload: %w", err)
-		}
-		if err := payload.Validate(); err != nil {
-			return nil, fmt.Errorf("QuizSessionCompletedPayload validation failed: %w", err)
-		}
-		return payload, payload.Validate()
-
-	case UserLogin:
-		var payload UserLoginPayload
-		if err := json.Unmarshal(jsonData, &payload); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal UserLoginPayload: %w", err)
-		}
-		if err := payload.Validate(); err != nil {
-			return nil, fmt.Errorf("UserLoginPayload validation failed: %w", err)
-		}
-		return payload, payload.Validate()
-
-	case UserLogout:
-		var payload UserLogoutPayload
-		if err := json.Unmarshal(jsonData, &payload); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal UserLogoutPayload: %w", err)
-		}
-		if err := payload.Validate(); err != nil {
-			return nil, fmt.Errorf("UserLogoutPayload validation failed: %w", err)
-		}
-		return payload, payload.Validate()
-
-	case AppInteraction:
-		var payload AppInteractionPayload
-		if err := json.Unmarshal(jsonData, &payload); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal AppInteractionPayload: %w", err)
-		}
-		if err := payload.Validate(); err != nil {
-			return nil, fmt.Errorf("AppInteractionPayload validation failed: %w", err)
-		}
-		return payload, payload.Validate()
-
-	case AppNavigation:
-		var payload AppNavigationPayload
-		if err := json.Unmarshal(jsonData, &payload); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal AppNavigationPayload: %w", err)
-		}
-		if err := payload.Validate(); err != nil {
-			return nil, fmt.Errorf("AppNaviationPayload validation failed: %w", err)
-		}
-		return payload, payload.Validate()
-
-	default:
-		return nil, fmt.Errorf("unknown event type: %s", eventType)
+type SystemStartupPayload struct {
+	ColdStart     bool    `json:"cold_start"`
+	StartupTimeMS *int    `json:"startup_time_ms,omitempty"`
+	OSVersion     *string `json:"os_version,omitempty"`
+}
+
+func (p SystemStartupPayload) Type() string { return SystemStartup.String() }
+func (p SystemStartupPayload) Validate() error {
+	if p.StartupTimeMS != nil && *p.StartupTimeMS < 0 {
+		return ErrInvalidPayload
 	}
+	return nil
+}
+
+type SystemShutdownPayload struct {
+	Reason   string `json:"reason"` // e.g. user, low_battery, update, crash
+	UptimeMS int    `json:"uptime_ms"`
+}
+
+func (p SystemShutdownPayload) Type() string { return SystemShutdown.String() }
+func (p SystemShutdownPayload) Validate() error {
+	if p.Reason == "" || p.UptimeMS < 0 {
+		return ErrInvalidPayload
+	}
+	return nil
+}
+
+func init() {
+	RegisterEventType[QuizSessionStartedPayload](QuizSessionStarted, TopicQuizEvents, CategoryQuiz)
+	RegisterEventType[QuizQuestionShownPayload](QuizQuestionShown, TopicQuizEvents, CategoryQuiz)
+	RegisterEventType[QuizAnswerSubmittedPayload](QuizAnswerSubmitted, TopicQuizEvents, CategoryQuiz)
+	RegisterEventType[QuizSessionCompletedPayload](QuizSessionCompleted, TopicQuizEvents, CategoryQuiz)
+	RegisterEventType[QuizSessionAbandonedPayload](QuizSessionAbandoned, TopicQuizEvents, CategoryQuiz)
+	RegisterEventType[QuizSessionPausedPayload](QuizSessionPaused, TopicQuizEvents, CategoryQuiz)
+	RegisterEventType[QuizSessionResumedPayload](QuizSessionResumed, TopicQuizEvents, CategoryQuiz)
+
+	RegisterEventType[UserLoginPayload](UserLogin, TopicUserEvents, CategoryUser)
+	RegisterEventType[UserLogoutPayload](UserLogout, TopicUserEvents, CategoryUser)
+	RegisterEventType[AppInteractionPayload](AppInteraction, TopicEngagementEvents, CategoryUser)
+	RegisterEventType[AppNavigationPayload](AppNavigation, TopicEngagementEvents, CategoryUser)
+	RegisterEventType[AppFocusChangePayload](AppFocusChange, TopicEngagementEvents, CategoryUser)
+	RegisterEventType[AppBackgroundPayload](AppBackground, TopicEngagementEvents, CategoryUser)
+	RegisterEventType[AppForegroundPayload](AppForeground, TopicEngagementEvents, CategoryUser)
+
+	RegisterEventType[APIRequestPayload](APIRequest, TopicSystemEvents, CategorySystem)
+	RegisterEventType[APIResponsePayload](APIResponse, TopicSystemEvents, CategorySystem)
+	RegisterEventType[ErrorOccurredPayload](ErrorOccurred, TopicSystemEvents, CategorySystem)
+	RegisterEventType[SystemStartupPayload](SystemStartup, TopicSystemEvents, CategorySystem)
+	RegisterEventType[SystemShutdownPayload](SystemShutdown, TopicSystemEvents, CategorySystem)
 }
@@ -0,0 +1,115 @@
+package streaming
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/lavish-gambhir/dashbeam/shared/models"
+)
+
+// EventCategory groups event types by the ingestion endpoint accepting them.
+type EventCategory string
+
+const (
+	CategoryQuiz   EventCategory = "quiz"
+	CategoryUser   EventCategory = "user"
+	CategorySystem EventCategory = "system"
+)
+
+// AnalyticsTransformer fills in the type-specific fields of the analytics
+// record for an event; base already holds the fields common to all events.
+type AnalyticsTransformer func(base models.AnalyticsRecord, event Event) (models.AnalyticsRecord, error)
+
+// EventSpec is what the registry knows about an event type.
+type EventSpec struct {
+	Type      EventType
+	Topic     string
+	Category  EventCategory
+	Transform AnalyticsTransformer // nil until the analytics service registers one
+
+	// decode decodes a payload of the type with unmarshal, which fills in
+	// the value it is given from the wire format at hand.
+	decode func(unmarshal func(v any) error) (EventPayload, error)
+}
+
+var registry = struct {
+	sync.RWMutex
+	specs map[EventType]*EventSpec
+}{specs: make(map[EventType]*EventSpec)}
+
+// RegisterEventType registers eventType with payload type P, the topic its
+// events are published to and its category. Register event types from init
+// functions; registering one twice panics.
+func RegisterEventType[P EventPayload](eventType EventType, topic string, category EventCategory) {
+	registry.Lock()
+	defer registry.Unlock()
+	if _, dup := registry.specs[eventType]; dup {
+		panic(fmt.Sprintf("streaming: event type %s registered twice", eventType))
+	}
+	registry.specs[eventType] = &EventSpec{
+		Type:     eventType,
+		Topic:    topic,
+		Category: category,
+		decode: func(unmarshal func(v any) error) (EventPayload, error) {
+			var payload P
+			if err := unmarshal(&payload); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal %T: %w", payload, err)
+			}
+			if err := payload.Validate(); err != nil {
+				return nil, fmt.Errorf("%T validation failed: %w", payload, err)
+			}
+			return payload, nil
+		},
+	}
+}
+
+// RegisterAnalyticsTransformer sets the analytics transformer of a registered
+// event type. Like RegisterEventType, call it from init functions.
+func RegisterAnalyticsTransformer(eventType EventType, fn AnalyticsTransformer) {
+	registry.Lock()
+	defer registry.Unlock()
+	spec, ok := registry.specs[eventType]
+	if !ok {
+		panic(fmt.Sprintf("streaming: analytics transformer for unregistered event type %s", eventType))
+	}
+	if spec.Transform != nil {
+		panic(fmt.Sprintf("streaming: analytics transformer for %s registered twice", eventType))
+	}
+	spec.Transform = fn
+}
+
+// LookupEventType returns the registration of eventType.
+func LookupEventType(eventType EventType) (EventSpec, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	spec, ok := registry.specs[eventType]
+	if !ok {
+		return EventSpec{}, false
+	}
+	return *spec, true
+}
+
+// EventTypes returns all registered event types.
+func EventTypes() []EventType {
+	registry.RLock()
+	defer registry.RUnlock()
+	types := make([]EventType, 0, len(registry.specs))
+	for eventType := range registry.specs {
+		types = append(types, eventType)
+	}
+	return types
+}
+
+// DecodePayload decodes and validates the payload of an event of eventType.
+func DecodePayload(eventType EventType, data json.RawMessage) (EventPayload, error) {
+	spec, ok := LookupEventType(eventType)
+	if !ok {
+		return nil, fmt.Errorf("unknown event type: %s", eventType)
+	}
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		return nil, fmt.Errorf("payload data is empty")
+	}
+	return spec.decode(func(v any) error { return json.Unmarshal(data, v) })
+}
@@ -0,0 +1,436 @@
+package streaming
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lavish-gambhir/dashbeam/pkg/apperr"
+	"github.com/lavish-gambhir/dashbeam/shared/config"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	streamKeyPrefix   = "stream:"
+	streamEventField  = "event"
+	streamTypeField   = "content_type"
+	defaultGroup      = "dashbeam"
+	defaultReadCount  = 100
+	defaultBlock      = 5 * time.Second
+	defaultClaimIdle  = time.Minute
+	defaultClaimEvery = 30 * time.Second
+	ackTimeout        = 5 * time.Second
+)
+
+func streamKey(topic string) string {
+	return streamKeyPrefix + topic
+}
+
+// StreamQueue is a MessageQueue backed by Valkey Streams. Every topic maps to
+// a stream and every subscription reads through a named consumer group, so
+// events published while a consumer is down are delivered once it is back.
+// Entries are acknowledged only after the handler succeeds (or the event has
+// been moved to the dead-letter queue); entries left pending by a crashed
+// consumer are reclaimed with XAUTOCLAIM.
+type StreamQueue struct {
+	client *redis.Client
+	dlq    *RedisDLQ
+	codec  Codec
+	cfg    config.QueueConfig
+	logger *slog.Logger
+
+	mu      sync.Mutex
+	wg      sync.WaitGroup
+	cancels map[string]context.CancelFunc
+}
+
+func NewStreamQueue(ctx context.Context, cfg *config.AppConfig, logger *slog.Logger) (*StreamQueue, error) {
+	codec, err := NewCodec(cfg.Queue.Codec)
+	if err != nil {
+		return nil, err
+	}
+	client, err := NewRedisClient(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	qcfg := cfg.Queue
+	if qcfg.ConsumerGroup == "" {
+		qcfg.ConsumerGroup = defaultGroup
+	}
+	if qcfg.ConsumerName == "" {
+		host, _ := os.Hostname()
+		qcfg.ConsumerName = fmt.Sprintf("%s-%d", host, os.Getpid())
+	}
+	if qcfg.ReadCount <= 0 {
+		qcfg.ReadCount = defaultReadCount
+	}
+	if qcfg.BlockTimeout <= 0 {
+		qcfg.BlockTimeout = defaultBlock
+	}
+	if qcfg.ClaimMinIdle <= 0 {
+		qcfg.ClaimMinIdle = defaultClaimIdle
+	}
+	if qcfg.ClaimInterval <= 0 {
+		qcfg.ClaimInterval = defaultClaimEvery
+	}
+
+	q := &StreamQueue{
+		client:  client,
+		codec:   codec,
+		cfg:     qcfg,
+		logger:  logger.With("component", "stream_queue", "consumer", qcfg.ConsumerName),
+		cancels: make(map[string]context.CancelFunc),
+	}
+	q.dlq = NewRedisDLQ(client, q, qcfg, logger)
+	return q, nil
+}
+
+// DeadLetters returns the store holding events whose handler kept failing.
+func (q *StreamQueue) DeadLetters() DeadLetterQueue {
+	return q.dlq
+}
+
+func (q *StreamQueue) Publish(ctx context.Context, topic string, event Event) error {
+	if event.ID == uuid.Nil {
+		id, err := uuid.NewV7()
+		if err != nil {
+			return err
+		}
+		event.ID = id
+	}
+
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now().UTC()
+	}
+	payload, err := q.codec.Marshal(event)
+	if err != nil {
+		return apperr.Wrapf(err, apperr.Internal, "%s, %v", "failed to encode event", event.ID)
+	}
+
+	args := &redis.XAddArgs{
+		Stream: streamKey(topic),
+		Values: map[string]any{
+			streamEventField: payload,
+			streamTypeField:  q.codec.ContentType(),
+		},
+	}
+	if q.cfg.MaxLen > 0 {
+		args.MaxLen = q.cfg.MaxLen
+		args.Approx = true
+	}
+	entryID, err := q.client.XAdd(ctx, args).Result()
+	if err != nil {
+		return apperr.Wrapf(err, apperr.RedisUnknown, "failed to add event %v to stream %s", event.ID, topic)
+	}
+	q.logger.Info("published message", slog.String("topic", topic), slog.String("entry_id", entryID), slog.String("event_id", event.ID.String()), slog.String("event_type", event.Type.String()))
+	return nil
+}
+
+// Subscribe starts a consumer for topic in the configured (or opts-provided)
+// consumer group. With opts.Pattern set, topic is matched against the known
+// topics, since streams cannot be subscribed to by pattern. Subscribing again
+// to the same topic replaces the previous consumer.
+func (q *StreamQueue) Subscribe(ctx context.Context, topic string, handler func(Event) error, opts *SubscribeOptions) {
+	if opts == nil {
+		opts = DefaultSubscriberOptions()
+	}
+
+	topics := []string{topic}
+	if opts.Pattern {
+		topics = MatchTopics(topic)
+	}
+	if len(topics) == 0 {
+		q.logger.Warn("subscription pattern matches no topics", slog.String("pattern", topic))
+		return
+	}
+
+	group := opts.ConsumerGroup
+	if group == "" {
+		group = q.cfg.ConsumerGroup
+	}
+
+	sub := &streamSubscription{
+		q:        q,
+		group:    group,
+		topics:   make(map[string]string, len(topics)),
+		handler:  handler,
+		opts:     opts,
+		logger:   q.logger.With("subscription", topic, "group", group),
+		handling: newInflight(opts.Concurrency),
+		held:     make(map[string]struct{}),
+	}
+	for _, t := range topics {
+		key := streamKey(t)
+		sub.keys = append(sub.keys, key)
+		sub.topics[key] = t
+	}
+
+	q.mu.Lock()
+	if cancel, exists := q.cancels[topic]; exists {
+		cancel()
+	}
+	subCtx, cancel := context.WithCancel(ctx)
+	q.cancels[topic] = cancel
+	q.mu.Unlock()
+
+	q.wg.Add(1)
+	go func() {
+		defer q.wg.Done()
+		sub.run(subCtx)
+	}()
+}
+
+// Close stops all consumers, waits for in-flight handlers and closes the client.
+func (q *StreamQueue) Close() error {
+	q.mu.Lock()
+	for _, cancel := range q.cancels {
+		cancel()
+	}
+	q.mu.Unlock()
+	q.wg.Wait()
+	return q.client.Close()
+}
+
+type streamSubscription struct {
+	q       *StreamQueue
+	group   string
+	keys    []string          // stream keys, in read order
+	topics  map[string]string // stream key -> topic
+	handler func(Event) error
+	opts    *SubscribeOptions
+	logger  *slog.Logger
+
+	handling *inflight
+
+	mu   sync.Mutex
+	held map[string]struct{} // entries being handled, by entry ID
+}
+
+func (s *streamSubscription) run(ctx context.Context) {
+	// Entries still being handled are acknowledged before the consumer stops.
+	defer s.handling.wait()
+
+	var failures int
+	for {
+		err := s.ensureGroups(ctx)
+		if err == nil {
+			break
+		}
+		s.logger.Error("failed to create consumer groups", slog.Any("err", err))
+		if !sleepCtx(ctx, s.opts.backoff(failures)) {
+			return
+		}
+		failures++
+	}
+
+	// Entries this consumer read but never acknowledged before it last stopped.
+	s.drainPending(ctx)
+	s.reclaim(ctx)
+	lastClaim := time.Now()
+
+	failures = 0
+	for ctx.Err() == nil {
+		if time.Since(lastClaim) >= s.q.cfg.ClaimInterval {
+			s.reclaim(ctx)
+			lastClaim = time.Now()
+		}
+
+		res, err := s.read(ctx, ">", s.q.cfg.BlockTimeout)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			s.logger.Error("failed to read from streams", slog.Int("consecutive_failures", failures+1), slog.Any("err", err))
+			if !sleepCtx(ctx, s.opts.backoff(failures)) {
+				return
+			}
+			failures++
+			continue
+		}
+		failures = 0
+		s.handleStreams(ctx, res)
+	}
+}
+
+// ensureGroups creates the consumer group on every stream. New groups start
+// from the beginning of the stream so that events published before the first
+// consumer ever joined are not skipped.
+func (s *streamSubscription) ensureGroups(ctx context.Context) error {
+	for _, key := range s.keys {
+		err := s.q.client.XGroupCreateMkStream(ctx, key, s.group, "0").Err()
+		if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+			return apperr.Wrapf(err, apperr.RedisUnknown, "failed to create group %s on %s", s.group, key)
+		}
+	}
+	return nil
+}
+
+func (s *streamSubscription) read(ctx context.Context, id string, block time.Duration) ([]redis.XStream, error) {
+	streams := make([]string, 0, len(s.keys)*2)
+	streams = append(streams, s.keys...)
+	for range s.keys {
+		streams = append(streams, id)
+	}
+	return s.readGroup(ctx, streams, block)
+}
+
+func (s *streamSubscription) readGroup(ctx context.Context, streams []string, block time.Duration) ([]redis.XStream, error) {
+	res, err := s.q.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    s.group,
+		Consumer: s.q.cfg.ConsumerName,
+		Streams:  streams,
+		Count:    s.q.cfg.ReadCount,
+		Block:    block,
+	}).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	return res, err
+}
+
+// drainPending walks this consumer's pending entries list. Reading with an
+// explicit ID returns history instead of new entries; the cursor advances past
+// every entry seen so one that cannot be acknowledged is not retried forever.
+func (s *streamSubscription) drainPending(ctx context.Context) {
+	for _, key := range s.keys {
+		cursor := "0"
+		for ctx.Err() == nil {
+			res, err := s.readGroup(ctx, []string{key, cursor}, -1)
+			if err != nil {
+				s.logger.Error("failed to read pending entries", slog.String("stream", key), slog.Any("err", err))
+				break
+			}
+			if len(res) == 0 || len(res[0].Messages) == 0 {
+				break
+			}
+			msgs := res[0].Messages
+			s.logger.Info("redelivering pending entries", slog.String("stream", key), slog.Int("count", len(msgs)))
+			for _, msg := range msgs {
+				s.dispatch(ctx, key, msg)
+			}
+			cursor = msgs[len(msgs)-1].ID
+		}
+	}
+}
+
+// reclaim takes over entries that other consumers in the group read but did
+// not acknowledge within ClaimMinIdle, e.g. because they crashed. Entries of
+// this consumer that are still being handled are claimed back by
+// XAUTOCLAIM too, but not handled again.
+func (s *streamSubscription) reclaim(ctx context.Context) {
+	for _, key := range s.keys {
+		start := "0-0"
+		for ctx.Err() == nil {
+			msgs, next, err := s.q.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+				Stream:   key,
+				Group:    s.group,
+				Consumer: s.q.cfg.ConsumerName,
+				MinIdle:  s.opts.claimMinIdle(s.q.cfg.ClaimMinIdle),
+				Start:    start,
+				Count:    s.q.cfg.ReadCount,
+			}).Result()
+			if err != nil {
+				s.logger.Error("failed to reclaim idle entries", slog.String("stream", key), slog.Any("err", err))
+				break
+			}
+			if len(msgs) > 0 {
+				s.logger.Info("reclaimed idle entries", slog.String("stream", key), slog.Int("count", len(msgs)))
+			}
+			for _, msg := range msgs {
+				s.dispatch(ctx, key, msg)
+			}
+			if next == "0-0" || next == "" {
+				break
+			}
+			start = next
+		}
+	}
+}
+
+func (s *streamSubscription) handleStreams(ctx context.Context, res []redis.XStream) {
+	for _, st := range res {
+		for _, msg := range st.Messages {
+			s.dispatch(ctx, st.Stream, msg)
+		}
+	}
+}
+
+// dispatch handles msg, alongside up to opts.Concurrency others, unless it
+// is already being handled.
+func (s *streamSubscription) dispatch(ctx context.Context, key string, msg redis.XMessage) {
+	entry := key + "/" + msg.ID
+	s.mu.Lock()
+	_, held := s.held[entry]
+	s.held[entry] = struct{}{}
+	s.mu.Unlock()
+	if held {
+		return
+	}
+
+	s.handling.run(func() {
+		defer func() {
+			s.mu.Lock()
+			delete(s.held, entry)
+			s.mu.Unlock()
+		}()
+		s.handleMessage(ctx, key, msg)
+	})
+}
+
+func (s *streamSubscription) handleMessage(ctx context.Context, key string, msg redis.XMessage) {
+	topic := s.topics[key]
+	logger := s.logger.With(slog.String("topic", topic), slog.String("entry_id", msg.ID))
+
+	raw, ok := msg.Values[streamEventField].(string)
+	if !ok {
+		// The entry was trimmed away while pending, or is not ours.
+		logger.Warn("stream entry has no event, acknowledging")
+		s.ack(ctx, key, msg.ID)
+		return
+	}
+
+	// Entries added before codecs existed have no content type and are JSON.
+	contentType, _ := msg.Values[streamTypeField].(string)
+	codec, err := codecFor(contentType)
+	if err != nil {
+		// Published by a newer producer; leave it pending for a consumer
+		// that can decode it to claim.
+		logger.Warn("unsupported content type, leaving entry pending", slog.Any("err", err))
+		return
+	}
+	var ev Event
+	if err := codec.Unmarshal([]byte(raw), &ev); err != nil {
+		logger.Error("failed to decode event, acknowledging", slog.Any("err", err))
+		s.ack(ctx, key, msg.ID)
+		return
+	}
+
+	if err := handleWithRetry(ctx, s.logger, s.handler, ev, s.opts); err != nil {
+		if ctx.Err() != nil {
+			// Leave it pending; it is redelivered when the consumer restarts.
+			return
+		}
+		logger.Error("handler failed with retries", slog.String("event_id", ev.ID.String()), slog.Any("err", err))
+		if err := s.q.dlq.Store(ctx, topic, ev, err); err != nil {
+			logger.Error("failed to store failed event", slog.String("event_id", ev.ID.String()), slog.Any("err", err))
+			return
+		}
+	}
+	s.ack(ctx, key, msg.ID)
+}
+
+// ack acknowledges an entry even once ctx is done, so that entries handled
+// while the consumer stops are not redelivered after a restart.
+func (s *streamSubscription) ack(ctx context.Context, key, id string) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), ackTimeout)
+	defer cancel()
+	if err := s.q.client.XAck(ctx, key, s.group, id).Err(); err != nil {
+		s.logger.Error("failed to acknowledge entry", slog.String("stream", key), slog.String("entry_id", id), slog.Any("err", err))
+	}
+}
@@ -1,21 +1,48 @@
 package streaming
 
+import "path"
+
 const (
 	TopicQuizEvents       = "quiz-events"
 	TopicUserEvents       = "user-events"
 	TopicEngagementEvents = "engagement-events"
 	TopicSystemEvents     = "system-events"
+
+	// TopicPatternAll matches every event topic.
+	TopicPatternAll = "*-events"
 )
 
+// Topics lists every topic events are published to.
+var Topics = []string{
+	TopicQuizEvents,
+	TopicUserEvents,
+	TopicEngagementEvents,
+	TopicSystemEvents,
+}
+
+// GetTopicForEventType returns the topic events of eventType are published
+// to; events of unregistered types go to the system topic.
 func GetTopicForEventType(eventType EventType) string {
-	switch eventType {
-	case QuizSessionStarted, QuizQuestionShown, QuizAnswerSubmitted, QuizSessionCompleted, QuizSessionAbandoned, QuizSessionPaused, QuizSessionResumed:
-		return TopicQuizEvents
-	case UserLogin, UserLogout:
-		return TopicUserEvents
-	case AppInteraction, AppNavigation, AppFocusChange, AppBackground, AppForeground:
-		return TopicEngagementEvents
-	default:
-		return TopicSystemEvents
+	if spec, ok := LookupEventType(eventType); ok {
+		return spec.Topic
+	}
+	return TopicSystemEvents
+}
+
+// MatchTopic reports whether topic matches a glob-style subscription pattern
+// (the same `*`, `?` and `[...]` syntax PSUBSCRIBE accepts).
+func MatchTopic(pattern, topic string) bool {
+	ok, err := path.Match(pattern, topic)
+	return err == nil && ok
+}
+
+// MatchTopics resolves a subscription pattern against the known topics.
+func MatchTopics(pattern string) []string {
+	var matched []string
+	for _, topic := range Topics {
+		if MatchTopic(pattern, topic) {
+			matched = append(matched, topic)
+		}
 	}
+	return matched
 }
@@ -0,0 +1,114 @@
+package streaming
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// BaseSchemaVersion is the payload schema version of every event type until
+// an upcaster is registered for it, and the version assumed for events sent
+// without a schema_version (app builds from before versioning).
+const BaseSchemaVersion = 1
+
+// Upcaster migrates a decoded payload from one schema version to the next.
+// It may modify and return the given map.
+type Upcaster func(payload map[string]any) (map[string]any, error)
+
+var upcasters = struct {
+	sync.RWMutex
+	byType map[EventType]map[int]Upcaster // event type -> from version -> upcaster
+}{byType: make(map[EventType]map[int]Upcaster)}
+
+// RegisterUpcaster registers fn to migrate payloads of eventType from version
+// from to from+1. The current schema version of the type becomes the highest
+// version reachable through registered upcasters. Register upcasters from
+// init functions; registering the same step twice panics.
+func RegisterUpcaster(eventType EventType, from int, fn Upcaster) {
+	if from < BaseSchemaVersion {
+		panic(fmt.Sprintf("streaming: upcaster for %s from invalid version %d", eventType, from))
+	}
+
+	upcasters.Lock()
+	defer upcasters.Unlock()
+	steps, ok := upcasters.byType[eventType]
+	if !ok {
+		steps = make(map[int]Upcaster)
+		upcasters.byType[eventType] = steps
+	}
+	if _, dup := steps[from]; dup {
+		panic(fmt.Sprintf("streaming: upcaster for %s from version %d registered twice", eventType, from))
+	}
+	steps[from] = fn
+}
+
+// CurrentSchemaVersion returns the payload schema version the structs of
+// eventType correspond to.
+func CurrentSchemaVersion(eventType EventType) int {
+	upcasters.RLock()
+	defer upcasters.RUnlock()
+	return currentSchemaVersion(eventType)
+}
+
+func currentSchemaVersion(eventType EventType) int {
+	steps := upcasters.byType[eventType]
+	version := BaseSchemaVersion
+	for steps[version] != nil {
+		version++
+	}
+	return version
+}
+
+// Upcast migrates payload from version to the current schema version of
+// eventType and returns it with the version it is now at. Payloads from a
+// version newer than the current one are rejected: they come from an app
+// build this server does not know about yet.
+func Upcast(eventType EventType, version int, payload map[string]any) (map[string]any, int, error) {
+	if version == 0 {
+		version = BaseSchemaVersion
+	}
+
+	upcasters.RLock()
+	defer upcasters.RUnlock()
+
+	current := currentSchemaVersion(eventType)
+	if version < BaseSchemaVersion || version > current {
+		return nil, version, fmt.Errorf("%w: schema_version %d of %s is not supported (current %d)", ErrInvalidPayload, version, eventType, current)
+	}
+
+	steps := upcasters.byType[eventType]
+	for ; version < current; version++ {
+		var err error
+		if payload, err = steps[version](payload); err != nil {
+			return nil, version, fmt.Errorf("failed to upcast %s payload from schema_version %d: %w", eventType, version, err)
+		}
+	}
+	return payload, version, nil
+}
+
+// upcastRaw is Upcast for an undecoded payload. The payload is only decoded
+// to a map when an upcaster has to run.
+func upcastRaw(eventType EventType, version int, payload json.RawMessage) (json.RawMessage, int, error) {
+	if version == 0 {
+		version = BaseSchemaVersion
+	}
+	if version == CurrentSchemaVersion(eventType) {
+		return payload, version, nil
+	}
+
+	var data map[string]any
+	if len(payload) > 0 {
+		if err := json.Unmarshal(payload, &data); err != nil {
+			return nil, version, fmt.Errorf("failed to unmarshal %s payload: %w", eventType, err)
+		}
+	}
+	data, version, err := Upcast(eventType, version, data)
+	if err != nil {
+		return nil, version, err
+	}
+	upcast, err := json.Marshal(data)
+	if err != nil {
+		return nil, version, fmt.Errorf("failed to marshal upcast %s payload: %w", eventType, err)
+	}
+	return upcast, version, nil
+}
@@ -2,7 +2,6 @@ package streaming
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log/slog"
 	"sync"
@@ -28,6 +27,101 @@ type SubscribeOptions struct {
 	Pattern      bool
 	MaxRetries   int
 	RetryBackoff []time.Duration
+	// ConsumerGroup names the group used by queues with durable delivery.
+	// Empty falls back to the configured default group.
+	ConsumerGroup string
+	// Concurrency is how many events are handed to the handler at once. Zero
+	// or one handles them one at a time, in order. Each event is acknowledged
+	// when its own handler returns, so a handler may hold on to its event
+	// until it is durably stored elsewhere.
+	Concurrency int
+	// ClaimMinIdle is how long an entry may stay unacknowledged, from the
+	// time it is read, before another consumer of the group takes it over,
+	// on queues that reclaim entries of crashed consumers. It must exceed
+	// the longest a handler can hold an event, retries and time spent
+	// waiting for a Concurrency slot included, or live entries are handled
+	// twice. Zero uses the queue's configured claim_min_idle.
+	ClaimMinIdle time.Duration
+}
+
+// claimMinIdle returns opts.ClaimMinIdle, or fallback if it is not set.
+func (o *SubscribeOptions) claimMinIdle(fallback time.Duration) time.Duration {
+	if o.ClaimMinIdle > 0 {
+		return o.ClaimMinIdle
+	}
+	return fallback
+}
+
+// backoff returns the delay before the given retry attempt (0-based), reusing
+// the last configured step once the schedule is exhausted.
+func (o *SubscribeOptions) backoff(attempt int) time.Duration {
+	if len(o.RetryBackoff) == 0 {
+		return time.Second
+	}
+	if attempt >= len(o.RetryBackoff) {
+		return o.RetryBackoff[len(o.RetryBackoff)-1]
+	}
+	return o.RetryBackoff[attempt]
+}
+
+// handleWithRetry runs handler once plus up to opts.MaxRetries retries,
+// sleeping per opts.RetryBackoff between attempts.
+func handleWithRetry(ctx context.Context, logger *slog.Logger, handler func(Event) error, ev Event, opts *SubscribeOptions) error {
+	var lastErr error
+	for attempt := 0; attempt <= opts.MaxRetries; attempt++ {
+		if attempt > 0 && !sleepCtx(ctx, opts.backoff(attempt-1)) {
+			return ctx.Err()
+		}
+		if lastErr = handler(ev); lastErr == nil {
+			return nil
+		}
+		logger.Warn("handler failed, retrying", slog.String("event_id", ev.ID.String()), slog.Int("attempt", attempt+1), slog.Any("err", lastErr))
+	}
+	return lastErr
+}
+
+// sleepCtx waits for d and reports false if ctx was cancelled first.
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
+
+// inflight runs the handling of a subscription's events, at most limit at a
+// time.
+type inflight struct {
+	sem chan struct{}
+	wg  sync.WaitGroup
+}
+
+func newInflight(limit int) *inflight {
+	return &inflight{sem: make(chan struct{}, max(limit, 1))}
+}
+
+// run calls fn, in the background unless events are handled one at a time.
+// It blocks while limit calls are running.
+func (f *inflight) run(fn func()) {
+	if cap(f.sem) == 1 {
+		fn()
+		return
+	}
+	f.sem <- struct{}{}
+	f.wg.Add(1)
+	go func() {
+		defer func() {
+			<-f.sem
+			f.wg.Done()
+		}()
+		fn()
+	}()
+}
+
+// wait blocks until every call started by run has returned.
+func (f *inflight) wait() {
+	f.wg.Wait()
 }
 
 func DefaultSubscriberOptions() *SubscribeOptions {
@@ -44,6 +138,8 @@ func DefaultSubscriberOptions() *SubscribeOptions {
 
 type RedisQueue struct {
 	client *redis.Client
+	dlq    *RedisDLQ
+	codec  Codec
 	logger *slog.Logger
 
 	mu          sync.Mutex
@@ -51,7 +147,9 @@ type RedisQueue struct {
 	subscribers map[string]*Subscriber
 }
 
-func NewRedisQueue(ctx context.Context, cfg *config.AppConfig, logger *slog.Logger) (*RedisQueue, error) {
+// NewRedisClient opens a Valkey connection using the shared redis config and
+// verifies it with a PING.
+func NewRedisClient(ctx context.Context, cfg *config.AppConfig) (*redis.Client, error) {
 	client := redis.NewClient(&redis.Options{
 		Addr:            cfg.Redis.Addr,
 		Password:        cfg.Redis.Password,
@@ -68,12 +166,32 @@ func NewRedisQueue(ctx context.Context, cfg *config.AppConfig, logger *slog.Logg
 	if err := client.Ping(ctx).Err(); err != nil {
 		return nil, err
 	}
-	return &RedisQueue{
+	return client, nil
+}
+
+func NewRedisQueue(ctx context.Context, cfg *config.AppConfig, logger *slog.Logger) (*RedisQueue, error) {
+	codec, err := NewCodec(cfg.Queue.Codec)
+	if err != nil {
+		return nil, err
+	}
+	client, err := NewRedisClient(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	q := &RedisQueue{
 		client:      client,
+		codec:       codec,
 		logger:      logger,
 		done:        make(chan struct{}),
 		subscribers: make(map[string]*Subscriber),
-	}, nil
+	}
+	q.dlq = NewRedisDLQ(client, q, cfg.Queue, logger)
+	return q, nil
+}
+
+// DeadLetters returns the store holding events whose handler kept failing.
+func (r *RedisQueue) DeadLetters() DeadLetterQueue {
+	return r.dlq
 }
 
 func (r *RedisQueue) Publish(ctx context.Context, topic string, event Event) error {
@@ -88,9 +206,9 @@ func (r *RedisQueue) Publish(ctx context.Context, topic string, event Event) err
 	if event.Timestamp.IsZero() {
 		event.Timestamp = time.Now().UTC()
 	}
-	payload, err := json.Marshal(event)
+	payload, err := encodeMessage(r.codec, event)
 	if err != nil {
-		return apperr.Wrapf(err, apperr.JSONEncodingFailed, "%s, %v", "failed to marshal event", event.ID)
+		return apperr.Wrapf(err, apperr.Internal, "%s, %v", "failed to encode event", event.ID)
 	}
 
 	pipe := r.client.Pipeline()
@@ -134,7 +252,7 @@ func (r *RedisQueue) handleSubscription(ctx context.Context, topic string, opts
 
 	maxBackoffAttempts := 5
 	for {
-		if err := r.processSubscription(ctx, topic); err != nil {
+		if err := r.processSubscription(ctx, topic, opts); err != nil {
 			consecutiveFailures++
 			r.logger.Error("subscription processing failed", slog.String("topic", topic), slog.Int("consecutive_failures", consecutiveFailures), slog.Any("err", err))
 			var backoff time.Duration
@@ -166,7 +284,7 @@ func (r *RedisQueue) handleSubscription(ctx context.Context, topic string, opts
 	}
 }
 
-func (r *RedisQueue) processSubscription(ctx context.Context, topic string) error {
+func (r *RedisQueue) processSubscription(ctx context.Context, topic string, opts *SubscribeOptions) error {
 	r.mu.Lock()
 	subscriber, exists := r.subscribers[topic]
 	if !exists {
@@ -175,6 +293,8 @@ func (r *RedisQueue) processSubscription(ctx context.Context, topic string) erro
 	}
 	r.mu.Unlock()
 	ch := subscriber.pubsub.Channel()
+	handling := newInflight(opts.Concurrency)
+	defer handling.wait()
 	for {
 		select {
 		case <-ctx.Done():
@@ -184,17 +304,18 @@ func (r *RedisQueue) processSubscription(ctx context.Context, topic string) erro
 				return fmt.Errorf("subscription channel closed")
 			}
 			var ev Event
-			if err := json.Unmarshal([]byte(msg.Payload), &ev); err != nil {
-				r.logger.Error("failed to unmarshal event", slog.String("topic", topic), slog.Any("err", err))
+			if err := decodeMessage([]byte(msg.Payload), &ev); err != nil {
+				r.logger.Error("failed to decode event", slog.String("topic", topic), slog.Any("err", err))
 				continue
 			}
-			if err := r.executeWithRetry(ctx, ev, subscriber.handler, topic); err != nil {
-				r.logger.Error("handler failed with retries", slog.String("topic", topic), slog.String("event_id", ev.ID.String()), slog.Any("err", err))
-				if err := r.storeFailedEvent(ctx, topic, ev, err); err != nil {
-					r.logger.Error("failed to store failed event", slog.String("topic", topic), slog.String("event_id", ev.ID.String()), slog.Any("err", err))
+			handling.run(func() {
+				if err := r.executeWithRetry(ctx, ev, subscriber.handler, topic); err != nil {
+					r.logger.Error("handler failed with retries", slog.String("topic", topic), slog.String("event_id", ev.ID.String()), slog.Any("err", err))
+					if err := r.dlq.Store(ctx, topic, ev, err); err != nil {
+						r.logger.Error("failed to store failed event", slog.String("topic", topic), slog.String("event_id", ev.ID.String()), slog.Any("err", err))
+					}
 				}
-			}
-
+			})
 		}
 	}
 }
@@ -221,26 +342,6 @@ func (r *RedisQueue) executeWithRetry(ctx context.Context, event Event, handler
 	return lastErr
 }
 
-func (r *RedisQueue) storeFailedEvent(ctx context.Context, topic string, event Event, err error) error {
-	failedEvent := struct {
-		Event      Event     `json:"event"`
-		Error      string    `json:"error"`
-		FailedAt   time.Time `json:"failed_at"`
-		RetryCount int       `json:"retry_count"`
-	}{
-		Event:      event,
-		Error:      err.Error(),
-		FailedAt:   time.Now(),
-		RetryCount: 0,
-	}
-	data, err := json.Marshal(failedEvent)
-	if err != nil {
-		return apperr.Wrapf(err, apperr.JSONEncodingFailed, "%s,topic=%s,event=%s", "failed to marshal event", topic, event.ID.String())
-	}
-	key := fmt.Sprintf("failed_events=%s:%s", topic, event.ID.String())
-	return r.client.Set(ctx, key, data, 24*time.Hour).Err()
-}
-
 func (r *RedisQueue) Close() error {
 	close(r.done)
 	r.mu.Lock()
@@ -267,50 +368,21 @@ func (r *RedisQueue) GetEventHistory(ctx context.Context, eventID uuid.UUID) (*E
 		return nil, apperr.Wrapf(err, apperr.RedisUnknown, "%s,id:%v", "failed to get event", eventID)
 	}
 	var ev Event
-	if err := json.Unmarshal(data, &ev); err != nil {
-		return nil, apperr.Wrapf(err, apperr.JSONDecodingFailed, "%s,id:%v", "failed to unmarshal event", eventID)
+	if err := decodeMessage(data, &ev); err != nil {
+		return nil, apperr.Wrapf(err, apperr.InvalidFormat, "%s,id:%v", "failed to decode event", eventID)
 	}
 	return &ev, nil
 }
 
+// ReplayFailedEvents replays every failed (not quarantined) event of topic.
 func (r *RedisQueue) ReplayFailedEvents(ctx context.Context, topic string) error {
-	pattern := fmt.Sprintf("failed_events:%s:*", topic)
-	keys, err := r.client.Keys(ctx, pattern).Result()
-	if err != nil {
-		return apperr.Wrapf(err, apperr.RedisUnknown, "%s,topic=%s", "failed to get failed events", topic)
-	}
-	pipe := r.client.Pipeline()
-	for _, key := range keys {
-		pipe.Get(ctx, key)
-	}
-	cmds, err := pipe.Exec(ctx)
+	failed, err := r.dlq.List(ctx, DLQFilter{Topic: topic, Status: DLQStatusFailed})
 	if err != nil {
-		return apperr.Wrapf(err, apperr.RedisUnknown, "%s,topic=%s", "failed to execute redis cmds", topic)
+		return err
 	}
-	for _, cmd := range cmds {
-		getCmd, ok := cmd.(*redis.StringCmd)
-		if !ok {
-			return fmt.Errorf("failed to cast cmd to *redisCmd, cmd:%s", cmd)
-		}
-		data, err := getCmd.Bytes()
-		if err != nil {
-			r.logger.Error("failed to convert cmd to bytes", slog.String("topic", topic))
-			continue
-		}
-		var failedEvent struct {
-			Event Event `json:"event"`
-		}
-		if err := json.Unmarshal(data, &failedEvent); err != nil {
-			r.logger.Error("failed to unmarshal cmd bytes", slog.String("topic", topic))
-			continue
-		}
-		if err := r.Publish(ctx, topic, failedEvent.Event); err != nil {
-			r.logger.Error("failed to replay event", slog.String("topic", topic), slog.String("event_id", failedEvent.Event.ID.String()), slog.Any("err", err))
-			continue
-		}
-		if len(cmd.Args()) > 0 {
-			cmdString := cmd.Args()[1].(string)
-			r.client.Del(ctx, cmdString)
+	for _, fe := range failed {
+		if _, err := r.dlq.Replay(ctx, topic, fe.Event.ID); err != nil {
+			r.logger.Error("failed to replay event", slog.String("topic", topic), slog.String("event_id", fe.Event.ID.String()), slog.Any("err", err))
 		}
 	}
 	return nil